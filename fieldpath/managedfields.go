@@ -0,0 +1,216 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ManagedFieldsOperation is the type of operation which lead to a
+// ManagedFieldsEntry being created.
+type ManagedFieldsOperation string
+
+const (
+	// ManagedFieldsOperationApply is the operation of appliers, whose
+	// VersionedSet is Applied().
+	ManagedFieldsOperationApply ManagedFieldsOperation = "Apply"
+	// ManagedFieldsOperationUpdate is the operation of updaters.
+	ManagedFieldsOperationUpdate ManagedFieldsOperation = "Update"
+)
+
+// FieldsTypeV1 is the only supported FieldsType, where FieldsV1 holds a
+// Set serialized with ToJSON.
+const FieldsTypeV1 = "FieldsV1"
+
+// ManagedFieldsEntry is the serialized form of one entry of ManagedFields,
+// as found in the metadata.managedFields of Kubernetes objects. It
+// serializes to JSON the same way.
+type ManagedFieldsEntry struct {
+	// Manager is an identifier of the workflow managing these fields.
+	Manager string `json:"manager,omitempty"`
+	// Operation is the type of operation which lead to this entry.
+	Operation ManagedFieldsOperation `json:"operation,omitempty"`
+	// APIVersion is the version of the object that the fields are
+	// expressed in.
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Time is the timestamp of when the entry was last changed. It has
+	// a precision of a second.
+	Time *time.Time `json:"time,omitempty"`
+	// FieldsType is the format of FieldsV1, it must be FieldsTypeV1.
+	FieldsType string `json:"fieldsType,omitempty"`
+	// FieldsV1 is the JSON serialized Set of fields.
+	FieldsV1 json.RawMessage `json:"fieldsV1,omitempty"`
+	// Subresource is the name of the subresource used to update the
+	// object, empty for the main resource.
+	Subresource string `json:"subresource,omitempty"`
+}
+
+// BuildManagerIdentifier returns the key used in ManagedFields for the
+// given entry. It is the JSON serialization of the entry without its
+// fieldsType, fieldsV1 and time, as well as without its apiVersion if
+// it is an Apply entry, so that an applier is always identified the same
+// way whatever the version it applies.
+func BuildManagerIdentifier(entry ManagedFieldsEntry) (string, error) {
+	id := ManagedFieldsEntry{
+		Manager:     entry.Manager,
+		Operation:   entry.Operation,
+		APIVersion:  entry.APIVersion,
+		Subresource: entry.Subresource,
+	}
+	if entry.Operation == ManagedFieldsOperationApply {
+		id.APIVersion = ""
+	}
+	b, err := json.Marshal(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to build manager identifier: %v", err)
+	}
+	return string(b), nil
+}
+
+// DecodeManagedFields decodes a list of entries into ManagedFields, keyed
+// by the identifier of each entry (see BuildManagerIdentifier), and
+// returns the time of the entries that have one, keyed the same way.
+// Malformed entries, and entries with the same identifier, are errors.
+func DecodeManagedFields(entries []ManagedFieldsEntry) (ManagedFields, map[string]time.Time, error) {
+	managed := make(ManagedFields, len(entries))
+	times := map[string]time.Time{}
+	for i, entry := range entries {
+		manager, vs, err := decodeManagedFieldsEntry(entry)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid managed fields entry %d: %v", i, err)
+		}
+		if _, ok := managed[manager]; ok {
+			return nil, nil, fmt.Errorf("invalid managed fields entry %d: duplicate entry for manager %v", i, manager)
+		}
+		managed[manager] = vs
+		if entry.Time != nil {
+			times[manager] = *entry.Time
+		}
+	}
+	return managed, times, nil
+}
+
+func decodeManagedFieldsEntry(entry ManagedFieldsEntry) (string, VersionedSet, error) {
+	switch entry.Operation {
+	case ManagedFieldsOperationApply, ManagedFieldsOperationUpdate:
+	default:
+		return "", nil, fmt.Errorf("invalid operation %q, must be %q or %q", entry.Operation, ManagedFieldsOperationApply, ManagedFieldsOperationUpdate)
+	}
+	if entry.APIVersion == "" {
+		return "", nil, fmt.Errorf("missing apiVersion")
+	}
+	if entry.FieldsType != FieldsTypeV1 {
+		return "", nil, fmt.Errorf("invalid fieldsType %q, must be %q", entry.FieldsType, FieldsTypeV1)
+	}
+	set := &Set{}
+	if len(entry.FieldsV1) != 0 {
+		if err := set.FromJSON(bytes.NewReader(entry.FieldsV1)); err != nil {
+			return "", nil, fmt.Errorf("invalid fieldsV1: %v", err)
+		}
+	}
+	manager, err := BuildManagerIdentifier(entry)
+	if err != nil {
+		return "", nil, err
+	}
+	return manager, NewVersionedSet(set, APIVersion(entry.APIVersion), entry.Operation == ManagedFieldsOperationApply), nil
+}
+
+// EncodeManagedFields encodes ManagedFields, keyed by manager identifiers
+// (see BuildManagerIdentifier), into a list of entries. The operation and
+// apiVersion of each entry are taken from its VersionedSet, and its time
+// from times, if present. Entries are sorted by operation, time, manager,
+// apiVersion and subresource, so that the output is deterministic. Keys
+// that are not valid manager identifiers, and keys that encode to
+// entries with the same identifier, are errors.
+func EncodeManagedFields(managed ManagedFields, times map[string]time.Time) ([]ManagedFieldsEntry, error) {
+	entries := make([]ManagedFieldsEntry, 0, len(managed))
+	ids := make(map[string]string, len(managed))
+	for manager, vs := range managed {
+		entry, err := encodeManagedFieldsEntry(manager, vs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode managed fields of manager %v: %v", manager, err)
+		}
+		if t, ok := times[manager]; ok {
+			t = t.UTC().Truncate(time.Second)
+			entry.Time = &t
+		}
+		id, err := BuildManagerIdentifier(entry)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := ids[id]; ok {
+			if other > manager {
+				other, manager = manager, other
+			}
+			return nil, fmt.Errorf("managers %v and %v encode to the same entry %v", other, manager, id)
+		}
+		ids[id] = manager
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		p, q := entries[i], entries[j]
+		if p.Operation != q.Operation {
+			return p.Operation < q.Operation
+		}
+		var pt, qt int64
+		if p.Time != nil {
+			pt = p.Time.Unix()
+		}
+		if q.Time != nil {
+			qt = q.Time.Unix()
+		}
+		if pt != qt {
+			return pt < qt
+		}
+		if p.Manager != q.Manager {
+			return p.Manager < q.Manager
+		}
+		if p.APIVersion != q.APIVersion {
+			return p.APIVersion < q.APIVersion
+		}
+		return p.Subresource < q.Subresource
+	})
+	return entries, nil
+}
+
+func encodeManagedFieldsEntry(manager string, vs VersionedSet) (ManagedFieldsEntry, error) {
+	var entry ManagedFieldsEntry
+	decoder := json.NewDecoder(bytes.NewReader([]byte(manager)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&entry); err != nil {
+		return entry, fmt.Errorf("invalid manager identifier: %v", err)
+	}
+	if entry.Time != nil || entry.FieldsType != "" || entry.FieldsV1 != nil {
+		return entry, fmt.Errorf("invalid manager identifier: must not have a time, fieldsType or fieldsV1")
+	}
+	entry.APIVersion = string(vs.APIVersion())
+	entry.Operation = ManagedFieldsOperationUpdate
+	if vs.Applied() {
+		entry.Operation = ManagedFieldsOperationApply
+	}
+	fields, err := vs.Set().ToJSON()
+	if err != nil {
+		return entry, fmt.Errorf("failed to serialize fields: %v", err)
+	}
+	entry.FieldsType = FieldsTypeV1
+	entry.FieldsV1 = fields
+	return entry, nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+const managedFieldsJSON = `[
+  {
+    "manager": "kubectl",
+    "operation": "Apply",
+    "apiVersion": "apps/v1",
+    "time": "2019-01-01T00:00:00Z",
+    "fieldsType": "FieldsV1",
+    "fieldsV1": {"f:spec":{"f:replicas":{}}}
+  },
+  {
+    "manager": "kubectl",
+    "operation": "Apply",
+    "apiVersion": "apps/v1",
+    "time": "2019-01-01T00:00:00Z",
+    "fieldsType": "FieldsV1",
+    "fieldsV1": {"f:spec":{"f:replicas":{}}},
+    "subresource": "scale"
+  },
+  {
+    "manager": "controller",
+    "operation": "Update",
+    "apiVersion": "apps/v1",
+    "time": "2019-01-02T00:00:00Z",
+    "fieldsType": "FieldsV1",
+    "fieldsV1": {"f:status":{".":{},"f:replicas":{}}},
+    "subresource": "status"
+  },
+  {
+    "manager": "controller",
+    "operation": "Update",
+    "apiVersion": "apps/v1beta1",
+    "fieldsType": "FieldsV1",
+    "fieldsV1": {"f:metadata":{"f:labels":{"f:<app>":{}}}}
+  }
+]`
+
+func TestDecodeManagedFields(t *testing.T) {
+	var entries []ManagedFieldsEntry
+	if err := json.Unmarshal([]byte(managedFieldsJSON), &entries); err != nil {
+		t.Fatalf("failed to unmarshal entries: %v", err)
+	}
+	managed, times, err := DecodeManagedFields(entries)
+	if err != nil {
+		t.Fatalf("failed to decode entries: %v", err)
+	}
+	expected := ManagedFields{
+		`{"manager":"kubectl","operation":"Apply"}`: NewVersionedSet(
+			NewSet(MakePathOrDie("spec", "replicas")),
+			"apps/v1",
+			true,
+		),
+		`{"manager":"kubectl","operation":"Apply","subresource":"scale"}`: NewVersionedSet(
+			NewSet(MakePathOrDie("spec", "replicas")),
+			"apps/v1",
+			true,
+		),
+		`{"manager":"controller","operation":"Update","apiVersion":"apps/v1","subresource":"status"}`: NewVersionedSet(
+			NewSet(MakePathOrDie("status"), MakePathOrDie("status", "replicas")),
+			"apps/v1",
+			false,
+		),
+		`{"manager":"controller","operation":"Update","apiVersion":"apps/v1beta1"}`: NewVersionedSet(
+			NewSet(MakePathOrDie("metadata", "labels", "<app>")),
+			"apps/v1beta1",
+			false,
+		),
+	}
+	if !managed.Equals(expected) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, managed)
+	}
+	if len(times) != 3 {
+		t.Errorf("expected 3 times, got %v", times)
+	}
+	if got := times[`{"manager":"controller","operation":"Update","apiVersion":"apps/v1","subresource":"status"}`]; !got.Equal(time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time: %v", got)
+	}
+
+	// Encoding sorts the entries by operation, time and manager.
+	encoded, err := EncodeManagedFields(managed, times)
+	if err != nil {
+		t.Fatalf("failed to encode entries: %v", err)
+	}
+	b, err := json.Marshal(encoded)
+	if err != nil {
+		t.Fatalf("failed to marshal entries: %v", err)
+	}
+	expectedJSON := `[` +
+		`{"manager":"kubectl","operation":"Apply","apiVersion":"apps/v1","time":"2019-01-01T00:00:00Z","fieldsType":"FieldsV1","fieldsV1":{"f:spec":{"f:replicas":{}}}},` +
+		`{"manager":"kubectl","operation":"Apply","apiVersion":"apps/v1","time":"2019-01-01T00:00:00Z","fieldsType":"FieldsV1","fieldsV1":{"f:spec":{"f:replicas":{}}},"subresource":"scale"},` +
+		`{"manager":"controller","operation":"Update","apiVersion":"apps/v1beta1","fieldsType":"FieldsV1","fieldsV1":{"f:metadata":{"f:labels":{"f:\u003capp\u003e":{}}}}},` +
+		`{"manager":"controller","operation":"Update","apiVersion":"apps/v1","time":"2019-01-02T00:00:00Z","fieldsType":"FieldsV1","fieldsV1":{"f:status":{".":{},"f:replicas":{}}},"subresource":"status"}` +
+		`]`
+	if string(b) != expectedJSON {
+		t.Errorf("expected:\n%s\ngot:\n%s", expectedJSON, b)
+	}
+
+	roundTripped, roundTrippedTimes, err := DecodeManagedFields(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode encoded entries: %v", err)
+	}
+	if !roundTripped.Equals(managed) {
+		t.Errorf("expected:\n%v\ngot:\n%v", managed, roundTripped)
+	}
+	if len(roundTrippedTimes) != len(times) {
+		t.Errorf("expected times %v, got %v", times, roundTrippedTimes)
+	}
+}
+
+func TestDecodeManagedFieldsErrors(t *testing.T) {
+	valid := ManagedFieldsEntry{
+		Manager:    "foo",
+		Operation:  ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: FieldsTypeV1,
+		FieldsV1:   json.RawMessage(`{"f:a":{}}`),
+	}
+	table := map[string][]ManagedFieldsEntry{
+		"invalid operation": {func() ManagedFieldsEntry {
+			e := valid
+			e.Operation = "Patch"
+			return e
+		}()},
+		"missing apiVersion": {func() ManagedFieldsEntry {
+			e := valid
+			e.APIVersion = ""
+			return e
+		}()},
+		"invalid fieldsType": {func() ManagedFieldsEntry {
+			e := valid
+			e.FieldsType = "FieldsV2"
+			return e
+		}()},
+		"invalid fieldsV1": {func() ManagedFieldsEntry {
+			e := valid
+			e.FieldsV1 = json.RawMessage(`{"f:a":`)
+			return e
+		}()},
+		"duplicate updaters": {valid, func() ManagedFieldsEntry {
+			e := valid
+			e.FieldsV1 = json.RawMessage(`{"f:b":{}}`)
+			return e
+		}()},
+		"duplicate appliers in different versions": {
+			func() ManagedFieldsEntry {
+				e := valid
+				e.Operation = ManagedFieldsOperationApply
+				return e
+			}(),
+			func() ManagedFieldsEntry {
+				e := valid
+				e.Operation = ManagedFieldsOperationApply
+				e.APIVersion = "v2"
+				return e
+			}(),
+		},
+	}
+	for name, entries := range table {
+		t.Run(name, func(t *testing.T) {
+			if managed, _, err := DecodeManagedFields(entries); err == nil {
+				t.Errorf("expected an error, got %v", managed)
+			}
+		})
+	}
+}
+
+func TestEncodeManagedFields(t *testing.T) {
+	managed := ManagedFields{
+		// The operation and version are taken from the set.
+		`{"manager":"foo","operation":"Update","apiVersion":"v1"}`: NewVersionedSet(NewSet(MakePathOrDie("a")), "v2", true),
+	}
+	times := map[string]time.Time{
+		`{"manager":"foo","operation":"Update","apiVersion":"v1"}`: time.Date(2020, 1, 1, 1, 0, 0, 5000, time.FixedZone("", 3600)),
+	}
+	entries, err := EncodeManagedFields(managed, times)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	b, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatalf("failed to marshal entries: %v", err)
+	}
+	expected := `[{"manager":"foo","operation":"Apply","apiVersion":"v2","time":"2020-01-01T00:00:00Z","fieldsType":"FieldsV1","fieldsV1":{"f:a":{}}}]`
+	if string(b) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, b)
+	}
+}
+
+func TestEncodeManagedFieldsErrors(t *testing.T) {
+	table := map[string]ManagedFields{
+		"not an identifier": {
+			"foo": NewVersionedSet(NewSet(), "v1", false),
+		},
+		"unknown fields": {
+			`{"manager":"foo","unknown":true}`: NewVersionedSet(NewSet(), "v1", false),
+		},
+		"fields in identifier": {
+			`{"manager":"foo","fieldsType":"FieldsV1"}`: NewVersionedSet(NewSet(), "v1", false),
+		},
+		"duplicate appliers": {
+			`{"manager":"foo","operation":"Apply"}`:                    NewVersionedSet(NewSet(), "v1", true),
+			`{"manager":"foo","operation":"Update","apiVersion":"v1"}`: NewVersionedSet(NewSet(), "v2", true),
+		},
+	}
+	for name, managed := range table {
+		t.Run(name, func(t *testing.T) {
+			if entries, err := EncodeManagedFields(managed, nil); err == nil {
+				t.Errorf("expected an error, got %v", entries)
+			}
+		})
+	}
+}
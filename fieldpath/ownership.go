@@ -0,0 +1,207 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"sort"
+)
+
+// Owner is a manager that owns a field, with the version and the
+// operation it owns the field with.
+type Owner struct {
+	Manager    string
+	APIVersion APIVersion
+	Applied    bool
+}
+
+func newOwner(manager string, vs VersionedSet) Owner {
+	return Owner{Manager: manager, APIVersion: vs.APIVersion(), Applied: vs.Applied()}
+}
+
+// Ownership describes which managers own a path, one of its ancestors,
+// or something beneath it. Owners are sorted by manager.
+type Ownership struct {
+	// Owners are the managers that own the path itself.
+	Owners []Owner
+	// AncestorOwners are the managers that own at least one of the
+	// ancestors of the path.
+	AncestorOwners []Owner
+	// DescendantOwners are the managers that own at least one field
+	// beneath the path.
+	DescendantOwners []Owner
+}
+
+// Ownership returns the managers that own the path, one of its
+// ancestors, or something beneath it. When looking up many paths, an
+// OwnershipIndex is more efficient.
+func (lhs ManagedFields) Ownership(p Path) Ownership {
+	var o Ownership
+	for manager, vs := range lhs {
+		owner := newOwner(manager, vs)
+		s := vs.Set()
+		for i := 0; i < len(p) && s != nil; i++ {
+			if i == len(p)-1 {
+				if s.Members.Has(p[i]) {
+					o.Owners = append(o.Owners, owner)
+				}
+			} else if s.Members.Has(p[i]) && !ownersContain(o.AncestorOwners, manager) {
+				o.AncestorOwners = append(o.AncestorOwners, owner)
+			}
+			s, _ = s.Children.Get(p[i])
+		}
+		if s != nil && !s.Empty() {
+			o.DescendantOwners = append(o.DescendantOwners, owner)
+		}
+	}
+	o.sort()
+	return o
+}
+
+func (o *Ownership) sort() {
+	sortOwners(o.Owners)
+	sortOwners(o.AncestorOwners)
+	sortOwners(o.DescendantOwners)
+}
+
+func sortOwners(owners []Owner) {
+	sort.Slice(owners, func(i, j int) bool { return owners[i].Manager < owners[j].Manager })
+}
+
+func ownersContain(owners []Owner, manager string) bool {
+	for _, o := range owners {
+		if o.Manager == manager {
+			return true
+		}
+	}
+	return false
+}
+
+// OwnershipIndex indexes the owners of every field of ManagedFields, so
+// that the ownership of many paths can be looked up efficiently.
+type OwnershipIndex struct {
+	root ownershipNode
+}
+
+type ownershipNode struct {
+	owners   []Owner
+	children PathElementMap
+}
+
+func (n *ownershipNode) child(pe PathElement) *ownershipNode {
+	if c, ok := n.children.Get(pe); ok {
+		return c.(*ownershipNode)
+	}
+	return nil
+}
+
+// NewOwnershipIndex builds an index of the fields owned by the managers,
+// with a single pass over the set of each manager.
+func NewOwnershipIndex(managed ManagedFields) *OwnershipIndex {
+	managers := make([]string, 0, len(managed))
+	for manager := range managed {
+		managers = append(managers, manager)
+	}
+	// Insert managers in order so that owners are sorted.
+	sort.Strings(managers)
+	idx := &OwnershipIndex{}
+	for _, manager := range managers {
+		vs := managed[manager]
+		idx.root.insert(vs.Set(), newOwner(manager, vs))
+	}
+	return idx
+}
+
+func (n *ownershipNode) insert(s *Set, owner Owner) {
+	for _, pe := range s.Members.members {
+		c := n.descend(pe)
+		c.owners = append(c.owners, owner)
+	}
+	for _, child := range s.Children.members {
+		n.descend(child.pathElement).insert(child.set, owner)
+	}
+}
+
+func (n *ownershipNode) descend(pe PathElement) *ownershipNode {
+	c := n.child(pe)
+	if c == nil {
+		c = &ownershipNode{}
+		n.children.Insert(pe, c)
+	}
+	return c
+}
+
+// Owners returns the managers that own the path, sorted by manager.
+func (idx *OwnershipIndex) Owners(p Path) []Owner {
+	n := &idx.root
+	for _, pe := range p {
+		if n = n.child(pe); n == nil {
+			return nil
+		}
+	}
+	return n.owners
+}
+
+// Ownership returns the managers that own the path, one of its
+// ancestors, or something beneath it.
+func (idx *OwnershipIndex) Ownership(p Path) Ownership {
+	var o Ownership
+	n := &idx.root
+	for i, pe := range p {
+		if n = n.child(pe); n == nil {
+			o.sort()
+			return o
+		}
+		if i < len(p)-1 {
+			for _, owner := range n.owners {
+				if !ownersContain(o.AncestorOwners, owner.Manager) {
+					o.AncestorOwners = append(o.AncestorOwners, owner)
+				}
+			}
+		}
+	}
+	o.Owners = append(o.Owners, n.owners...)
+	n.iterate(nil, func(p Path, owners []Owner) {
+		if len(p) == 0 {
+			return
+		}
+		for _, owner := range owners {
+			if !ownersContain(o.DescendantOwners, owner.Manager) {
+				o.DescendantOwners = append(o.DescendantOwners, owner)
+			}
+		}
+	})
+	o.sort()
+	return o
+}
+
+// Iterate calls f once for each owned field (preorder DFS), with its
+// owners sorted by manager. The path passed to f will be reused so make a copy
+// if you wish to keep it.
+func (idx *OwnershipIndex) Iterate(f func(p Path, owners []Owner)) {
+	idx.root.iterate(Path{}, func(p Path, owners []Owner) {
+		if len(owners) > 0 {
+			f(p, owners)
+		}
+	})
+}
+
+func (n *ownershipNode) iterate(prefix Path, f func(Path, []Owner)) {
+	f(prefix, n.owners)
+	for _, c := range n.children.members {
+		c.Value.(*ownershipNode).iterate(append(prefix, c.PathElement), f)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var ownershipManagedFields = ManagedFields{
+	"applier": NewVersionedSet(NewSet(
+		MakePathOrDie("spec"),
+		MakePathOrDie("spec", "replicas"),
+		MakePathOrDie("spec", "containers", KeyByFields("name", "app"), "image"),
+	), "v1", true),
+	"controller": NewVersionedSet(NewSet(
+		MakePathOrDie("spec", "replicas"),
+		MakePathOrDie("status", "replicas"),
+	), "v1beta1", false),
+	"sidecar": NewVersionedSet(NewSet(
+		MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar")),
+		MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar"), "image"),
+	), "v1", false),
+}
+
+var (
+	applierOwner    = Owner{Manager: "applier", APIVersion: "v1", Applied: true}
+	controllerOwner = Owner{Manager: "controller", APIVersion: "v1beta1"}
+	sidecarOwner    = Owner{Manager: "sidecar", APIVersion: "v1"}
+)
+
+func TestOwnership(t *testing.T) {
+	table := []struct {
+		path   Path
+		expect Ownership
+	}{
+		{
+			path: MakePathOrDie(),
+			expect: Ownership{
+				DescendantOwners: []Owner{applierOwner, controllerOwner, sidecarOwner},
+			},
+		},
+		{
+			path: MakePathOrDie("spec"),
+			expect: Ownership{
+				Owners:           []Owner{applierOwner},
+				DescendantOwners: []Owner{applierOwner, controllerOwner, sidecarOwner},
+			},
+		},
+		{
+			path: MakePathOrDie("spec", "replicas"),
+			expect: Ownership{
+				Owners:         []Owner{applierOwner, controllerOwner},
+				AncestorOwners: []Owner{applierOwner},
+			},
+		},
+		{
+			path: MakePathOrDie("spec", "containers"),
+			expect: Ownership{
+				AncestorOwners:   []Owner{applierOwner},
+				DescendantOwners: []Owner{applierOwner, sidecarOwner},
+			},
+		},
+		{
+			path: MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar"), "image", "tag"),
+			expect: Ownership{
+				AncestorOwners: []Owner{applierOwner, sidecarOwner},
+			},
+		},
+		{
+			path: MakePathOrDie("status"),
+			expect: Ownership{
+				DescendantOwners: []Owner{controllerOwner},
+			},
+		},
+		{
+			path:   MakePathOrDie("metadata", "labels"),
+			expect: Ownership{},
+		},
+	}
+	idx := NewOwnershipIndex(ownershipManagedFields)
+	for _, tt := range table {
+		tt := tt
+		t.Run(tt.path.String(), func(t *testing.T) {
+			t.Parallel()
+			if got := ownershipManagedFields.Ownership(tt.path); !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("ManagedFields.Ownership: expected %+v, got %+v", tt.expect, got)
+			}
+			if got := idx.Ownership(tt.path); !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("OwnershipIndex.Ownership: expected %+v, got %+v", tt.expect, got)
+			}
+			if got := idx.Owners(tt.path); !reflect.DeepEqual(got, tt.expect.Owners) {
+				t.Errorf("OwnershipIndex.Owners: expected %+v, got %+v", tt.expect.Owners, got)
+			}
+		})
+	}
+}
+
+func TestOwnershipIndexIterate(t *testing.T) {
+	idx := NewOwnershipIndex(ownershipManagedFields)
+	var got []string
+	idx.Iterate(func(p Path, owners []Owner) {
+		got = append(got, fmt.Sprintf("%v: %v", p, owners))
+	})
+	expect := []string{
+		".spec: [{applier v1 true}]",
+		`.spec.containers[name="app"].image: [{applier v1 true}]`,
+		`.spec.containers[name="sidecar"]: [{sidecar v1 false}]`,
+		`.spec.containers[name="sidecar"].image: [{sidecar v1 false}]`,
+		".spec.replicas: [{applier v1 true} {controller v1beta1 false}]",
+		".status.replicas: [{controller v1beta1 false}]",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expect, got)
+	}
+}
@@ -0,0 +1,341 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// ParsePath parses a path in the human-readable form produced by
+// Path.String(), e.g. `.spec.containers[name="nginx"].image`. Each element
+// of the path can be:
+//   - `.name` for a field name,
+//   - `[3]` for a list index,
+//   - `[k1="a",k2=1]` for the key of an associative list item,
+//   - `[="a"]` for the value of a set item.
+//
+// Values are either quoted strings, using Go escaping rules, numbers,
+// booleans or null. Since Path.String() doesn't escape field names, field
+// names that contain a `.` or a `[` can't be parsed back. Likewise, floats
+// that are printed without a fraction, e.g. 1.0 printed as `1`, are parsed
+// back as ints. The paths are still equal, since ints and floats compare
+// numerically, but the values have a different type.
+func ParsePath(s string) (Path, error) {
+	p := pathParser{in: s}
+	return p.parse()
+}
+
+// ParsePathWithSchema is like ParsePath, but it also checks the path against
+// the given schema, starting from the type tr: fields must be declared in the
+// schema, and list elements must be selected the way the list type expects.
+// Since the schema knows the type of key and set values, strings don't need
+// to be quoted in that form, e.g. `.spec.containers[name=nginx].image`.
+func ParsePathWithSchema(s string, sc *schema.Schema, tr schema.TypeRef) (Path, error) {
+	p := pathParser{in: s, schema: sc, typeRef: &tr}
+	return p.parse()
+}
+
+// ParsePathOrDie panics if the string can't be parsed into a path. Good for
+// things that are known at compile time.
+func ParsePathOrDie(s string) Path {
+	fp, err := ParsePath(s)
+	if err != nil {
+		panic(err)
+	}
+	return fp
+}
+
+type pathParser struct {
+	in  string
+	pos int
+
+	// schema and typeRef are only set when parsing with a schema,
+	// typeRef is the type of the element being parsed.
+	schema  *schema.Schema
+	typeRef *schema.TypeRef
+}
+
+func (p *pathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid path %q at position %d: %v", p.in, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *pathParser) parse() (Path, error) {
+	fp := Path{}
+	for p.pos < len(p.in) {
+		var pe PathElement
+		var err error
+		switch p.in[p.pos] {
+		case '.':
+			p.pos++
+			pe, err = p.parseField()
+		case '[':
+			p.pos++
+			pe, err = p.parseBracket()
+		default:
+			return nil, p.errorf("expected '.' or '[', got %q", p.in[p.pos])
+		}
+		if err != nil {
+			return nil, err
+		}
+		fp = append(fp, pe)
+	}
+	return fp, nil
+}
+
+func (p *pathParser) parseField() (PathElement, error) {
+	end := strings.IndexAny(p.in[p.pos:], ".[")
+	if end < 0 {
+		end = len(p.in) - p.pos
+	}
+	name := p.in[p.pos : p.pos+end]
+	if p.typeRef != nil {
+		m, err := p.resolveMap()
+		if err != nil {
+			return PathElement{}, err
+		}
+		if sf, ok := m.FindField(name); ok {
+			*p.typeRef = sf.Type
+		} else if (m.ElementType == schema.TypeRef{}) {
+			return PathElement{}, p.errorf("field %q not declared in schema", name)
+		} else {
+			*p.typeRef = m.ElementType
+		}
+	}
+	p.pos += end
+	return PathElement{FieldName: &name}, nil
+}
+
+func (p *pathParser) parseBracket() (PathElement, error) {
+	var list *schema.List
+	if p.typeRef != nil {
+		var err error
+		if list, err = p.resolveList(); err != nil {
+			return PathElement{}, err
+		}
+		*p.typeRef = list.ElementType
+	}
+
+	var pe PathElement
+	if p.pos < len(p.in) && p.in[p.pos] == '=' {
+		// Set value.
+		if list != nil && (list.ElementRelationship != schema.Associative || len(list.Keys) != 0) {
+			return PathElement{}, p.errorf("list is not a set, can't be indexed by value")
+		}
+		p.pos++
+		v, err := p.parseValue(p.scalarType(list, ""))
+		if err != nil {
+			return PathElement{}, err
+		}
+		pe.Value = &v
+	} else if i, ok := p.parseIndex(); ok {
+		if list != nil && list.ElementRelationship == schema.Associative {
+			return PathElement{}, p.errorf("associative list can't be indexed by position")
+		}
+		pe.Index = &i
+	} else {
+		// Associative list key.
+		if list != nil && (list.ElementRelationship != schema.Associative || len(list.Keys) == 0) {
+			return PathElement{}, p.errorf("list doesn't have keys")
+		}
+		key, err := p.parseKey(list)
+		if err != nil {
+			return PathElement{}, err
+		}
+		if list != nil && len(key) != len(list.Keys) {
+			return PathElement{}, p.errorf("expected all the keys of the list: %v", strings.Join(list.Keys, ", "))
+		}
+		pe.Key = &key
+	}
+	if p.pos >= len(p.in) || p.in[p.pos] != ']' {
+		return PathElement{}, p.errorf("expected ']'")
+	}
+	p.pos++
+	return pe, nil
+}
+
+// parseIndex parses an integer index if the brackets contain only that.
+func (p *pathParser) parseIndex() (int, bool) {
+	end := strings.IndexByte(p.in[p.pos:], ']')
+	if end < 0 {
+		return 0, false
+	}
+	i, err := strconv.Atoi(p.in[p.pos : p.pos+end])
+	if err != nil {
+		return 0, false
+	}
+	p.pos += end
+	return i, true
+}
+
+func (p *pathParser) parseKey(list *schema.List) (value.FieldList, error) {
+	key := value.FieldList{}
+	for {
+		end := strings.IndexByte(p.in[p.pos:], '=')
+		if end <= 0 {
+			return nil, p.errorf("expected key name followed by '='")
+		}
+		name := p.in[p.pos : p.pos+end]
+		if strings.ContainsAny(name, ",]") {
+			return nil, p.errorf("invalid key name %q", name)
+		}
+		for _, f := range key {
+			if f.Name == name {
+				return nil, p.errorf("duplicate key %q", name)
+			}
+		}
+		if list != nil && !stringsContain(list.Keys, name) {
+			return nil, p.errorf("%q is not a key of the list", name)
+		}
+		p.pos += end + 1
+		v, err := p.parseValue(p.scalarType(list, name))
+		if err != nil {
+			return nil, err
+		}
+		key = append(key, value.Field{Name: name, Value: v})
+		if p.pos < len(p.in) && p.in[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	key.Sort()
+	return key, nil
+}
+
+// parseValue parses a scalar value. If expected is a string scalar, values
+// that are not quoted are parsed as strings. Numbers are parsed as ints
+// when they can be, since the numeric scalar type doesn't tell ints and
+// floats apart.
+func (p *pathParser) parseValue(expected *schema.Scalar) (value.Value, error) {
+	if p.pos < len(p.in) && p.in[p.pos] == '"' {
+		end := p.pos + 1
+		for ; end < len(p.in) && p.in[end] != '"'; end++ {
+			if p.in[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(p.in) {
+			return nil, p.errorf("unterminated string")
+		}
+		s, err := strconv.Unquote(p.in[p.pos : end+1])
+		if err != nil {
+			return nil, p.errorf("invalid string: %v", err)
+		}
+		p.pos = end + 1
+		return value.NewValueInterface(s), nil
+	}
+
+	end := strings.IndexAny(p.in[p.pos:], ",]")
+	if end < 0 {
+		end = len(p.in) - p.pos
+	}
+	token := p.in[p.pos : p.pos+end]
+	if expected != nil && *expected == schema.String {
+		p.pos += end
+		return value.NewValueInterface(token), nil
+	}
+	var v interface{}
+	switch token {
+	case "null":
+		v = nil
+	case "true":
+		v = true
+	case "false":
+		v = false
+	default:
+		if i, err := strconv.ParseInt(token, 10, 64); err == nil {
+			v = i
+		} else if f, err := strconv.ParseFloat(token, 64); err == nil {
+			v = f
+		} else {
+			return nil, p.errorf("invalid value %q, strings must be quoted", token)
+		}
+	}
+	p.pos += end
+	return value.NewValueInterface(v), nil
+}
+
+func (p *pathParser) resolveMap() (*schema.Map, error) {
+	atom, ok := p.schema.Resolve(*p.typeRef)
+	if !ok {
+		return nil, p.errorf("no type found matching: %v", typeRefName(*p.typeRef))
+	}
+	if atom.Map == nil {
+		return nil, p.errorf("type %v is not a map", typeRefName(*p.typeRef))
+	}
+	return atom.Map, nil
+}
+
+func (p *pathParser) resolveList() (*schema.List, error) {
+	atom, ok := p.schema.Resolve(*p.typeRef)
+	if !ok {
+		return nil, p.errorf("no type found matching: %v", typeRefName(*p.typeRef))
+	}
+	if atom.List == nil {
+		return nil, p.errorf("type %v is not a list", typeRefName(*p.typeRef))
+	}
+	return atom.List, nil
+}
+
+// scalarType returns the type of the set items of list if key is empty, or
+// the type of the given key field of list items otherwise. It returns nil if
+// there is no schema or the type is not a scalar.
+func (p *pathParser) scalarType(list *schema.List, key string) *schema.Scalar {
+	if list == nil {
+		return nil
+	}
+	atom, ok := p.schema.Resolve(list.ElementType)
+	if !ok {
+		return nil
+	}
+	if key == "" {
+		return atom.Scalar
+	}
+	if atom.Map == nil {
+		return nil
+	}
+	sf, ok := atom.Map.FindField(key)
+	if !ok {
+		return nil
+	}
+	atom, ok = p.schema.Resolve(sf.Type)
+	if !ok {
+		return nil
+	}
+	return atom.Scalar
+}
+
+func typeRefName(tr schema.TypeRef) string {
+	if tr.NamedType != nil {
+		return *tr.NamedType
+	}
+	return "inlined type"
+}
+
+func stringsContain(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
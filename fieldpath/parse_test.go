@@ -0,0 +1,231 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	yaml "sigs.k8s.io/yaml/goyaml.v2"
+)
+
+func TestParsePathRoundTrip(t *testing.T) {
+	table := []Path{
+		{},
+		MakePathOrDie("foo"),
+		MakePathOrDie("foo", 1),
+		MakePathOrDie("foo", "bar", 1, "baz"),
+		MakePathOrDie("foo", KeyByFields(
+			"a", "b",
+			"c", 1,
+			"d", 1.5,
+			"e", true,
+			"f", nil,
+		)),
+		MakePathOrDie("foo", KeyByFields("name", `quote"and,comma]`), "bar"),
+		MakePathOrDie("foo",
+			_V("b"),
+			_V(5),
+			_V(false),
+			_V(3.14159),
+			_V(nil),
+			_V("a\nb"),
+		),
+		MakePathOrDie("foo", 0, KeyByFields("a", -1), _V("x"), "bar"),
+	}
+	for _, fp := range table {
+		fp := fp
+		t.Run(fp.String(), func(t *testing.T) {
+			t.Parallel()
+			got, err := ParsePath(fp.String())
+			if err != nil {
+				t.Fatalf("failed to parse %v: %v", fp, err)
+			}
+			if !got.Equals(fp) {
+				t.Errorf("expected %v, got %v", fp, got)
+			}
+		})
+	}
+}
+
+func TestParsePathIntegralFloat(t *testing.T) {
+	fp := MakePathOrDie("foo", _V(1.0))
+	got, err := ParsePath(fp.String())
+	if err != nil {
+		t.Fatalf("failed to parse %v: %v", fp, err)
+	}
+	if !got.Equals(fp) {
+		t.Errorf("expected %v, got %v", fp, got)
+	}
+	// The float is printed as 1, so it is parsed back as an int.
+	if v := *got[1].Value; !v.IsInt() {
+		t.Errorf("expected an int, got %v", v)
+	}
+}
+
+func TestParsePathSortsKeys(t *testing.T) {
+	got, err := ParsePath(`.foo[c=1,a="b"]`)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	expected := MakePathOrDie("foo", KeyByFields("a", "b", "c", 1))
+	if !got.Equals(expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	table := []string{
+		"foo",
+		".foo[",
+		".foo[1",
+		".foo[=]",
+		".foo[a]",
+		".foo[=bar]",
+		`.foo[="bar]`,
+		`.foo[a="b"c]`,
+		`.foo[a="b",a="c"]`,
+		`.foo[=1]]`,
+		`.foo[a="b",]`,
+	}
+	for _, s := range table {
+		s := s
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+			if fp, err := ParsePath(s); err == nil {
+				t.Errorf("expected an error, got %v", fp)
+			}
+		})
+	}
+}
+
+var parseSchema = func() (*schema.Schema, schema.TypeRef) {
+	sc := &schema.Schema{}
+	name := "object"
+	err := yaml.Unmarshal([]byte(`types:
+- name: object
+  map:
+    fields:
+      - name: containers
+        type:
+          list:
+            elementRelationship: associative
+            keys: ["name", "port"]
+            elementType:
+              namedType: container
+      - name: finalizers
+        type:
+          list:
+            elementRelationship: associative
+            elementType:
+              scalar: string
+      - name: args
+        type:
+          list:
+            elementRelationship: atomic
+            elementType:
+              scalar: string
+      - name: labels
+        type:
+          map:
+            elementType:
+              scalar: string
+- name: container
+  map:
+    fields:
+      - name: name
+        type:
+          scalar: string
+      - name: port
+        type:
+          scalar: numeric
+      - name: image
+        type:
+          scalar: string
+`), &sc)
+	if err != nil {
+		panic(err)
+	}
+	return sc, schema.TypeRef{NamedType: &name}
+}
+
+func TestParsePathWithSchema(t *testing.T) {
+	sc, tr := parseSchema()
+	table := []struct {
+		in     string
+		expect Path
+	}{
+		{".containers", MakePathOrDie("containers")},
+		{
+			`.containers[name=nginx,port=80].image`,
+			MakePathOrDie("containers", KeyByFields("name", "nginx", "port", 80), "image"),
+		},
+		{
+			`.containers[port=80,name="nginx"]`,
+			MakePathOrDie("containers", KeyByFields("name", "nginx", "port", 80)),
+		},
+		{
+			`.containers[name=80,port=80]`,
+			MakePathOrDie("containers", KeyByFields("name", "80", "port", 80)),
+		},
+		{".finalizers[=foo]", MakePathOrDie("finalizers", _V("foo"))},
+		{`.finalizers[="foo"]`, MakePathOrDie("finalizers", _V("foo"))},
+		{".args[2]", MakePathOrDie("args", 2)},
+		{".labels.app", MakePathOrDie("labels", "app")},
+	}
+	for _, tt := range table {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParsePathWithSchema(tt.in, sc, tr)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			if !got.Equals(tt.expect) {
+				t.Errorf("expected %v, got %v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestParsePathWithSchemaErrors(t *testing.T) {
+	sc, tr := parseSchema()
+	table := []string{
+		".unknown",
+		".containers.name",
+		".containers[0]",
+		".containers[name=nginx]",
+		".containers[name=nginx,port=80,image=nginx]",
+		".containers[name=nginx,port=eighty]",
+		".containers[=nginx]",
+		".finalizers[0]",
+		".finalizers[name=foo]",
+		".args[=foo]",
+		".args[name=foo]",
+		".labels[0]",
+		".labels.app.foo",
+	}
+	for _, s := range table {
+		s := s
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+			if fp, err := ParsePathWithSchema(s, sc, tr); err == nil {
+				t.Errorf("expected an error, got %v", fp)
+			}
+		})
+	}
+}
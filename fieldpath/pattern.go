@@ -0,0 +1,308 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// ValuePredicate matches one of the values of a path element: the value
+// of a key of an associative list item, or the value of a set item.
+type ValuePredicate struct {
+	// Key is the name of the key whose value is matched. If empty, the
+	// value of a set item is matched instead.
+	Key string
+
+	// Exactly one of the following fields should be non-nil.
+
+	// Value matches values that are equal to it.
+	Value *value.Value
+
+	// Regexp matches values that it matches. Non-string values are
+	// matched against their textual form.
+	Regexp *regexp.Regexp
+}
+
+// Matches returns true if the path element has a value that is matched
+// by the predicate.
+func (vp ValuePredicate) Matches(pe PathElement) bool {
+	var v value.Value
+	if vp.Key == "" {
+		if pe.Value == nil {
+			return false
+		}
+		v = *pe.Value
+	} else {
+		if pe.Key == nil {
+			return false
+		}
+		for _, f := range *pe.Key {
+			if f.Name == vp.Key {
+				v = f.Value
+				break
+			}
+		}
+		if v == nil {
+			return false
+		}
+	}
+	switch {
+	case vp.Value != nil:
+		return value.Equals(*vp.Value, v)
+	case vp.Regexp != nil:
+		if v.IsString() {
+			return vp.Regexp.MatchString(v.AsString())
+		}
+		return vp.Regexp.MatchString(value.ToString(v))
+	}
+	return false
+}
+
+// String presents the predicate the way it is written in patterns.
+func (vp ValuePredicate) String() string {
+	switch {
+	case vp.Value != nil:
+		return fmt.Sprintf("%v=%v", vp.Key, value.ToString(*vp.Value))
+	case vp.Regexp != nil:
+		return fmt.Sprintf("%v=~%q", vp.Key, vp.Regexp.String())
+	}
+	return vp.Key
+}
+
+func comparePredicates(lhs, rhs []ValuePredicate) int {
+	// Matchers with predicates are sorted first, like wildcards, since
+	// they are more general than path elements.
+	if len(lhs) != 0 && len(rhs) == 0 {
+		return -1
+	} else if len(lhs) == 0 && len(rhs) != 0 {
+		return 1
+	}
+	for i := 0; i < len(lhs) && i < len(rhs); i++ {
+		if c := strings.Compare(lhs[i].String(), rhs[i].String()); c != 0 {
+			return c
+		}
+	}
+	return len(lhs) - len(rhs)
+}
+
+// ParseSetMatcher compiles a list of patterns into a SetMatcher that
+// matches the field paths matched by any of them. Like the matchers built
+// by PrefixMatcher, a pattern matches the field paths it selects and
+// everything under them. Patterns are written like the output of
+// Path.String(), the leading dot is optional, and they can also contain:
+//   - `*` or `[*]` to match any path element,
+//   - `**` to match everything under the current path, it must be the
+//     last element of a pattern,
+//   - `[k1="a",k2=~"re"]` to match associative list items whose key k1 is
+//     "a" and whose key k2 matches the regular expression "re",
+//   - `[=~"re"]` to match set items whose value matches "re".
+//
+// Regular expressions must match the whole value. For example:
+// `spec.containers[*].image`, `metadata.labels.*`,
+// `spec.containers[name=~"sidecar-.*"].image` or `**`.
+//
+// As with any SetMatcher, when several matchers match the same path
+// element, wildcards take precedence over predicates, which take
+// precedence over exact path elements.
+func ParseSetMatcher(patterns ...string) (*SetMatcher, error) {
+	var matcher *SetMatcher
+	for _, pattern := range patterns {
+		p := patternParser{pathParser: pathParser{in: pattern}}
+		m, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		if matcher == nil {
+			matcher = m
+		} else {
+			matcher = matcher.Merge(m)
+		}
+	}
+	if matcher == nil {
+		return nil, fmt.Errorf("no pattern provided")
+	}
+	return matcher, nil
+}
+
+// ParseSetMatcherOrDie is the same as ParseSetMatcher except it panics if
+// the patterns can't be parsed. Good for things that are known at compile
+// time.
+func ParseSetMatcherOrDie(patterns ...string) *SetMatcher {
+	m, err := ParseSetMatcher(patterns...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+type patternParser struct {
+	pathParser
+}
+
+func (p *patternParser) parse() (*SetMatcher, error) {
+	var parts []interface{}
+	if strings.HasPrefix(p.in, ".") {
+		p.pos++
+	}
+	for i := 0; p.pos < len(p.in); i++ {
+		var part PathElementMatcher
+		var err error
+		if p.in[p.pos] == '[' {
+			p.pos++
+			part, err = p.parseBracket()
+		} else {
+			if i > 0 {
+				if p.in[p.pos] != '.' {
+					return nil, p.errorf("expected '.' or '[', got %q", p.in[p.pos])
+				}
+				p.pos++
+			}
+			var anySet bool
+			part, anySet, err = p.parseField()
+			if err == nil && anySet {
+				if p.pos < len(p.in) {
+					return nil, p.errorf("'**' must be the last element of the pattern")
+				}
+				return PrefixMatcher(parts...)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return nil, p.errorf("empty pattern")
+	}
+	return PrefixMatcher(parts...)
+}
+
+func (p *patternParser) parseField() (PathElementMatcher, bool, error) {
+	end := strings.IndexAny(p.in[p.pos:], ".[")
+	if end < 0 {
+		end = len(p.in) - p.pos
+	}
+	name := p.in[p.pos : p.pos+end]
+	if name == "" {
+		return PathElementMatcher{}, false, p.errorf("expected field name")
+	}
+	p.pos += end
+	switch name {
+	case "*":
+		return MatchAnyPathElement(), false, nil
+	case "**":
+		return PathElementMatcher{}, true, nil
+	}
+	return PathElementMatcher{PathElement: PathElement{FieldName: &name}}, false, nil
+}
+
+func (p *patternParser) parseBracket() (PathElementMatcher, error) {
+	var pm PathElementMatcher
+	if strings.HasPrefix(p.in[p.pos:], "*]") {
+		p.pos++
+		pm = MatchAnyPathElement()
+	} else if strings.HasPrefix(p.in[p.pos:], "=~") {
+		// Set value predicate.
+		vp, err := p.parsePredicate("")
+		if err != nil {
+			return pm, err
+		}
+		pm.Predicates = []ValuePredicate{vp}
+	} else if p.pos < len(p.in) && p.in[p.pos] == '=' {
+		// Set value.
+		p.pos++
+		v, err := p.parseValue(nil)
+		if err != nil {
+			return pm, err
+		}
+		pm.Value = &v
+	} else if i, ok := p.parseIndex(); ok {
+		pm.Index = &i
+	} else {
+		preds, err := p.parsePredicates()
+		if err != nil {
+			return pm, err
+		}
+		pm.Predicates = preds
+	}
+	if p.pos >= len(p.in) || p.in[p.pos] != ']' {
+		return pm, p.errorf("expected ']'")
+	}
+	p.pos++
+	return pm, nil
+}
+
+func (p *patternParser) parsePredicates() ([]ValuePredicate, error) {
+	var preds []ValuePredicate
+	for {
+		end := strings.IndexByte(p.in[p.pos:], '=')
+		if end <= 0 {
+			return nil, p.errorf("expected key name followed by '=' or '=~'")
+		}
+		name := p.in[p.pos : p.pos+end]
+		if strings.ContainsAny(name, ",]") {
+			return nil, p.errorf("invalid key name %q", name)
+		}
+		p.pos += end
+		vp, err := p.parsePredicate(name)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, vp)
+		if p.pos < len(p.in) && p.in[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	sort.SliceStable(preds, func(i, j int) bool { return preds[i].Key < preds[j].Key })
+	return preds, nil
+}
+
+// parsePredicate parses the operator and value of a predicate on the
+// given key.
+func (p *patternParser) parsePredicate(key string) (ValuePredicate, error) {
+	vp := ValuePredicate{Key: key}
+	if strings.HasPrefix(p.in[p.pos:], "=~") {
+		p.pos += 2
+		v, err := p.parseValue(nil)
+		if err != nil {
+			return vp, err
+		}
+		if !v.IsString() {
+			return vp, p.errorf("regular expressions must be quoted strings")
+		}
+		re, err := regexp.Compile("^(?:" + v.AsString() + ")$")
+		if err != nil {
+			return vp, p.errorf("invalid regular expression: %v", err)
+		}
+		vp.Regexp = re
+		return vp, nil
+	}
+	p.pos++
+	v, err := p.parseValue(nil)
+	if err != nil {
+		return vp, err
+	}
+	vp.Value = &v
+	return vp, nil
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"testing"
+)
+
+func TestParseSetMatcher(t *testing.T) {
+	input := NewSet(
+		MakePathOrDie("metadata"),
+		MakePathOrDie("metadata", "name"),
+		MakePathOrDie("metadata", "labels"),
+		MakePathOrDie("metadata", "labels", "app"),
+		MakePathOrDie("metadata", "labels", "tier"),
+		MakePathOrDie("spec"),
+		MakePathOrDie("spec", "containers"),
+		MakePathOrDie("spec", "containers", KeyByFields("name", "app")),
+		MakePathOrDie("spec", "containers", KeyByFields("name", "app"), "image"),
+		MakePathOrDie("spec", "containers", KeyByFields("name", "app"), "args"),
+		MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log")),
+		MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log"), "image"),
+		MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log"), "args"),
+		MakePathOrDie("spec", "finalizers"),
+		MakePathOrDie("spec", "finalizers", _V("keep")),
+		MakePathOrDie("spec", "finalizers", _V("example.com/cleanup")),
+		MakePathOrDie("spec", "ports"),
+		MakePathOrDie("spec", "ports", KeyByFields("port", 80, "protocol", "TCP")),
+		MakePathOrDie("spec", "ports", KeyByFields("port", 443, "protocol", "TCP")),
+		MakePathOrDie("spec", "args"),
+		MakePathOrDie("spec", "args", 0),
+		MakePathOrDie("spec", "args", 1),
+		MakePathOrDie("status"),
+	)
+
+	testCases := []struct {
+		name     string
+		patterns []string
+		expect   *Set
+	}{
+		{
+			name:     "everything",
+			patterns: []string{"**"},
+			expect:   input,
+		},
+		{
+			name:     "field",
+			patterns: []string{"metadata"},
+			expect: NewSet(
+				MakePathOrDie("metadata"),
+				MakePathOrDie("metadata", "name"),
+				MakePathOrDie("metadata", "labels"),
+				MakePathOrDie("metadata", "labels", "app"),
+				MakePathOrDie("metadata", "labels", "tier"),
+			),
+		},
+		{
+			name:     "leading dot and trailing double wildcard",
+			patterns: []string{".metadata.labels.**"},
+			expect: NewSet(
+				MakePathOrDie("metadata"),
+				MakePathOrDie("metadata", "labels"),
+				MakePathOrDie("metadata", "labels", "app"),
+				MakePathOrDie("metadata", "labels", "tier"),
+			),
+		},
+		{
+			name:     "field wildcard",
+			patterns: []string{"metadata.labels.*"},
+			expect: NewSet(
+				MakePathOrDie("metadata"),
+				MakePathOrDie("metadata", "labels"),
+				MakePathOrDie("metadata", "labels", "app"),
+				MakePathOrDie("metadata", "labels", "tier"),
+			),
+		},
+		{
+			name:     "list wildcard",
+			patterns: []string{"spec.containers[*].image"},
+			expect: NewSet(
+				MakePathOrDie("spec"),
+				MakePathOrDie("spec", "containers"),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "app")),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "app"), "image"),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log")),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log"), "image"),
+			),
+		},
+		{
+			name:     "key regular expression",
+			patterns: []string{`spec.containers[name=~"sidecar-.*"].image`},
+			expect: NewSet(
+				MakePathOrDie("spec"),
+				MakePathOrDie("spec", "containers"),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log")),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log"), "image"),
+			),
+		},
+		{
+			name:     "regular expressions match the whole value",
+			patterns: []string{`spec.containers[name=~"side"]`},
+			expect: NewSet(
+				MakePathOrDie("spec"),
+				MakePathOrDie("spec", "containers"),
+			),
+		},
+		{
+			name:     "partial key",
+			patterns: []string{`spec.ports[port=443]`},
+			expect: NewSet(
+				MakePathOrDie("spec"),
+				MakePathOrDie("spec", "ports"),
+				MakePathOrDie("spec", "ports", KeyByFields("port", 443, "protocol", "TCP")),
+			),
+		},
+		{
+			name:     "several predicates",
+			patterns: []string{`spec.ports[protocol="TCP",port=~"8."]`},
+			expect: NewSet(
+				MakePathOrDie("spec"),
+				MakePathOrDie("spec", "ports"),
+				MakePathOrDie("spec", "ports", KeyByFields("port", 80, "protocol", "TCP")),
+			),
+		},
+		{
+			name:     "set values",
+			patterns: []string{`spec.finalizers[="keep"]`, `spec.finalizers[=~".*/cleanup"]`},
+			expect: NewSet(
+				MakePathOrDie("spec"),
+				MakePathOrDie("spec", "finalizers"),
+				MakePathOrDie("spec", "finalizers", _V("keep")),
+				MakePathOrDie("spec", "finalizers", _V("example.com/cleanup")),
+			),
+		},
+		{
+			name:     "index",
+			patterns: []string{"spec.args[1]"},
+			expect: NewSet(
+				MakePathOrDie("spec"),
+				MakePathOrDie("spec", "args"),
+				MakePathOrDie("spec", "args", 1),
+			),
+		},
+		{
+			name:     "multiple patterns",
+			patterns: []string{"status", "metadata.name", "spec.args"},
+			expect: NewSet(
+				MakePathOrDie("metadata"),
+				MakePathOrDie("metadata", "name"),
+				MakePathOrDie("spec"),
+				MakePathOrDie("spec", "args"),
+				MakePathOrDie("spec", "args", 0),
+				MakePathOrDie("spec", "args", 1),
+				MakePathOrDie("status"),
+			),
+		},
+		{
+			name:     "wildcard takes precedence over predicate",
+			patterns: []string{`spec.containers[name="app"].image`, "spec.containers[*].args"},
+			expect: NewSet(
+				MakePathOrDie("spec"),
+				MakePathOrDie("spec", "containers"),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "app")),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "app"), "args"),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log")),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log"), "args"),
+			),
+		},
+		{
+			name:     "wildcard takes precedence over regular expression",
+			patterns: []string{`spec.containers[name=~"sidecar-.*"].image`, "spec.containers.*.args"},
+			expect: NewSet(
+				MakePathOrDie("spec"),
+				MakePathOrDie("spec", "containers"),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "app")),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "app"), "args"),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log")),
+				MakePathOrDie("spec", "containers", KeyByFields("name", "sidecar-log"), "args"),
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matcher, err := ParseSetMatcher(tc.patterns...)
+			if err != nil {
+				t.Fatalf("failed to parse patterns: %v", err)
+			}
+			filtered := NewIncludeMatcherFilter(matcher).Filter(input)
+			if !filtered.Equals(tc.expect) {
+				t.Errorf("Expected:\n%v\n\nbut got:\n%v", tc.expect, filtered)
+			}
+		})
+	}
+}
+
+func TestParseSetMatcherErrors(t *testing.T) {
+	table := []string{
+		"",
+		".",
+		"spec..containers",
+		"spec.**.image",
+		"spec[",
+		"spec[*",
+		"spec[]",
+		"spec[name]",
+		"spec[name=~sidecar]",
+		`spec[name=~"("]`,
+		"spec[name=~1]",
+		`spec[name="a"]image`,
+	}
+	for _, s := range table {
+		s := s
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+			if _, err := ParseSetMatcher(s); err == nil {
+				t.Errorf("expected an error for %q", s)
+			}
+		})
+	}
+}
+
+func TestPathElementMatcherEquals(t *testing.T) {
+	a := ParseSetMatcherOrDie(`spec[name="a"]`).members[0].Child.members[0].Path
+	b := ParseSetMatcherOrDie(`spec[name="b"]`).members[0].Child.members[0].Path
+	wildcard := PathElementMatcher{Wildcard: true}
+	if !a.Equals(a) || !wildcard.Equals(wildcard) {
+		t.Errorf("expected the matchers to equal themselves")
+	}
+	if a.Equals(b) || a.Equals(wildcard) || wildcard.Equals(a) {
+		t.Errorf("expected matchers with different predicates to differ")
+	}
+	if merged := ParseSetMatcherOrDie(`spec[name="a"]`, `spec[name="b"]`); len(merged.members[0].Child.members) != 2 {
+		t.Errorf("expected both predicates to be kept, got %v", merged.members[0].Child.members)
+	}
+}
@@ -0,0 +1,480 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// The binary format starts with a version byte, followed by the root
+// node of the set. A node is a uvarint count of entries followed by the
+// entries, in the order of their path elements. Each entry is a header
+// byte, a path element, and the node of its children if it has any:
+//
+//   - bit 0 of the header is set if the path element is a member,
+//   - bit 1 is set if the path element has children,
+//   - bits 2-3 are the type of the path element.
+//
+// Strings (field names, key names and string values) are interned: a
+// uvarint 0 is followed by a new string (uvarint length and bytes) which
+// gets the next index in the table, and any other n refers to the string
+// at index n-1. Indices are varints. Keys are encoded as the number of
+// fields they share with the key of the previous entry of the same node,
+// followed by the remaining fields.
+const (
+	binaryV1 byte = 1
+
+	binaryIsMember    byte = 1 << 0
+	binaryHasChildren byte = 1 << 1
+	binaryTypeShift        = 2
+	binaryTypeMask    byte = 3 << binaryTypeShift
+
+	binaryField byte = 0
+	binaryKey   byte = 1
+	binaryValue byte = 2
+	binaryIndex byte = 3
+
+	binaryNull   byte = 0
+	binaryFalse  byte = 1
+	binaryTrue   byte = 2
+	binaryInt    byte = 3
+	binaryFloat  byte = 4
+	binaryString byte = 5
+	// binaryJSON is used for any other value, which shouldn't be found
+	// in path elements.
+	binaryJSON byte = 6
+)
+
+// ErrInvalidBinarySet is returned when decoding a malformed binary set.
+var ErrInvalidBinarySet = errors.New("invalid binary set")
+
+// ToBinary encodes the set in a compact binary format, which is much
+// faster to decode than the JSON format. See FromBinary.
+func (s *Set) ToBinary() ([]byte, error) {
+	e := binaryEncoder{
+		buf:     []byte{binaryV1},
+		strings: map[string]uint64{},
+	}
+	if err := e.writeSet(s); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// FromBinary clears s and reads a set encoded by ToBinary.
+func (s *Set) FromBinary(b []byte) error {
+	if len(b) == 0 {
+		return fmt.Errorf("%w: empty input", ErrInvalidBinarySet)
+	}
+	if b[0] != binaryV1 {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidBinarySet, b[0])
+	}
+	d := binaryDecoder{buf: b, pos: 1}
+	found, err := d.readSet()
+	if err != nil {
+		return err
+	}
+	if d.pos != len(d.buf) {
+		return d.errorf("unexpected trailing data")
+	}
+	*s = *found
+	return nil
+}
+
+type binaryEncoder struct {
+	buf     []byte
+	strings map[string]uint64
+}
+
+func (e *binaryEncoder) writeSet(s *Set) error {
+	mi, ci := 0, 0
+	members, children := s.Members.members, s.Children.members
+	e.buf = binary.AppendUvarint(e.buf, uint64(countEntries(members, children)))
+	var prevKey *value.FieldList
+	writeEntry := func(pe PathElement, header byte, child *Set) error {
+		if err := e.writePathElement(pe, header, prevKey); err != nil {
+			return err
+		}
+		prevKey = pe.Key
+		if child != nil {
+			return e.writeSet(child)
+		}
+		return nil
+	}
+	for mi < len(members) || ci < len(children) {
+		var err error
+		switch {
+		case ci == len(children) || (mi < len(members) && members[mi].Less(children[ci].pathElement)):
+			err = writeEntry(members[mi], binaryIsMember, nil)
+			mi++
+		case mi == len(members) || children[ci].pathElement.Less(members[mi]):
+			err = writeEntry(children[ci].pathElement, binaryHasChildren, children[ci].set)
+			ci++
+		default:
+			err = writeEntry(children[ci].pathElement, binaryIsMember|binaryHasChildren, children[ci].set)
+			mi++
+			ci++
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countEntries returns the number of distinct path elements in the two
+// sorted lists.
+func countEntries(members sortedPathElements, children sortedSetNode) int {
+	n := len(members) + len(children)
+	mi, ci := 0, 0
+	for mi < len(members) && ci < len(children) {
+		if c := members[mi].Compare(children[ci].pathElement); c < 0 {
+			mi++
+		} else if c > 0 {
+			ci++
+		} else {
+			n--
+			mi++
+			ci++
+		}
+	}
+	return n
+}
+
+func (e *binaryEncoder) writePathElement(pe PathElement, header byte, prevKey *value.FieldList) error {
+	switch {
+	case pe.FieldName != nil:
+		e.buf = append(e.buf, header|binaryField<<binaryTypeShift)
+		e.writeString(*pe.FieldName)
+	case pe.Key != nil:
+		e.buf = append(e.buf, header|binaryKey<<binaryTypeShift)
+		shared := 0
+		if prevKey != nil {
+			for shared < len(*prevKey) && shared < len(*pe.Key) {
+				prev, cur := (*prevKey)[shared], (*pe.Key)[shared]
+				if prev.Name != cur.Name || !value.Equals(prev.Value, cur.Value) {
+					break
+				}
+				shared++
+			}
+		}
+		e.buf = binary.AppendUvarint(e.buf, uint64(shared))
+		e.buf = binary.AppendUvarint(e.buf, uint64(len(*pe.Key)-shared))
+		for _, f := range (*pe.Key)[shared:] {
+			e.writeString(f.Name)
+			if err := e.writeValue(f.Value); err != nil {
+				return err
+			}
+		}
+	case pe.Value != nil:
+		e.buf = append(e.buf, header|binaryValue<<binaryTypeShift)
+		return e.writeValue(*pe.Value)
+	case pe.Index != nil:
+		e.buf = append(e.buf, header|binaryIndex<<binaryTypeShift)
+		e.buf = binary.AppendVarint(e.buf, int64(*pe.Index))
+	default:
+		return errors.New("invalid PathElement")
+	}
+	return nil
+}
+
+func (e *binaryEncoder) writeString(s string) {
+	if i, ok := e.strings[s]; ok {
+		e.buf = binary.AppendUvarint(e.buf, i+1)
+		return
+	}
+	e.strings[s] = uint64(len(e.strings))
+	e.buf = append(e.buf, 0)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *binaryEncoder) writeValue(v value.Value) error {
+	switch {
+	case v.IsNull():
+		e.buf = append(e.buf, binaryNull)
+	case v.IsBool():
+		if v.AsBool() {
+			e.buf = append(e.buf, binaryTrue)
+		} else {
+			e.buf = append(e.buf, binaryFalse)
+		}
+	case v.IsInt():
+		e.buf = append(e.buf, binaryInt)
+		e.buf = binary.AppendVarint(e.buf, v.AsInt())
+	case v.IsFloat():
+		e.buf = append(e.buf, binaryFloat)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v.AsFloat()))
+	case v.IsString():
+		e.buf = append(e.buf, binaryString)
+		e.writeString(v.AsString())
+	default:
+		b, err := value.ToJSON(v)
+		if err != nil {
+			return err
+		}
+		e.buf = append(e.buf, binaryJSON)
+		e.buf = binary.AppendUvarint(e.buf, uint64(len(b)))
+		e.buf = append(e.buf, b...)
+	}
+	return nil
+}
+
+type binaryDecoder struct {
+	buf []byte
+	pos int
+	// strings, and their values, are shared by all the path elements
+	// that use them.
+	strings      []*string
+	stringValues []value.Value
+
+	// indices and values are allocated in batches to reduce the number
+	// of allocations.
+	indices []int
+	values  []value.Value
+}
+
+const binaryBatchSize = 64
+
+func (d *binaryDecoder) newIndex(i int) *int {
+	if len(d.indices) == cap(d.indices) {
+		d.indices = make([]int, 0, binaryBatchSize)
+	}
+	d.indices = append(d.indices, i)
+	return &d.indices[len(d.indices)-1]
+}
+
+func (d *binaryDecoder) newValue(v value.Value) *value.Value {
+	if len(d.values) == cap(d.values) {
+		d.values = make([]value.Value, 0, binaryBatchSize)
+	}
+	d.values = append(d.values, v)
+	return &d.values[len(d.values)-1]
+}
+
+func (d *binaryDecoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w at offset %d: %v", ErrInvalidBinarySet, d.pos, fmt.Sprintf(format, args...))
+}
+
+func (d *binaryDecoder) readSet() (*Set, error) {
+	n, err := d.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	// Each entry takes at least two bytes.
+	if n > uint64(len(d.buf)-d.pos)/2 {
+		return nil, d.errorf("too many entries: %d", n)
+	}
+	s := &Set{}
+	var prevKey *value.FieldList
+	for i := uint64(0); i < n; i++ {
+		if d.pos >= len(d.buf) {
+			return nil, d.errorf("unexpected end of input")
+		}
+		header := d.buf[d.pos]
+		d.pos++
+		if header&^(binaryIsMember|binaryHasChildren|binaryTypeMask) != 0 {
+			return nil, d.errorf("invalid header %#x", header)
+		}
+		pe, err := d.readPathElement((header&binaryTypeMask)>>binaryTypeShift, prevKey)
+		if err != nil {
+			return nil, err
+		}
+		prevKey = pe.Key
+		if header&binaryIsMember != 0 {
+			// Since the entries were serialized in the right order,
+			// we just verify that and append.
+			m := &s.Members.members
+			if len(*m) == 0 || (*m)[len(*m)-1].Less(pe) {
+				*m = append(*m, pe)
+			} else {
+				s.Members.Insert(pe)
+			}
+		}
+		if header&binaryHasChildren != 0 {
+			child, err := d.readSet()
+			if err != nil {
+				return nil, err
+			}
+			m := &s.Children.members
+			if len(*m) == 0 || (*m)[len(*m)-1].pathElement.Less(pe) {
+				*m = append(*m, setNode{pe, child})
+			} else {
+				*s.Children.Descend(pe) = *child
+			}
+		}
+	}
+	return s, nil
+}
+
+func (d *binaryDecoder) readPathElement(peType byte, prevKey *value.FieldList) (PathElement, error) {
+	switch peType {
+	case binaryField:
+		name, err := d.readString()
+		if err != nil {
+			return PathElement{}, err
+		}
+		return PathElement{FieldName: name}, nil
+	case binaryKey:
+		shared, err := d.readUvarint()
+		if err != nil {
+			return PathElement{}, err
+		}
+		if prevKey == nil && shared > 0 || prevKey != nil && shared > uint64(len(*prevKey)) {
+			return PathElement{}, d.errorf("invalid shared key prefix: %d", shared)
+		}
+		rest, err := d.readUvarint()
+		if err != nil {
+			return PathElement{}, err
+		}
+		if rest > uint64(len(d.buf)-d.pos)/2 {
+			return PathElement{}, d.errorf("too many key fields: %d", rest)
+		}
+		key := make(value.FieldList, shared, shared+rest)
+		if shared > 0 {
+			copy(key, *prevKey)
+		}
+		for i := uint64(0); i < rest; i++ {
+			name, err := d.readString()
+			if err != nil {
+				return PathElement{}, err
+			}
+			v, err := d.readValue()
+			if err != nil {
+				return PathElement{}, err
+			}
+			key = append(key, value.Field{Name: *name, Value: v})
+		}
+		return PathElement{Key: &key}, nil
+	case binaryValue:
+		v, err := d.readValue()
+		if err != nil {
+			return PathElement{}, err
+		}
+		return PathElement{Value: d.newValue(v)}, nil
+	default: // binaryIndex
+		i, n := binary.Varint(d.buf[d.pos:])
+		if n <= 0 || i < math.MinInt || i > math.MaxInt {
+			return PathElement{}, d.errorf("invalid index")
+		}
+		d.pos += n
+		return PathElement{Index: d.newIndex(int(i))}, nil
+	}
+}
+
+func (d *binaryDecoder) readUvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.buf[d.pos:])
+	if n <= 0 {
+		return 0, d.errorf("invalid uvarint")
+	}
+	d.pos += n
+	return v, nil
+}
+
+func (d *binaryDecoder) readString() (*string, error) {
+	i, err := d.readStringIndex()
+	if err != nil {
+		return nil, err
+	}
+	return d.strings[i], nil
+}
+
+// readStringIndex reads a string and returns its index in the table.
+func (d *binaryDecoder) readStringIndex() (int, error) {
+	i, err := d.readUvarint()
+	if err != nil {
+		return 0, err
+	}
+	if i > 0 {
+		if i > uint64(len(d.strings)) {
+			return 0, d.errorf("unknown string reference: %d", i)
+		}
+		return int(i - 1), nil
+	}
+	l, err := d.readUvarint()
+	if err != nil {
+		return 0, err
+	}
+	if l > uint64(len(d.buf)-d.pos) {
+		return 0, d.errorf("string too long: %d", l)
+	}
+	s := string(d.buf[d.pos : d.pos+int(l)])
+	d.pos += int(l)
+	d.strings = append(d.strings, &s)
+	d.stringValues = append(d.stringValues, nil)
+	return len(d.strings) - 1, nil
+}
+
+func (d *binaryDecoder) readValue() (value.Value, error) {
+	if d.pos >= len(d.buf) {
+		return nil, d.errorf("unexpected end of input")
+	}
+	tag := d.buf[d.pos]
+	d.pos++
+	switch tag {
+	case binaryNull:
+		return value.NewValueInterface(nil), nil
+	case binaryFalse:
+		return value.NewValueInterface(false), nil
+	case binaryTrue:
+		return value.NewValueInterface(true), nil
+	case binaryInt:
+		i, n := binary.Varint(d.buf[d.pos:])
+		if n <= 0 {
+			return nil, d.errorf("invalid int")
+		}
+		d.pos += n
+		return value.NewValueInterface(i), nil
+	case binaryFloat:
+		if len(d.buf)-d.pos < 8 {
+			return nil, d.errorf("unexpected end of input")
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(d.buf[d.pos:]))
+		d.pos += 8
+		return value.NewValueInterface(f), nil
+	case binaryString:
+		i, err := d.readStringIndex()
+		if err != nil {
+			return nil, err
+		}
+		if d.stringValues[i] == nil {
+			d.stringValues[i] = value.NewValueInterface(*d.strings[i])
+		}
+		return d.stringValues[i], nil
+	case binaryJSON:
+		l, err := d.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if l > uint64(len(d.buf)-d.pos) {
+			return nil, d.errorf("value too long: %d", l)
+		}
+		v, err := value.FromJSON(d.buf[d.pos : d.pos+int(l)])
+		if err != nil {
+			return nil, d.errorf("invalid value: %v", err)
+		}
+		d.pos += int(l)
+		return v, nil
+	default:
+		return nil, d.errorf("invalid value type %d", tag)
+	}
+}
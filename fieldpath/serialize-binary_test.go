@@ -0,0 +1,148 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fieldpath
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSerializeBinary(t *testing.T) {
+	for i := 0; i < 500; i++ {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			x := NewSet()
+			for j := 0; j < 50; j++ {
+				x.Insert(randomPathMaker.makePath(2, 5))
+			}
+			b, err := x.ToBinary()
+			if err != nil {
+				t.Fatalf("Failed to serialize %#v: %v", x, err)
+			}
+			x2 := NewSet()
+			err = x2.FromBinary(b)
+			if err != nil {
+				t.Fatalf("Failed to deserialize %x: %v\n%#v", b, err, x)
+			}
+			if !x2.Equals(x) {
+				t.Fatalf("failed to reproduce original:\n\n%s\n\n%x\n\n%s\n", x, b, x2)
+			}
+		})
+	}
+}
+
+func TestSerializeBinaryValues(t *testing.T) {
+	x := NewSet(
+		MakePathOrDie(),
+		MakePathOrDie("a"),
+		MakePathOrDie("a", -1),
+		MakePathOrDie("a", 1<<40),
+		MakePathOrDie("b", _V(nil)),
+		MakePathOrDie("b", _V(1.5)),
+		MakePathOrDie("b", _V(-7)),
+		MakePathOrDie("b", _V("with \"quotes\"\n")),
+		MakePathOrDie("b", _V([]interface{}{"not", "a", "scalar"})),
+		MakePathOrDie("c", KeyByFields("a", 1, "b", "x", "c", true), "d"),
+		MakePathOrDie("c", KeyByFields("a", 1, "b", "x", "c", false)),
+		MakePathOrDie("c", KeyByFields("a", 1, "b", "y")),
+		MakePathOrDie("c", KeyByFields("a", 2)),
+		MakePathOrDie("", "a"),
+	)
+	b, err := x.ToBinary()
+	if err != nil {
+		t.Fatalf("Failed to serialize %v: %v", x, err)
+	}
+	x2 := NewSet()
+	if err := x2.FromBinary(b); err != nil {
+		t.Fatalf("Failed to deserialize %x: %v", b, err)
+	}
+	if !x2.Equals(x) {
+		t.Fatalf("failed to reproduce original:\n\n%s\n\n%s\n", x, x2)
+	}
+}
+
+func TestSerializeBinaryIsSmaller(t *testing.T) {
+	x := NewSet()
+	for j := 0; j < 200; j++ {
+		x.Insert(randomPathMaker.makePath(3, 7))
+	}
+	j, err := x.ToJSON()
+	if err != nil {
+		t.Fatalf("Failed to serialize to JSON: %v", err)
+	}
+	b, err := x.ToBinary()
+	if err != nil {
+		t.Fatalf("Failed to serialize to binary: %v", err)
+	}
+	if len(b)*2 > len(j) {
+		t.Errorf("expected binary encoding (%d bytes) to be much smaller than JSON (%d bytes)", len(b), len(j))
+	}
+}
+
+func TestSerializeBinaryGoldenData(t *testing.T) {
+	x := NewSet(
+		MakePathOrDie("a"),
+		MakePathOrDie("a", KeyByFields("name", "a", "port", 1)),
+		MakePathOrDie("b", 1),
+	)
+	b, err := x.ToBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "01" + // version
+		"02" + // two entries
+		"03" + "00" + "01" + "61" + // "a", member with children, new string "a"
+		"01" + // one entry
+		"05" + "00" + "02" + // key, no shared field, two fields
+		"00" + "04" + "6e616d65" + "05" + "01" + // "name": reference to "a"
+		"00" + "04" + "706f7274" + "03" + "02" + // "port": 1
+		"02" + "00" + "01" + "62" + // "b", children only, new string "b"
+		"01" + // one entry
+		"0d" + "02" // index 1
+	if got := fmt.Sprintf("%x", b); got != expected {
+		t.Errorf("got:  %s\nwant: %s", got, expected)
+	}
+}
+
+func TestDeserializeBinaryErrors(t *testing.T) {
+	x := NewSet(
+		MakePathOrDie("a", KeyByFields("name", "a"), "b"),
+		MakePathOrDie("a", KeyByFields("name", "b"), 1),
+	)
+	b, err := x.ToBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(b); i++ {
+		if err := NewSet().FromBinary(b[:i]); !errors.Is(err, ErrInvalidBinarySet) {
+			t.Errorf("expected truncated input %x to fail, got: %v", b[:i], err)
+		}
+	}
+	for _, input := range [][]byte{
+		{},
+		{2, 0},
+		{1, 0, 0},
+		{1, 1, 0x10, 0},
+		{1, 1, 0, 5},
+		{1, 1, 4, 1, 0},
+		{1, 1, 0x8, 9},
+	} {
+		if err := NewSet().FromBinary(input); !errors.Is(err, ErrInvalidBinarySet) {
+			t.Errorf("expected %x to fail, got: %v", input, err)
+		}
+	}
+}
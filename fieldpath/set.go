@@ -268,9 +268,15 @@ func (s *SetMatcher) Merge(s2 *SetMatcher) *SetMatcher {
 type SetMemberMatcher struct {
 	// Path provides a matcher to match members of a Set.
 	// If Path is a wildcard, all members of a Set are included in the match.
+	// Otherwise, if Path has Predicates, the members matched by all of them
+	// are included in the match, and the PathElement of Path is ignored.
 	// Otherwise, if any Path is Equal to a member of a Set, that member is
-	// included in the match and the children of that member are matched
-	// against the Child matcher.
+	// included in the match. The children of the matched members are
+	// matched against the Child matcher.
+	//
+	// When several members of a SetMatcher match the same member of a Set,
+	// only the first one in sort order is used: wildcards take precedence
+	// over Predicates, which take precedence over exact path elements.
 	Path PathElementMatcher
 
 	// Child provides a matcher to use for the children of matched members of a Set.
@@ -283,13 +289,36 @@ type PathElementMatcher struct {
 	// If set, PathElement is ignored.
 	Wildcard bool
 
+	// Predicates, if not empty, indicates that a PathElement is matched if
+	// it is matched by all the predicates. If set, PathElement is ignored.
+	Predicates []ValuePredicate
+
 	// PathElement indicates that a PathElement is matched if it is Equal
 	// to this PathElement.
 	PathElement
 }
 
+// Matches returns true if the PathElement is matched by this matcher.
+func (p PathElementMatcher) Matches(pe PathElement) bool {
+	if p.Wildcard {
+		return true
+	}
+	if len(p.Predicates) == 0 {
+		return p.PathElement.Equals(pe)
+	}
+	for _, vp := range p.Predicates {
+		if !vp.Matches(pe) {
+			return false
+		}
+	}
+	return true
+}
+
 func (p PathElementMatcher) Equals(p2 PathElementMatcher) bool {
-	return p.Wildcard != p2.Wildcard && p.PathElement.Equals(p2.PathElement)
+	if p.Wildcard || p2.Wildcard {
+		return p.Wildcard == p2.Wildcard
+	}
+	return comparePredicates(p.Predicates, p2.Predicates) == 0 && p.PathElement.Equals(p2.PathElement)
 }
 
 func (p PathElementMatcher) Less(p2 PathElementMatcher) bool {
@@ -298,15 +327,20 @@ func (p PathElementMatcher) Less(p2 PathElementMatcher) bool {
 	} else if p2.Wildcard {
 		return false
 	}
-	return p.PathElement.Less(p2.PathElement)
+	return p.Compare(p2) < 0
 }
 
 func (p PathElementMatcher) Compare(p2 PathElementMatcher) int {
-	if p.Wildcard && !p2.Wildcard {
+	if p.Wildcard && p2.Wildcard {
+		return 0
+	} else if p.Wildcard {
 		return -1
 	} else if p2.Wildcard {
 		return 1
 	}
+	if c := comparePredicates(p.Predicates, p2.Predicates); c != 0 {
+		return c
+	}
 	return p.PathElement.Compare(p2.PathElement)
 }
 
@@ -319,7 +353,7 @@ func (s *Set) FilterIncludeMatches(pattern *SetMatcher) *Set {
 	members := PathElementSet{}
 	for _, m := range s.Members.members {
 		for _, pm := range pattern.members {
-			if pm.Path.Wildcard || pm.Path.PathElement.Equals(m) {
+			if pm.Path.Matches(m) {
 				members.Insert(m)
 				break
 			}
@@ -680,7 +714,7 @@ func (s *SetNodeMap) FilterIncludeMatches(pattern *SetMatcher) *SetNodeMap {
 	var out sortedSetNode
 	for _, member := range s.members {
 		for _, c := range pattern.members {
-			if c.Path.Wildcard || c.Path.PathElement.Equals(member.pathElement) {
+			if c.Path.Matches(member.pathElement) {
 				childSet := member.set.FilterIncludeMatches(c.Child)
 				if childSet.Size() > 0 {
 					out = append(out, setNode{
@@ -731,7 +765,8 @@ func (s *SetNodeMap) Leaves() *SetNodeMap {
 // specific field paths and all of their children.
 // NewIncludeMatcherFilter can be used to create a filter that removes all fields except
 // the fields that match a field path matcher. PrefixMatcher and MakePrefixMatcherOrDie
-// can be used to define field path patterns.
+// can be used to define field path patterns, or ParseSetMatcher to compile them from
+// their textual form.
 type Filter interface {
 	// Filter returns a filtered copy of the set.
 	Filter(*Set) *Set
@@ -86,9 +86,11 @@ func BenchmarkFieldSet(b *testing.B) {
 		}
 		operands := make([]*Set, 500)
 		serialized := make([][]byte, len(operands))
+		serializedBinary := make([][]byte, len(operands))
 		for i := range operands {
 			operands[i] = makeSet()
 			serialized[i], _ = operands[i].ToJSON()
+			serializedBinary[i], _ = operands[i].ToBinary()
 		}
 		randOperand := func() *Set { return operands[rand.Intn(len(operands))] }
 
@@ -117,6 +119,19 @@ func BenchmarkFieldSet(b *testing.B) {
 				s.FromJSON(bytes.NewReader(serialized[rand.Intn(len(serialized))]))
 			}
 		})
+		b.Run(fmt.Sprintf("serialize-binary-%v", here.size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				randOperand().ToBinary()
+			}
+		})
+		b.Run(fmt.Sprintf("deserialize-binary-%v", here.size), func(b *testing.B) {
+			b.ReportAllocs()
+			s := NewSet()
+			for i := 0; i < b.N; i++ {
+				s.FromBinary(serializedBinary[rand.Intn(len(serializedBinary))])
+			}
+		})
 
 		b.Run(fmt.Sprintf("union-%v", here.size), func(b *testing.B) {
 			b.ReportAllocs()
@@ -0,0 +1,389 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+	"sigs.k8s.io/yaml"
+	goyaml "sigs.k8s.io/yaml/goyaml.v2"
+)
+
+// blame prints an object annotated with the managers owning each of its
+// fields.
+type blame struct {
+	operationBase
+
+	objectPath string
+	// managedFieldsPath is a file containing a list of managed fields
+	// entries. If empty, the entries are read from the
+	// metadata.managedFields of the object.
+	managedFieldsPath string
+	format            string
+}
+
+// blameOwner is a manager owning a field, as printed in the JSON output.
+type blameOwner struct {
+	Manager     string                           `json:"manager"`
+	Operation   fieldpath.ManagedFieldsOperation `json:"operation"`
+	APIVersion  string                           `json:"apiVersion"`
+	Subresource string                           `json:"subresource,omitempty"`
+	Time        *time.Time                       `json:"time,omitempty"`
+}
+
+func (o blameOwner) String() string {
+	if o.Subresource != "" {
+		return fmt.Sprintf("%v (%v %v, %v)", o.Manager, o.Operation, o.APIVersion, o.Subresource)
+	}
+	return fmt.Sprintf("%v (%v %v)", o.Manager, o.Operation, o.APIVersion)
+}
+
+// blameField is a field of the object and its owners, as printed in the
+// JSON output. Value is only set for leaf fields.
+type blameField struct {
+	Path   string          `json:"path"`
+	Value  json.RawMessage `json:"value,omitempty"`
+	Owners []blameOwner    `json:"owners"`
+}
+
+// blameNode is a field of the object, with the owners of the field.
+type blameNode struct {
+	// key is the name of the field in its parent map, if any.
+	key    string
+	path   fieldpath.Path
+	owners []blameOwner
+
+	// Non-empty maps and lists have children, other fields are leaves.
+	isList   bool
+	children []*blameNode
+	leaf     value.Value
+}
+
+func (b blame) Execute(w io.Writer) error {
+	object, entries, err := b.readObject()
+	if err != nil {
+		return err
+	}
+	if b.managedFieldsPath != "" {
+		if entries, err = b.readManagedFields(); err != nil {
+			return err
+		}
+	}
+	managed, times, err := fieldpath.DecodeManagedFields(entries)
+	if err != nil {
+		return err
+	}
+	owners := map[string]blameOwner{}
+	for _, entry := range entries {
+		id, err := fieldpath.BuildManagerIdentifier(entry)
+		if err != nil {
+			return err
+		}
+		owner := blameOwner{
+			Manager:     entry.Manager,
+			Operation:   entry.Operation,
+			APIVersion:  entry.APIVersion,
+			Subresource: entry.Subresource,
+		}
+		if t, ok := times[id]; ok {
+			owner.Time = &t
+		}
+		owners[id] = owner
+	}
+
+	tv, err := b.parser.Type(b.typeName).FromUnstructured(object)
+	if err != nil {
+		return fmt.Errorf("unable to validate file %q:\n%v", b.objectPath, err)
+	}
+	bb := blameBuilder{
+		schema: tv.Schema(),
+		index:  fieldpath.NewOwnershipIndex(managed),
+		owners: owners,
+	}
+	root, err := bb.build(nil, "", tv.AsValue(), tv.TypeRef())
+	if err != nil {
+		return err
+	}
+
+	switch b.format {
+	case "json":
+		fields := []blameField{}
+		if err := root.fields(&fields); err != nil {
+			return err
+		}
+		out, err := json.MarshalIndent(fields, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	default:
+		var lines []string
+		if len(root.children) == 0 {
+			s, err := formatScalar(root.leaf)
+			if err != nil {
+				return err
+			}
+			lines = []string{s}
+		} else if lines, err = root.yamlLines(""); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", strings.Join(lines, "\n"))
+		return err
+	}
+}
+
+// readObject reads the object, and removes its metadata.managedFields,
+// which are returned separately.
+func (b blame) readObject() (interface{}, []fieldpath.ManagedFieldsEntry, error) {
+	bytes, err := ioutil.ReadFile(b.objectPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read file %q: %v", b.objectPath, err)
+	}
+	var object interface{}
+	if err := goyaml.Unmarshal(bytes, &object); err != nil {
+		return nil, nil, fmt.Errorf("unable to parse file %q: %v", b.objectPath, err)
+	}
+	top, ok := object.(map[interface{}]interface{})
+	if !ok {
+		return object, nil, nil
+	}
+	metadata, ok := top["metadata"].(map[interface{}]interface{})
+	if !ok {
+		return object, nil, nil
+	}
+	managedFields, ok := metadata["managedFields"]
+	if !ok {
+		return object, nil, nil
+	}
+	delete(metadata, "managedFields")
+	raw, err := goyaml.Marshal(managedFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := parseManagedFields(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid metadata.managedFields in %q: %v", b.objectPath, err)
+	}
+	return object, entries, nil
+}
+
+func (b blame) readManagedFields() ([]fieldpath.ManagedFieldsEntry, error) {
+	bytes, err := ioutil.ReadFile(b.managedFieldsPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file %q: %v", b.managedFieldsPath, err)
+	}
+	entries, err := parseManagedFields(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid managed fields in %q: %v", b.managedFieldsPath, err)
+	}
+	return entries, nil
+}
+
+func parseManagedFields(bytes []byte) ([]fieldpath.ManagedFieldsEntry, error) {
+	var entries []fieldpath.ManagedFieldsEntry
+	if err := yaml.Unmarshal(bytes, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+type blameBuilder struct {
+	schema *schema.Schema
+	index  *fieldpath.OwnershipIndex
+	owners map[string]blameOwner
+}
+
+func (bb *blameBuilder) build(path fieldpath.Path, key string, v value.Value, tr schema.TypeRef) (*blameNode, error) {
+	n := &blameNode{key: key, path: path}
+	for _, owner := range bb.index.Owners(path) {
+		n.owners = append(n.owners, bb.owners[owner.Manager])
+	}
+	atom, _ := bb.schema.Resolve(tr)
+	switch {
+	case v.IsMap() && atom.Map != nil && atom.Map.ElementRelationship != schema.Atomic:
+		m := v.AsMap()
+		keys := make([]string, 0, m.Length())
+		m.Iterate(func(k string, _ value.Value) bool {
+			keys = append(keys, k)
+			return true
+		})
+		sort.Strings(keys)
+		for _, k := range keys {
+			k := k
+			childTR := atom.Map.ElementType
+			if sf, ok := atom.Map.FindField(k); ok {
+				childTR = sf.Type
+			}
+			child, _ := m.Get(k)
+			c, err := bb.build(append(path.Copy(), fieldpath.PathElement{FieldName: &k}), k, child, childTR)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, c)
+		}
+	case v.IsList() && atom.List != nil && atom.List.ElementRelationship != schema.Atomic:
+		n.isList = true
+		l := v.AsList()
+		for i := 0; i < l.Length(); i++ {
+			item := l.At(i)
+			pe, err := bb.listItemPathElement(atom.List, i, item)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", path, err)
+			}
+			c, err := bb.build(append(path.Copy(), pe), "", item, atom.List.ElementType)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, c)
+		}
+	}
+	if len(n.children) == 0 {
+		n.leaf = v
+	}
+	return n, nil
+}
+
+func (bb *blameBuilder) listItemPathElement(list *schema.List, i int, item value.Value) (fieldpath.PathElement, error) {
+	if list.ElementRelationship != schema.Associative {
+		return fieldpath.PathElement{Index: &i}, nil
+	}
+	if len(list.Keys) == 0 {
+		return fieldpath.PathElement{Value: &item}, nil
+	}
+	if !item.IsMap() {
+		return fieldpath.PathElement{}, fmt.Errorf("associative list with keys may not have non-map elements")
+	}
+	m := item.AsMap()
+	keys := value.FieldList{}
+	for _, name := range list.Keys {
+		if v, ok := m.Get(name); ok {
+			keys = append(keys, value.Field{Name: name, Value: v})
+			continue
+		}
+		// Missing keys take the default of the field, if any.
+		if atom, ok := bb.schema.Resolve(list.ElementType); ok && atom.Map != nil {
+			if sf, ok := atom.Map.FindField(name); ok && sf.Default != nil {
+				keys = append(keys, value.Field{Name: name, Value: value.NewValueInterface(sf.Default)})
+				continue
+			}
+		}
+		return fieldpath.PathElement{}, fmt.Errorf("associative list item is missing key %q", name)
+	}
+	keys.Sort()
+	return fieldpath.PathElement{Key: &keys}, nil
+}
+
+// fields appends the fields that are leaves or that have owners, in
+// document order.
+func (n *blameNode) fields(out *[]blameField) error {
+	if len(n.path) > 0 && (len(n.children) == 0 || len(n.owners) > 0) {
+		f := blameField{Path: n.path.String(), Owners: n.owners}
+		if f.Owners == nil {
+			f.Owners = []blameOwner{}
+		}
+		if len(n.children) == 0 {
+			v, err := value.ToJSON(n.leaf)
+			if err != nil {
+				return err
+			}
+			f.Value = v
+		}
+		*out = append(*out, f)
+	}
+	for _, c := range n.children {
+		if err := c.fields(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// yamlLines returns the YAML lines of the children of a map or list
+// node, indented by indent, with the owners of each field in a comment.
+func (n *blameNode) yamlLines(indent string) ([]string, error) {
+	var lines []string
+	for _, c := range n.children {
+		prefix := indent + c.key + ":"
+		if n.isList {
+			prefix = indent + "-"
+		}
+		if len(c.children) == 0 {
+			s, err := formatScalar(c.leaf)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, prefix+" "+s+c.comment())
+			continue
+		}
+		childIndent := indent + "  "
+		if c.isList && !n.isList {
+			// Lists are not indented under their key.
+			childIndent = indent
+		}
+		body, err := c.yamlLines(childIndent)
+		if err != nil {
+			return nil, err
+		}
+		if n.isList && len(c.owners) == 0 {
+			// Put the first field of the item on the same line as the dash.
+			body[0] = prefix + " " + strings.TrimPrefix(body[0], childIndent)
+		} else {
+			lines = append(lines, prefix+c.comment())
+		}
+		lines = append(lines, body...)
+	}
+	return lines, nil
+}
+
+func (n *blameNode) comment() string {
+	if len(n.owners) == 0 {
+		return ""
+	}
+	owners := make([]string, len(n.owners))
+	for i, o := range n.owners {
+		owners[i] = o.String()
+	}
+	return "  # " + strings.Join(owners, ", ")
+}
+
+// formatScalar formats a leaf value, which may be an empty or atomic map
+// or list, on a single line.
+func formatScalar(v value.Value) (string, error) {
+	if v.IsString() && strings.Contains(v.AsString(), "\n") {
+		return strconv.Quote(v.AsString()), nil
+	}
+	if v.IsMap() || v.IsList() {
+		b, err := value.ToJSON(v)
+		return string(b), err
+	}
+	b, err := goyaml.Marshal(v.Unstructured())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
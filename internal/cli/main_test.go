@@ -159,6 +159,24 @@ func TestCompare(t *testing.T) {
 		// just to make sure the command output stays sane. All the
 		// actual operations are unit tested.
 		expectedOutputPath: testdata("scalar-compare-output.txt"),
+	}, {
+		options: Options{
+			schemaPath: testdata("schema.yaml"),
+			compare:    true,
+			lhsPath:    testdata("scalar.yaml"),
+			rhsPath:    testdata("bad-scalar.yaml"),
+			format:     "diff",
+		},
+		expectedOutputPath: testdata("scalar-compare-output.diff"),
+	}, {
+		options: Options{
+			schemaPath: testdata("schema.yaml"),
+			compare:    true,
+			lhsPath:    testdata("scalar.yaml"),
+			rhsPath:    testdata("bad-scalar.yaml"),
+			format:     "json",
+		},
+		expectedOutputPath: testdata("scalar-compare-output.json"),
 	}, {
 		options: Options{
 			schemaPath: testdata("schema.yaml"),
@@ -249,3 +267,126 @@ func TestFieldSet(t *testing.T) {
 		})
 	}
 }
+
+func TestBlame(t *testing.T) {
+	cases := []testCase{{
+		options: Options{
+			schemaPath: testdata("k8s-schema.yaml"),
+			typeName:   "io.k8s.api.apps.v1.Deployment",
+			blame:      testdata("blame-deployment.yaml"),
+		},
+		expectedOutputPath: testdata("blame-output.txt"),
+	}, {
+		options: Options{
+			schemaPath: testdata("k8s-schema.yaml"),
+			typeName:   "io.k8s.api.apps.v1.Deployment",
+			blame:      testdata("blame-deployment.yaml"),
+			format:     "json",
+		},
+		expectedOutputPath: testdata("blame-output.json"),
+	}, {
+		options: Options{
+			schemaPath:        testdata("k8s-schema.yaml"),
+			typeName:          "io.k8s.api.apps.v1.Deployment",
+			blame:             testdata("blame-deployment.yaml"),
+			managedFieldsPath: testdata("blame-managedfields.yaml"),
+		},
+		expectedOutputPath: testdata("blame-managedfields-output.txt"),
+	}, {
+		options: Options{
+			schemaPath: testdata("schema.yaml"),
+			blame:      testdata("bad-schema.yaml"),
+		},
+		expectErr: true,
+	}}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.expectedOutputPath, func(t *testing.T) {
+			op, err := tt.options.Resolve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b bytes.Buffer
+			err = op.Execute(&b)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("unexpected success")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.checkOutput(t, b.Bytes())
+		})
+	}
+
+	o := Options{
+		schemaPath: testdata("k8s-schema.yaml"),
+		blame:      testdata("blame-deployment.yaml"),
+		format:     "xml",
+	}
+	if _, err := o.Resolve(); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	cases := []testCase{{
+		options: Options{
+			openAPIPath: testdata("openapi.yaml"),
+			printSchema: true,
+		},
+		expectedOutputPath: testdata("openapi-schema.yaml"),
+	}, {
+		options: Options{
+			schemaPath:   testdata("openapi-schema.yaml"),
+			typeName:     "io.k8s.api.apps.v1.Deployment",
+			printOpenAPI: true,
+		},
+		expectedOutputPath: testdata("openapi-export.yaml"),
+	}, {
+		options: Options{
+			openAPIPath:  testdata("openapi.yaml"),
+			typeName:     "io.k8s.api.apps.v1.Deployment",
+			validatePath: testdata("openapi-deployment.yaml"),
+		},
+	}, {
+		options: Options{
+			openAPIPath:  testdata("openapi.yaml"),
+			typeName:     "io.k8s.api.apps.v1.Deployment",
+			validatePath: testdata("bad-schema.yaml"),
+		},
+		expectErr: true,
+	}}
+
+	for _, tt := range cases {
+		tt := tt
+		t.Run(tt.expectedOutputPath, func(t *testing.T) {
+			op, err := tt.options.Resolve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b bytes.Buffer
+			err = op.Execute(&b)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("unexpected success")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.checkOutput(t, b.Bytes())
+		})
+	}
+
+	o := Options{
+		schemaPath:  testdata("schema.yaml"),
+		openAPIPath: testdata("openapi.yaml"),
+		printSchema: true,
+	}
+	if _, err := o.Resolve(); err == nil {
+		t.Error("expected an error for both --schema and --openapi")
+	}
+}
@@ -17,12 +17,17 @@ limitations under the License.
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 
+	"sigs.k8s.io/structured-merge-diff/v6/openapi"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
 	"sigs.k8s.io/structured-merge-diff/v6/typed"
 	"sigs.k8s.io/structured-merge-diff/v6/value"
+	sigsyaml "sigs.k8s.io/yaml"
+	yaml "sigs.k8s.io/yaml/goyaml.v2"
 )
 
 type Operation interface {
@@ -92,6 +97,36 @@ func (l listTypes) Execute(w io.Writer) error {
 	return nil
 }
 
+type printSchema struct {
+	operationBase
+}
+
+func (p printSchema) Execute(w io.Writer) error {
+	b, err := yaml.Marshal(&p.parser.Schema)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+type printOpenAPI struct {
+	operationBase
+}
+
+func (p printOpenAPI) Execute(w io.Writer) error {
+	doc, err := openapi.FromSchema(&p.parser.Schema, schema.TypeRef{NamedType: &p.typeName})
+	if err != nil {
+		return err
+	}
+	b, err := sigsyaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
 type merge struct {
 	operationBase
 
@@ -128,6 +163,9 @@ type compare struct {
 
 	lhs string
 	rhs string
+
+	format string
+	color  bool
 }
 
 func (c compare) Execute(w io.Writer) error {
@@ -140,6 +178,31 @@ func (c compare) Execute(w io.Writer) error {
 		return err
 	}
 
+	switch c.format {
+	case "diff":
+		d, err := lhs.Diff(rhs)
+		if err != nil {
+			return err
+		}
+		return d.WriteUnified(w, typed.UnifiedDiffOptions{
+			FromName: c.lhs,
+			ToName:   c.rhs,
+			Context:  typed.DefaultUnifiedDiffContext,
+			Color:    c.color,
+		})
+	case "json":
+		d, err := lhs.Diff(rhs)
+		if err != nil {
+			return err
+		}
+		b, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	}
+
 	got, err := lhs.Compare(rhs)
 	if err != nil {
 		return err
@@ -150,9 +213,6 @@ func (c compare) Execute(w io.Writer) error {
 		return err
 	}
 
-	// TODO: I think it'd be neat if we actually emitted a machine-readable
-	// format.
-
 	_, err = fmt.Fprint(w, got.String())
 
 	return err
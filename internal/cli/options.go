@@ -24,17 +24,20 @@ import (
 	"io/ioutil"
 	"os"
 
+	"sigs.k8s.io/structured-merge-diff/v6/openapi"
 	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	yaml "sigs.k8s.io/yaml/goyaml.v2"
 )
 
 var (
-	ErrTooManyOperations = errors.New("exactly one of --merge, --compare, --validate or --fieldset must be provided")
+	ErrTooManyOperations = errors.New("exactly one of --merge, --compare, --validate, --fieldset, --blame, --print-schema or --print-openapi must be provided")
 	ErrNeedTwoArgs       = errors.New("--merge and --compare require both --lhs and --rhs")
 )
 
 type Options struct {
-	schemaPath string
-	typeName   string
+	schemaPath  string
+	openAPIPath string
+	typeName    string
 
 	output string
 
@@ -44,14 +47,26 @@ type Options struct {
 	merge        bool
 	compare      bool
 	fieldset     string
+	blame        string
+	printSchema  bool
+	printOpenAPI bool
 
 	// arguments for merge or compare
 	lhsPath string
 	rhsPath string
+
+	// arguments for blame
+	managedFieldsPath string
+
+	// format of the output, for the operations that support several
+	format string
+	// color the output of --compare
+	color bool
 }
 
 func (o *Options) AddFlags(fs *flag.FlagSet) {
 	fs.StringVar(&o.schemaPath, "schema", "", "Path to the schema file for this operation. Required.")
+	fs.StringVar(&o.openAPIPath, "openapi", "", "Path to an OpenAPI v3 document whose component schemas are converted to the schema for this operation, instead of --schema.")
 	fs.StringVar(&o.typeName, "type-name", "", "Name of type in the schema to use. If empty, the first type in the schema will be used.")
 
 	fs.StringVar(&o.output, "output", "-", "Output location (if the command has output). '-' means stdout.")
@@ -65,25 +80,43 @@ func (o *Options) AddFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&o.merge, "merge", false, "Perform a merge operation between --lhs and --rhs")
 	fs.BoolVar(&o.compare, "compare", false, "Perform a compare operation between --lhs and --rhs")
 	fs.StringVar(&o.fieldset, "fieldset", "", "Path to a file for which we should build a fieldset.")
+	fs.StringVar(&o.blame, "blame", "", "Path to a file to print with the managers owning each of its fields.")
+	fs.BoolVar(&o.printSchema, "print-schema", false, "Print the schema and exit, e.g. to convert --openapi.")
+	fs.BoolVar(&o.printOpenAPI, "print-openapi", false, "Print the type and the types it refers to as an OpenAPI v3 document and exit.")
 
 	fs.StringVar(&o.lhsPath, "lhs", "", "Path to a file containing the left hand side of the operation")
 	fs.StringVar(&o.rhsPath, "rhs", "", "Path to a file containing the right hand side of the operation")
+
+	fs.StringVar(&o.managedFieldsPath, "managed-fields", "", "Path to a file containing the managed fields entries for --blame. If empty, the metadata.managedFields of the object are used.")
+
+	fs.StringVar(&o.format, "format", "", "Output format. --blame supports 'yaml' (the default) and 'json'. --compare supports 'text' (the default), 'diff' for a unified diff and 'json'.")
+	fs.BoolVar(&o.color, "color", false, "Color the unified diff of --compare --format=diff.")
 }
 
 // resolve turns options in to an operation that can be executed.
 func (o *Options) Resolve() (Operation, error) {
 	var base operationBase
-	if o.schemaPath == "" {
+	var err error
+	switch {
+	case o.schemaPath != "" && o.openAPIPath != "":
+		return nil, errors.New("only one of --schema and --openapi can be provided")
+	case o.schemaPath != "":
+		b, err := ioutil.ReadFile(o.schemaPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read schema %q: %v", o.schemaPath, err)
+		}
+		base.parser, err = typed.NewParser(typed.YAMLObject(b))
+		if err != nil {
+			return nil, fmt.Errorf("schema %q has errors:\n%v", o.schemaPath, err)
+		}
+	case o.openAPIPath != "":
+		base.parser, err = parseOpenAPI(o.openAPIPath)
+		if err != nil {
+			return nil, err
+		}
+	default:
 		return nil, errors.New("a schema is required")
 	}
-	b, err := ioutil.ReadFile(o.schemaPath)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read schema %q: %v", o.schemaPath, err)
-	}
-	base.parser, err = typed.NewParser(typed.YAMLObject(b))
-	if err != nil {
-		return nil, fmt.Errorf("schema %q has errors:\n%v", o.schemaPath, err)
-	}
 
 	if o.typeName == "" {
 		types := base.parser.Schema.Types
@@ -97,7 +130,7 @@ func (o *Options) Resolve() (Operation, error) {
 
 	// Count how many operations were requested
 	c := map[bool]int{true: 1}
-	count := c[o.merge] + c[o.compare] + c[o.validatePath != ""] + c[o.listTypes] + c[o.fieldset != ""]
+	count := c[o.merge] + c[o.compare] + c[o.validatePath != ""] + c[o.listTypes] + c[o.fieldset != ""] + c[o.blame != ""] + c[o.printSchema] + c[o.printOpenAPI]
 	if count > 1 {
 		return nil, ErrTooManyOperations
 	}
@@ -105,6 +138,10 @@ func (o *Options) Resolve() (Operation, error) {
 	switch {
 	case o.listTypes:
 		return listTypes{base}, nil
+	case o.printSchema:
+		return printSchema{base}, nil
+	case o.printOpenAPI:
+		return printOpenAPI{base}, nil
 	case o.validatePath != "":
 		return validation{base, o.validatePath}, nil
 	case o.merge:
@@ -116,13 +153,51 @@ func (o *Options) Resolve() (Operation, error) {
 		if o.lhsPath == "" || o.rhsPath == "" {
 			return nil, ErrNeedTwoArgs
 		}
-		return compare{base, o.lhsPath, o.rhsPath}, nil
+		switch o.format {
+		case "", "text", "diff", "json":
+		default:
+			return nil, fmt.Errorf("unsupported format %q for --compare, must be 'text', 'diff' or 'json'", o.format)
+		}
+		return compare{base, o.lhsPath, o.rhsPath, o.format, o.color}, nil
 	case o.fieldset != "":
 		return fieldset{base, o.fieldset}, nil
+	case o.blame != "":
+		switch o.format {
+		case "", "yaml", "json":
+		default:
+			return nil, fmt.Errorf("unsupported format %q for --blame, must be 'yaml' or 'json'", o.format)
+		}
+		return blame{base, o.blame, o.managedFieldsPath, o.format}, nil
 	}
 	return nil, errors.New("no operation requested")
 }
 
+// parseOpenAPI builds a parser from the component schemas of an OpenAPI
+// v3 document.
+func parseOpenAPI(path string) (*typed.Parser, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read OpenAPI document %q: %v", path, err)
+	}
+	doc, err := openapi.Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse OpenAPI document %q: %v", path, err)
+	}
+	s, err := openapi.ToSchema(doc)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAPI document %q has unsupported constructs:\n%v", path, err)
+	}
+	b, err = yaml.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	parser, err := typed.NewParser(typed.YAMLObject(b))
+	if err != nil {
+		return nil, fmt.Errorf("schema converted from OpenAPI document %q has errors:\n%v", path, err)
+	}
+	return parser, nil
+}
+
 func (o *Options) OpenOutput() (io.WriteCloser, error) {
 	if o.output == "-" {
 		return os.Stdout, nil
@@ -18,7 +18,9 @@ package fixture
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -129,6 +131,26 @@ func (s *State) UpdateObject(tv *typed.TypedValue, version fieldpath.APIVersion,
 	return nil
 }
 
+// UpdateJSONPatch updates the current state with the passed in JSON Patch
+func (s *State) UpdateJSONPatch(patch typed.JSONPatch, version fieldpath.APIVersion, manager string) error {
+	err := s.checkInit(version)
+	if err != nil {
+		return err
+	}
+	s.Live, err = s.Updater.Converter.Convert(s.Live, version)
+	if err != nil {
+		return err
+	}
+	newObj, managers, err := s.Updater.UpdateJSONPatch(s.Live, patch, version, s.Managers, manager)
+	if err != nil {
+		return err
+	}
+	s.Live = newObj
+	s.Managers = managers
+
+	return nil
+}
+
 // Update the current state with the passed in object
 func (s *State) Update(obj typed.YAMLObject, version fieldpath.APIVersion, manager string) error {
 	tv, err := s.Parser.Type(string(version)).FromYAML(FixTabsOrDie(obj), typed.AllowDuplicates)
@@ -167,6 +189,111 @@ func (s *State) Apply(obj typed.YAMLObject, version fieldpath.APIVersion, manage
 	return s.ApplyObject(tv, version, manager, force)
 }
 
+// ApplyWithForcePolicy applies the passed in object to the current state,
+// forcing the conflicts selected by force.
+func (s *State) ApplyWithForcePolicy(obj typed.YAMLObject, version fieldpath.APIVersion, manager string, force *merge.ForcePolicy) error {
+	tv, err := s.Parser.Type(string(version)).FromYAML(FixTabsOrDie(obj))
+	if err != nil {
+		return err
+	}
+	if err := s.checkInit(version); err != nil {
+		return err
+	}
+	s.Live, err = s.Updater.Converter.Convert(s.Live, version)
+	if err != nil {
+		return err
+	}
+	new, managers, err := s.Updater.ApplyWithForcePolicy(s.Live, tv, version, s.Managers, manager, force)
+	if err != nil {
+		return err
+	}
+	s.Managers = managers
+	if new != nil {
+		s.Live = new
+	}
+	return nil
+}
+
+// ApplyYielding applies the passed in object to the current state, and
+// returns the conflicts that it yielded.
+func (s *State) ApplyYielding(obj typed.YAMLObject, version fieldpath.APIVersion, manager string) (merge.Conflicts, error) {
+	tv, err := s.Parser.Type(string(version)).FromYAML(FixTabsOrDie(obj))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkInit(version); err != nil {
+		return nil, err
+	}
+	s.Live, err = s.Updater.Converter.Convert(s.Live, version)
+	if err != nil {
+		return nil, err
+	}
+	new, managers, yielded, err := s.Updater.ApplyYielding(s.Live, tv, version, s.Managers, manager)
+	if err != nil {
+		return nil, err
+	}
+	s.Managers = managers
+	if new != nil {
+		s.Live = new
+	}
+	return yielded, nil
+}
+
+// ApplyWithReport applies the passed in object to the current state, and
+// returns the report of the apply, even if it fails with conflicts.
+func (s *State) ApplyWithReport(obj typed.YAMLObject, version fieldpath.APIVersion, manager string, force bool) (*merge.ApplyReport, error) {
+	tv, err := s.Parser.Type(string(version)).FromYAML(FixTabsOrDie(obj))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkInit(version); err != nil {
+		return nil, err
+	}
+	s.Live, err = s.Updater.Converter.Convert(s.Live, version)
+	if err != nil {
+		return nil, err
+	}
+	new, managers, report, err := s.Updater.ApplyWithReport(s.Live, tv, version, s.Managers, manager, force)
+	if err != nil {
+		return report, err
+	}
+	s.Managers = managers
+	if new != nil {
+		s.Live = new
+	}
+	return report, nil
+}
+
+// TransferOwnership moves the ownership of the fields of set, at
+// version, from the manager from to the manager to.
+func (s *State) TransferOwnership(from, to string, set *fieldpath.Set, version fieldpath.APIVersion) error {
+	before := s.Managers.Copy()
+	managers, err := s.Updater.TransferOwnership(s.Live, s.Managers, from, to, set, version)
+	if err != nil {
+		return err
+	}
+	if !s.Managers.Equals(before) {
+		return fmt.Errorf("expected the managers to be left unchanged, got:\n%v", s.Managers)
+	}
+	s.Managers = managers
+	return nil
+}
+
+// TransferAllOwnership moves the ownership of all the fields of the
+// manager from to the manager to.
+func (s *State) TransferAllOwnership(from, to string) error {
+	before := s.Managers.Copy()
+	managers, err := s.Updater.TransferAllOwnership(s.Live, s.Managers, from, to)
+	if err != nil {
+		return err
+	}
+	if !s.Managers.Equals(before) {
+		return fmt.Errorf("expected the managers to be left unchanged, got:\n%v", s.Managers)
+	}
+	s.Managers = managers
+	return nil
+}
+
 // CompareLive takes a YAML string and returns the comparison with the
 // current live object or an error.
 func (s *State) CompareLive(obj typed.YAMLObject, version fieldpath.APIVersion) (string, error) {
@@ -298,23 +425,29 @@ type ApplyObject struct {
 var _ Operation = &ApplyObject{}
 
 func (a ApplyObject) run(state *State) error {
-	err := state.ApplyObject(a.Object, a.APIVersion, a.Manager, false)
+	return checkConflicts(a.Conflicts, state.ApplyObject(a.Object, a.APIVersion, a.Manager, false))
+}
+
+// checkConflicts returns err, unless expected is not nil and err is nil
+// or the Conflicts of an apply: then it returns an error if the
+// conflicts don't match expected.
+func checkConflicts(expected merge.Conflicts, err error) error {
 	if err != nil {
-		if _, ok := err.(merge.Conflicts); !ok || a.Conflicts == nil {
+		if _, ok := err.(merge.Conflicts); !ok || expected == nil {
 			return err
 		}
 	}
-	if a.Conflicts != nil {
+	if expected != nil {
 		conflicts := merge.Conflicts{}
 		if err != nil {
 			conflicts = err.(merge.Conflicts)
 		}
-		if len(addedConflicts(a.Conflicts, conflicts)) != 0 || len(addedConflicts(conflicts, a.Conflicts)) != 0 {
+		if len(addedConflicts(expected, conflicts)) != 0 || len(addedConflicts(conflicts, expected)) != 0 {
 			return fmt.Errorf("Expected conflicts:\n%v\ngot\n%v\nadded:\n%v\nremoved:\n%v",
-				a.Conflicts.Error(),
+				expected.Error(),
 				conflicts.Error(),
-				addedConflicts(a.Conflicts, conflicts).Error(),
-				addedConflicts(conflicts, a.Conflicts).Error(),
+				addedConflicts(expected, conflicts).Error(),
+				addedConflicts(conflicts, expected).Error(),
 			)
 		}
 	}
@@ -490,6 +623,145 @@ func (f UpdateObject) preprocess(parser Parser) (Operation, error) {
 	return f, nil
 }
 
+// UpdateJSONPatch is a type of operation. It is a controller type of
+// update, with a JSON Patch. Errors are passed along.
+type UpdateJSONPatch struct {
+	Manager    string
+	APIVersion fieldpath.APIVersion
+	Patch      string
+}
+
+var _ Operation = &UpdateJSONPatch{}
+
+func (u UpdateJSONPatch) run(state *State) error {
+	var patch typed.JSONPatch
+	if err := json.Unmarshal([]byte(u.Patch), &patch); err != nil {
+		return err
+	}
+	return state.UpdateJSONPatch(patch, u.APIVersion, u.Manager)
+}
+
+func (u UpdateJSONPatch) preprocess(parser Parser) (Operation, error) {
+	return u, nil
+}
+
+// ApplyWithForcePolicy is a type of operation. It is an apply run by a
+// manager with a given object, which forces the conflicts selected by
+// Force. Like for Apply, the expected conflicts can be specified.
+type ApplyWithForcePolicy struct {
+	Manager    string
+	APIVersion fieldpath.APIVersion
+	Object     typed.YAMLObject
+	Force      *merge.ForcePolicy
+	Conflicts  merge.Conflicts
+}
+
+var _ Operation = &ApplyWithForcePolicy{}
+
+func (a ApplyWithForcePolicy) run(state *State) error {
+	return checkConflicts(a.Conflicts, state.ApplyWithForcePolicy(a.Object, a.APIVersion, a.Manager, a.Force))
+}
+
+func (a ApplyWithForcePolicy) preprocess(parser Parser) (Operation, error) {
+	return a, nil
+}
+
+// ApplyYielding is a type of operation. It is an apply run by a manager
+// with a given object, which yields its conflicts instead of failing. The
+// yielded conflicts must match Yielded.
+type ApplyYielding struct {
+	Manager    string
+	APIVersion fieldpath.APIVersion
+	Object     typed.YAMLObject
+	Yielded    merge.Conflicts
+}
+
+var _ Operation = &ApplyYielding{}
+
+func (a ApplyYielding) run(state *State) error {
+	yielded, err := state.ApplyYielding(a.Object, a.APIVersion, a.Manager)
+	if err != nil {
+		return err
+	}
+	if !yielded.Equals(a.Yielded) {
+		return fmt.Errorf("expected yielded conflicts:\n%v\ngot:\n%v", a.Yielded, yielded)
+	}
+	return nil
+}
+
+func (a ApplyYielding) preprocess(parser Parser) (Operation, error) {
+	return a, nil
+}
+
+// ApplyWithReport is a type of operation. It is an apply run by a
+// manager with a given object, which is forced if Force is set. The
+// report of the apply must match Report, where the leading and trailing
+// spaces of the lines are ignored. Like for Apply, the expected
+// conflicts can be specified.
+type ApplyWithReport struct {
+	Manager    string
+	APIVersion fieldpath.APIVersion
+	Object     typed.YAMLObject
+	Force      bool
+	Conflicts  merge.Conflicts
+	Report     string
+}
+
+var _ Operation = &ApplyWithReport{}
+
+func (a ApplyWithReport) run(state *State) error {
+	report, err := state.ApplyWithReport(a.Object, a.APIVersion, a.Manager, a.Force)
+	if err := checkConflicts(a.Conflicts, err); err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimSpace(a.Report), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	if expected := strings.Join(lines, "\n"); report.String() != expected {
+		return fmt.Errorf("expected report:\n%v\ngot:\n%v", expected, report)
+	}
+	return nil
+}
+
+func (a ApplyWithReport) preprocess(parser Parser) (Operation, error) {
+	return a, nil
+}
+
+// TransferOwnership is a type of operation. It moves the ownership of
+// Fields, at APIVersion, from the manager From to the manager To.
+type TransferOwnership struct {
+	From, To   string
+	Fields     *fieldpath.Set
+	APIVersion fieldpath.APIVersion
+}
+
+var _ Operation = &TransferOwnership{}
+
+func (t TransferOwnership) run(state *State) error {
+	return state.TransferOwnership(t.From, t.To, t.Fields, t.APIVersion)
+}
+
+func (t TransferOwnership) preprocess(parser Parser) (Operation, error) {
+	return t, nil
+}
+
+// TransferAllOwnership is a type of operation. It moves the ownership of
+// all the fields of the manager From to the manager To.
+type TransferAllOwnership struct {
+	From, To string
+}
+
+var _ Operation = &TransferAllOwnership{}
+
+func (t TransferAllOwnership) run(state *State) error {
+	return state.TransferAllOwnership(t.From, t.To)
+}
+
+func (t TransferAllOwnership) preprocess(parser Parser) (Operation, error) {
+	return t, nil
+}
+
 // ChangeParser is a type of operation. It simulates making changes a schema without versioning
 // the schema. This can be used to test the behavior of making backward compatible schema changes,
 // e.g. setting "elementRelationship: atomic" on an existing struct. It also may be used to ensure
@@ -544,6 +816,12 @@ type TestCase struct {
 	// IgnoredFields containing the set to ignore for every version.
 	// IgnoredFields may not be set if IgnoreFilter is set.
 	IgnoredFields map[fieldpath.APIVersion]*fieldpath.Set
+
+	// CompareOptions are used to compare the objects in the updater.
+	CompareOptions []typed.CompareOption
+
+	// DefaultEquality is passed to the updater.
+	DefaultEquality bool
 }
 
 // Test runs the test-case using the given parser and a dummy converter.
@@ -579,6 +857,8 @@ func (tc TestCase) BenchWithConverter(parser Parser, converter merge.Converter)
 		IgnoreFilter:      tc.IgnoreFilter,
 		IgnoredFields:     tc.IgnoredFields,
 		ReturnInputOnNoop: tc.ReturnInputOnNoop,
+		CompareOptions:    tc.CompareOptions,
+		DefaultEquality:   tc.DefaultEquality,
 	}
 	state := State{
 		Updater: updaterBuilder.BuildUpdater(),
@@ -602,6 +882,8 @@ func (tc TestCase) TestWithConverter(parser Parser, converter merge.Converter) e
 		IgnoreFilter:      tc.IgnoreFilter,
 		IgnoredFields:     tc.IgnoredFields,
 		ReturnInputOnNoop: tc.ReturnInputOnNoop,
+		CompareOptions:    tc.CompareOptions,
+		DefaultEquality:   tc.DefaultEquality,
 	}
 	state := State{
 		Updater: updaterBuilder.BuildUpdater(),
@@ -0,0 +1,342 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	. "sigs.k8s.io/structured-merge-diff/v6/internal/fixture"
+	"sigs.k8s.io/structured-merge-diff/v6/merge"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+var quantityParser = func() Parser {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: memory
+      type:
+        namedType: quantity
+    - name: replicas
+      type:
+        scalar: numeric
+- name: quantity
+  scalar: string
+`)
+	if err != nil {
+		panic(err)
+	}
+	return SameVersionParser{T: parser.Type("root")}
+}()
+
+// quantityEquals is a simplified quantity equality, where "1Gi" and
+// "1024Mi" are equal.
+func quantityEquals(lhs, rhs value.Value) bool {
+	normalize := func(s string) string {
+		return strings.Replace(s, "1024Mi", "1Gi", 1)
+	}
+	return normalize(lhs.AsString()) == normalize(rhs.AsString())
+}
+
+func TestCompareOptions(t *testing.T) {
+	quantityEquality := []typed.CompareOption{typed.WithNamedTypeEquality("quantity", quantityEquals)}
+	tests := map[string]TestCase{
+		"update_changes_quantity": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						memory: 1Gi
+					`,
+				},
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						memory: 1024Mi
+					`,
+				},
+			},
+			Object: `
+				memory: 1024Mi
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"controller": fieldpath.NewVersionedSet(_NS(_P("memory")), "v1", false),
+			},
+		},
+		"update_equal_quantity": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						memory: 1Gi
+					`,
+				},
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						memory: 1024Mi
+					`,
+				},
+			},
+			Object: `
+				memory: 1024Mi
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"default": fieldpath.NewVersionedSet(_NS(_P("memory")), "v1", true),
+			},
+			CompareOptions: quantityEquality,
+		},
+		"apply_conflicting_quantity": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						memory: 1Gi
+					`,
+				},
+				Apply{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object: `
+						memory: 1024Mi
+					`,
+					Conflicts: merge.Conflicts{
+						merge.Conflict{Manager: "default", Path: _P("memory")},
+					},
+				},
+			},
+			Object: `
+				memory: 1Gi
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"default": fieldpath.NewVersionedSet(_NS(_P("memory")), "v1", true),
+			},
+		},
+		"apply_equal_quantity": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						memory: 1Gi
+					`,
+				},
+				Apply{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object: `
+						memory: 1024Mi
+					`,
+				},
+			},
+			Object: `
+				memory: 1024Mi
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"default": fieldpath.NewVersionedSet(_NS(_P("memory")), "v1", true),
+				"other":   fieldpath.NewVersionedSet(_NS(_P("memory")), "v1", true),
+			},
+			CompareOptions: quantityEquality,
+		},
+		"update_equal_number": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						replicas: 1
+					`,
+				},
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						replicas: 1.0
+					`,
+				},
+			},
+			Object: `
+				replicas: 1.0
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"default": fieldpath.NewVersionedSet(_NS(_P("replicas")), "v1", true),
+			},
+			CompareOptions: []typed.CompareOption{typed.WithNumericEquality()},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.Test(quantityParser); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+var defaultsParser = func() Parser {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+    - name: replicas
+      type:
+        scalar: numeric
+      default: 1
+`)
+	if err != nil {
+		panic(err)
+	}
+	return SameVersionParser{T: parser.Type("root")}
+}()
+
+func TestDefaultEquality(t *testing.T) {
+	tests := map[string]TestCase{
+		"update_removes_default": {
+			Ops: []Operation{
+				Update{
+					Manager:    "defaulter",
+					APIVersion: "v1",
+					Object: `
+						replicas: 1
+					`,
+				},
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						name: a
+					`,
+				},
+			},
+			Object: `
+				name: a
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"controller": fieldpath.NewVersionedSet(_NS(_P("name")), "v1", false),
+			},
+		},
+		"update_omits_default": {
+			Ops: []Operation{
+				Update{
+					Manager:    "defaulter",
+					APIVersion: "v1",
+					Object: `
+						replicas: 1
+					`,
+				},
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						name: a
+					`,
+				},
+			},
+			Object: `
+				name: a
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"defaulter":  fieldpath.NewVersionedSet(_NS(_P("replicas")), "v1", false),
+				"controller": fieldpath.NewVersionedSet(_NS(_P("name")), "v1", false),
+			},
+			DefaultEquality: true,
+		},
+		"update_omits_applied_default": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						name: a
+						replicas: 1
+					`,
+				},
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						name: a
+					`,
+				},
+			},
+			Object: `
+				name: a
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"default": fieldpath.NewVersionedSet(_NS(_P("name"), _P("replicas")), "v1", true),
+			},
+			DefaultEquality: true,
+		},
+		"update_changes_default": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						name: a
+						replicas: 2
+					`,
+				},
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						name: a
+					`,
+				},
+			},
+			Object: `
+				name: a
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"default":    fieldpath.NewVersionedSet(_NS(_P("name")), "v1", true),
+				"controller": fieldpath.NewVersionedSet(_NS(_P("replicas")), "v1", false),
+			},
+			DefaultEquality: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.Test(defaultsParser); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
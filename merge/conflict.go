@@ -17,11 +17,14 @@ limitations under the License.
 package merge
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
 
 	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
 )
 
 // Conflict is a conflict on a specific field with the current manager of
@@ -30,6 +33,17 @@ import (
 type Conflict struct {
 	Manager string
 	Path    fieldpath.Path
+
+	// APIVersion is the version at which the manager owns the field,
+	// which is the version of Path and of the values.
+	APIVersion fieldpath.APIVersion
+	// Applied is true if the manager owns the field by applying it, and
+	// false if it owns it by updating it.
+	Applied bool
+	// Current is the live value of the field, and Proposed is the value
+	// that the applier wants, or nil if the field is absent or if the
+	// value is unknown.
+	Current, Proposed value.Value
 }
 
 // Conflict is an error.
@@ -37,10 +51,64 @@ var _ error = Conflict{}
 
 // Error formats the conflict as an error.
 func (c Conflict) Error() string {
-	return fmt.Sprintf("conflict with %q: %v", c.Manager, c.Path)
+	return fmt.Sprintf("conflict with %q: %v%v", c.Manager, c.Path, c.values())
+}
+
+// values formats the values of the conflict, if they are known.
+func (c Conflict) values() string {
+	if c.Current == nil && c.Proposed == nil {
+		return ""
+	}
+	format := func(v value.Value) string {
+		if v == nil {
+			return "<absent>"
+		}
+		return value.ToString(v)
+	}
+	return fmt.Sprintf(" (live value: %v, applied value: %v)", format(c.Current), format(c.Proposed))
+}
+
+type conflict struct {
+	Manager    string               `json:"manager"`
+	Path       string               `json:"path"`
+	APIVersion fieldpath.APIVersion `json:"apiVersion,omitempty"`
+	// Operation is "Apply" for appliers, and "Update" for updaters, like
+	// the operation of the managed fields of Kubernetes objects.
+	Operation string           `json:"operation"`
+	Current   *json.RawMessage `json:"current,omitempty"`
+	Proposed  *json.RawMessage `json:"proposed,omitempty"`
+}
+
+// MarshalJSON serializes the conflict, with its values if present.
+func (c Conflict) MarshalJSON() ([]byte, error) {
+	out := conflict{
+		Manager:    c.Manager,
+		Path:       c.Path.String(),
+		APIVersion: c.APIVersion,
+		Operation:  "Update",
+	}
+	if c.Applied {
+		out.Operation = "Apply"
+	}
+	for _, v := range []struct {
+		value value.Value
+		out   **json.RawMessage
+	}{{c.Current, &out.Current}, {c.Proposed, &out.Proposed}} {
+		if v.value == nil {
+			continue
+		}
+		raw, err := value.ToJSON(v.value)
+		if err != nil {
+			return nil, err
+		}
+		r := json.RawMessage(raw)
+		*v.out = &r
+	}
+	return json.Marshal(&out)
 }
 
-// Equals returns true if c == c2
+// Equals returns true if c and c2 are conflicts with the same manager on
+// the same field. The other details of the conflicts aren't compared.
 func (c Conflict) Equals(c2 Conflict) bool {
 	if c.Manager != c2.Manager {
 		return false
@@ -59,9 +127,9 @@ func (conflicts Conflicts) Error() string {
 		return conflicts[0].Error()
 	}
 
-	m := map[string][]fieldpath.Path{}
+	m := map[string][]Conflict{}
 	for _, conflict := range conflicts {
-		m[conflict.Manager] = append(m[conflict.Manager], conflict.Path)
+		m[conflict.Manager] = append(m[conflict.Manager], conflict)
 	}
 
 	managers := []string{}
@@ -75,13 +143,21 @@ func (conflicts Conflicts) Error() string {
 	messages := []string{}
 	for _, manager := range managers {
 		messages = append(messages, fmt.Sprintf("conflicts with %q:", manager))
-		for _, path := range m[manager] {
-			messages = append(messages, fmt.Sprintf("- %v", path))
+		for _, conflict := range m[manager] {
+			messages = append(messages, fmt.Sprintf("- %v%v", conflict.Path, conflict.values()))
 		}
 	}
 	return strings.Join(messages, "\n")
 }
 
+// MarshalJSON serializes the list of conflicts.
+func (conflicts Conflicts) MarshalJSON() ([]byte, error) {
+	if conflicts == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Conflict(conflicts))
+}
+
 // Equals returns true if the lists of conflicts are the same.
 func (c Conflicts) Equals(c2 Conflicts) bool {
 	if len(c) != len(c2) {
@@ -105,17 +181,39 @@ func (c Conflicts) ToSet() *fieldpath.Set {
 }
 
 // ConflictsFromManagers creates a list of conflicts given Managers sets.
+// The conflicts have no values.
 func ConflictsFromManagers(sets fieldpath.ManagedFields) Conflicts {
+	return conflictsFromManagers(sets, nil)
+}
+
+// conflictsFromManagers creates a list of conflicts given Managers sets,
+// with the values of the fields in the objects at the versions of the
+// managers, if they are given.
+func conflictsFromManagers(sets fieldpath.ManagedFields, objects map[fieldpath.APIVersion]versionedObjects) Conflicts {
 	conflicts := []Conflict{}
 
 	for manager, set := range sets {
+		objs, hasValues := objects[set.APIVersion()]
 		set.Set().Iterate(func(p fieldpath.Path) {
-			conflicts = append(conflicts, Conflict{
-				Manager: manager,
-				Path:    p.Copy(),
-			})
+			c := Conflict{
+				Manager:    manager,
+				Path:       p.Copy(),
+				APIVersion: set.APIVersion(),
+				Applied:    set.Applied(),
+			}
+			if hasValues {
+				c.Current = objs.old.Lookup(p)
+				c.Proposed = objs.new.Lookup(p)
+			}
+			conflicts = append(conflicts, c)
 		})
 	}
 
 	return conflicts
 }
+
+// versionedObjects are the objects before and after an operation, at a
+// version.
+type versionedObjects struct {
+	old, new *typed.TypedValue
+}
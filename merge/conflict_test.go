@@ -17,10 +17,13 @@ limitations under the License.
 package merge_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	. "sigs.k8s.io/structured-merge-diff/v6/internal/fixture"
 	"sigs.k8s.io/structured-merge-diff/v6/merge"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
 	"sigs.k8s.io/structured-merge-diff/v6/value"
 )
 
@@ -111,3 +114,100 @@ func TestConflictsFromManagers(t *testing.T) {
 		t.Errorf("Got %v, wanted %v", got.Error(), wanted)
 	}
 }
+
+func TestConflictDetails(t *testing.T) {
+	state := State{
+		Updater: &merge.Updater{Converter: &specificVersionConverter{AcceptedVersions: []fieldpath.APIVersion{"v1", "v2"}}},
+		Parser:  DeducedParser,
+	}
+	if err := state.Apply(typed.YAMLObject(`{"replicas": 1, "image": "nginx"}`), "v1", "applier", false); err != nil {
+		t.Fatalf("failed to apply: %v", err)
+	}
+	if err := state.Update(typed.YAMLObject(`{"replicas": 1, "image": "nginx", "paused": true}`), "v2", "controller"); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	err := state.Apply(typed.YAMLObject(`{"replicas": 3, "paused": false, "new": "a"}`), "v1", "other", false)
+	conflicts, ok := err.(merge.Conflicts)
+	if !ok {
+		t.Fatalf("expected conflicts, got %v", err)
+	}
+	expected := map[string]merge.Conflict{
+		"applier": {
+			Manager:    "applier",
+			Path:       _P("replicas"),
+			APIVersion: "v1",
+			Applied:    true,
+			Current:    _V(1),
+			Proposed:   _V(3),
+		},
+		"controller": {
+			Manager:    "controller",
+			Path:       _P("paused"),
+			APIVersion: "v2",
+			Applied:    false,
+			Current:    _V(true),
+			Proposed:   _V(false),
+		},
+	}
+	if len(conflicts) != len(expected) {
+		t.Fatalf("expected %v conflicts, got %v", len(expected), conflicts)
+	}
+	for _, c := range conflicts {
+		e := expected[c.Manager]
+		if !c.Equals(e) || c.APIVersion != e.APIVersion || c.Applied != e.Applied ||
+			!value.Equals(c.Current, e.Current) || !value.Equals(c.Proposed, e.Proposed) {
+			t.Errorf("expected conflict %#v, got %#v", e, c)
+		}
+	}
+}
+
+func TestConflictError(t *testing.T) {
+	conflicts := merge.Conflicts{{
+		Manager:  "Bob",
+		Path:     _P("replicas"),
+		Current:  _V(1),
+		Proposed: _V(3),
+	}, {
+		Manager: "Bob",
+		Path:    _P("image"),
+		Current: _V("nginx"),
+	}}
+	wanted := `conflicts with "Bob":
+- .replicas (live value: 1, applied value: 3)
+- .image (live value: "nginx", applied value: <absent>)`
+	if got := conflicts.Error(); got != wanted {
+		t.Errorf("Got %v, wanted %v", got, wanted)
+	}
+	wanted = `conflict with "Bob": .replicas (live value: 1, applied value: 3)`
+	if got := conflicts[0].Error(); got != wanted {
+		t.Errorf("Got %v, wanted %v", got, wanted)
+	}
+}
+
+func TestConflictsJSON(t *testing.T) {
+	conflicts := merge.Conflicts{{
+		Manager:    "Bob",
+		Path:       _P("list", _KBF("name", "a"), "replicas"),
+		APIVersion: "v1",
+		Applied:    true,
+		Current:    _V(1),
+		Proposed:   _V(3),
+	}, {
+		Manager:    "Alice",
+		Path:       _P("image"),
+		APIVersion: "v2",
+		Current:    _V("nginx"),
+	}}
+	got, err := json.Marshal(conflicts)
+	if err != nil {
+		t.Fatalf("failed to marshal conflicts: %v", err)
+	}
+	wanted := `[{"manager":"Bob","path":".list[name=\"a\"].replicas","apiVersion":"v1","operation":"Apply","current":1,"proposed":3},` +
+		`{"manager":"Alice","path":".image","apiVersion":"v2","operation":"Update","current":"nginx"}]`
+	if string(got) != wanted {
+		t.Errorf("Got %s, wanted %v", got, wanted)
+	}
+	if got, err := json.Marshal(merge.Conflicts(nil)); err != nil || string(got) != "[]" {
+		t.Errorf("Got %s (%v), wanted []", got, err)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge
+
+import (
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+)
+
+// ForcePolicy selects the conflicts that an apply forces, i.e. the
+// conflicting fields that the applier takes from their managers. A
+// conflict is forced if its field is selected by Fields or FieldMatcher,
+// and its manager by Managers or ManagerPrefixes. When neither Fields nor
+// FieldMatcher are set, all the fields are selected, and likewise for the
+// managers, so that an empty ForcePolicy forces all the conflicts.
+//
+// The paths of the conflicts are at the versions of their managers, so
+// the fields should be given at these versions.
+type ForcePolicy struct {
+	// Fields selects the conflicting fields that are in the set, and
+	// their children.
+	Fields *fieldpath.Set
+	// FieldMatcher selects the conflicting fields that it matches.
+	FieldMatcher *fieldpath.SetMatcher
+	// Managers selects the conflicts with the managers of these names.
+	Managers []string
+	// ManagerPrefixes selects the conflicts with the managers whose name
+	// starts with one of these prefixes.
+	ManagerPrefixes []string
+}
+
+// forceAll is the policy of the applies that are forced.
+var forceAll = &ForcePolicy{}
+
+// forced returns the fields of conflictSet, the conflicts with manager,
+// that are forced. A nil policy forces nothing.
+func (p *ForcePolicy) forced(manager string, conflictSet *fieldpath.Set) *fieldpath.Set {
+	if p == nil || !p.selectsManager(manager) {
+		return fieldpath.NewSet()
+	}
+	if p.Fields == nil && p.FieldMatcher == nil {
+		return conflictSet
+	}
+	forced := fieldpath.NewSet()
+	if p.Fields != nil {
+		forced = forced.Union(conflictSet.Difference(conflictSet.RecursiveDifference(p.Fields)))
+	}
+	if p.FieldMatcher != nil {
+		forced = forced.Union(conflictSet.FilterIncludeMatches(p.FieldMatcher))
+	}
+	return forced
+}
+
+func (p *ForcePolicy) selectsManager(manager string) bool {
+	if len(p.Managers) == 0 && len(p.ManagerPrefixes) == 0 {
+		return true
+	}
+	for _, m := range p.Managers {
+		if m == manager {
+			return true
+		}
+	}
+	for _, prefix := range p.ManagerPrefixes {
+		if strings.HasPrefix(manager, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	. "sigs.k8s.io/structured-merge-diff/v6/internal/fixture"
+	"sigs.k8s.io/structured-merge-diff/v6/merge"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+var forceParser = func() Parser {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: a
+      type:
+        scalar: string
+    - name: b
+      type:
+        scalar: string
+    - name: c
+      type:
+        scalar: string
+    - name: items
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["name"]
+- name: item
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+    - name: value
+      type:
+        scalar: string
+`)
+	if err != nil {
+		panic(err)
+	}
+	return SameVersionParser{T: parser.Type("root")}
+}()
+
+// forcePolicyOps returns the operations of a deployer applying a, b and c
+// with force, over three managers that own them.
+func forcePolicyOps(force *merge.ForcePolicy, conflicts merge.Conflicts) []Operation {
+	return []Operation{
+		Update{
+			Manager:    "autoscaler",
+			APIVersion: "v1",
+			Object:     `{"a": "1"}`,
+		},
+		Update{
+			Manager:    "controller-b",
+			APIVersion: "v1",
+			Object:     `{"a": "1", "b": "1"}`,
+		},
+		Update{
+			Manager:    "controller-c",
+			APIVersion: "v1",
+			Object:     `{"a": "1", "b": "1", "c": "1"}`,
+		},
+		ApplyWithForcePolicy{
+			Manager:    "deployer",
+			APIVersion: "v1",
+			Object:     `{"a": "2", "b": "2", "c": "2"}`,
+			Force:      force,
+			Conflicts:  conflicts,
+		},
+	}
+}
+
+func TestApplyWithForcePolicy(t *testing.T) {
+	tests := map[string]TestCase{
+		"no_policy": {
+			Ops: forcePolicyOps(nil, merge.Conflicts{
+				{Manager: "autoscaler", Path: _P("a")},
+				{Manager: "controller-b", Path: _P("b")},
+				{Manager: "controller-c", Path: _P("c")},
+			}),
+		},
+		"empty_policy": {
+			Ops: forcePolicyOps(&merge.ForcePolicy{}, nil),
+			Managed: fieldpath.ManagedFields{
+				"deployer": fieldpath.NewVersionedSet(_NS(_P("a"), _P("b"), _P("c")), "v1", true),
+			},
+		},
+		"fields": {
+			Ops: forcePolicyOps(&merge.ForcePolicy{Fields: _NS(_P("a"))}, merge.Conflicts{
+				{Manager: "controller-b", Path: _P("b")},
+				{Manager: "controller-c", Path: _P("c")},
+			}),
+		},
+		"field_matcher": {
+			Ops: forcePolicyOps(&merge.ForcePolicy{FieldMatcher: fieldpath.MakePrefixMatcherOrDie("b")}, merge.Conflicts{
+				{Manager: "autoscaler", Path: _P("a")},
+				{Manager: "controller-c", Path: _P("c")},
+			}),
+		},
+		"managers": {
+			Ops: forcePolicyOps(&merge.ForcePolicy{Managers: []string{"autoscaler", "controller-b"}}, merge.Conflicts{
+				{Manager: "controller-c", Path: _P("c")},
+			}),
+		},
+		"manager_prefixes": {
+			Ops: forcePolicyOps(&merge.ForcePolicy{ManagerPrefixes: []string{"controller-"}}, merge.Conflicts{
+				{Manager: "autoscaler", Path: _P("a")},
+			}),
+		},
+		"fields_and_managers": {
+			Ops: forcePolicyOps(&merge.ForcePolicy{
+				Fields:   _NS(_P("a"), _P("b")),
+				Managers: []string{"autoscaler", "controller-c"},
+			}, merge.Conflicts{
+				{Manager: "controller-b", Path: _P("b")},
+				{Manager: "controller-c", Path: _P("c")},
+			}),
+		},
+		"only_the_selected_conflicts": {
+			Ops: forcePolicyOps(&merge.ForcePolicy{
+				Fields:          _NS(_P("a"), _P("c")),
+				FieldMatcher:    fieldpath.MakePrefixMatcherOrDie("b"),
+				ManagerPrefixes: []string{"auto", "controller-"},
+			}, nil),
+			Managed: fieldpath.ManagedFields{
+				"deployer": fieldpath.NewVersionedSet(_NS(_P("a"), _P("b"), _P("c")), "v1", true),
+			},
+		},
+		"patterns": {
+			Ops: []Operation{
+				Update{
+					Manager:    "autoscaler",
+					APIVersion: "v1",
+					Object:     `{"items": [{"name": "n", "value": "1"}, {"name": "m", "value": "1"}, {"name": "o", "value": "1"}]}`,
+				},
+				// Each pattern forces the value of one item.
+				ApplyWithForcePolicy{
+					Manager:    "deployer",
+					APIVersion: "v1",
+					Object:     `{"items": [{"name": "n", "value": "2"}, {"name": "m", "value": "2"}, {"name": "o", "value": "2"}]}`,
+					Force:      &merge.ForcePolicy{FieldMatcher: fieldpath.ParseSetMatcherOrDie(`items[name="n"].value`, `items[name="o"].value`)},
+					Conflicts:  merge.Conflicts{{Manager: "autoscaler", Path: _P("items", _KBF("name", "m"), "value")}},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.Test(forceParser); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
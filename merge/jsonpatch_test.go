@@ -0,0 +1,169 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	. "sigs.k8s.io/structured-merge-diff/v6/internal/fixture"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+var jsonPatchParser = func() Parser {
+	parser, err := typed.NewParser(`types:
+- name: type
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+    - name: items
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["key"]
+- name: item
+  map:
+    fields:
+    - name: key
+      type:
+        scalar: string
+    - name: value
+      type:
+        scalar: numeric
+`)
+	if err != nil {
+		panic(err)
+	}
+	return SameVersionParser{T: parser.Type("type")}
+}()
+
+func TestUpdateJSONPatch(t *testing.T) {
+	tests := map[string]TestCase{
+		"unchanged_fields_are_shared": {
+			Ops: []Operation{
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						name: a
+						items:
+						- key: a
+						  value: 1
+					`,
+				},
+				UpdateJSONPatch{
+					Manager:    "patcher",
+					APIVersion: "v1",
+					Patch:      `[{"op": "replace", "path": "/name", "value": "a"}, {"op": "test", "path": "/items/0/value", "value": 1}]`,
+				},
+			},
+			Object: `
+				name: a
+				items:
+				- key: a
+				  value: 1
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"controller": fieldpath.NewVersionedSet(
+					_NS(
+						_P("name"),
+						_P("items"),
+						_P("items", _KBF("key", "a")),
+						_P("items", _KBF("key", "a"), "key"),
+						_P("items", _KBF("key", "a"), "value"),
+					),
+					"v1",
+					false,
+				),
+				"patcher": fieldpath.NewVersionedSet(
+					_NS(
+						_P("name"),
+					),
+					"v1",
+					false,
+				),
+			},
+		},
+		"changed_fields_are_taken": {
+			Ops: []Operation{
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						name: a
+						items:
+						- key: a
+						  value: 1
+						- key: b
+						  value: 2
+					`,
+				},
+				UpdateJSONPatch{
+					Manager:    "patcher",
+					APIVersion: "v1",
+					Patch:      `[{"op": "remove", "path": "/items/1"}, {"op": "add", "path": "/items/0", "value": {"key": "c", "value": 3}}, {"op": "replace", "path": "/items/1/value", "value": 10}]`,
+				},
+			},
+			// The numbers of JSON Patches are decoded as floats, like
+			// those of value.FromJSON.
+			Object: `
+				name: a
+				items:
+				- key: c
+				  value: 3.0
+				- key: a
+				  value: 10.0
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"controller": fieldpath.NewVersionedSet(
+					_NS(
+						_P("name"),
+						_P("items"),
+						_P("items", _KBF("key", "a")),
+						_P("items", _KBF("key", "a"), "key"),
+					),
+					"v1",
+					false,
+				),
+				"patcher": fieldpath.NewVersionedSet(
+					_NS(
+						_P("items", _KBF("key", "a"), "value"),
+						_P("items", _KBF("key", "c")),
+						_P("items", _KBF("key", "c"), "key"),
+						_P("items", _KBF("key", "c"), "value"),
+					),
+					"v1",
+					false,
+				),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.Test(jsonPatchParser); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+// Decision is what an apply decided for a field.
+type Decision string
+
+const (
+	// DecisionOwned is for the fields of the applied configuration that
+	// the manager didn't own after its last apply.
+	DecisionOwned = Decision("Owned")
+	// DecisionShared is for the fields of the applied configuration that
+	// other managers own too, with the same value.
+	DecisionShared = Decision("Shared")
+	// DecisionConflict is for the fields of the applied configuration
+	// whose value is owned by other managers, and changed. Unless the
+	// apply is forced, these fail the apply.
+	DecisionConflict = Decision("Conflict")
+	// DecisionPruned is for the fields that were removed from the object,
+	// because they were in the last applied configuration, but aren't in
+	// the new one.
+	DecisionPruned = Decision("Pruned")
+	// DecisionRestoredOwned is for the fields that would have been pruned,
+	// but were kept because they are owned, by other managers or by the
+	// new applied configuration.
+	DecisionRestoredOwned = Decision("RestoredOwned")
+	// DecisionRestoredDangling is for the fields that would have been
+	// pruned, but were kept because they weren't in the last applied
+	// configuration, e.g. the fields of a removed item that no manager
+	// owns.
+	DecisionRestoredDangling = Decision("RestoredDangling")
+)
+
+// decisionOrder is the order of the decisions of the same path.
+var decisionOrder = map[Decision]int{
+	DecisionOwned:            0,
+	DecisionShared:           1,
+	DecisionConflict:         2,
+	DecisionPruned:           3,
+	DecisionRestoredOwned:    4,
+	DecisionRestoredDangling: 5,
+}
+
+// PathDecision is a decision that an apply made for a field.
+type PathDecision struct {
+	Path     fieldpath.Path
+	Decision Decision
+	// Managers are the other managers involved in the decision, e.g.
+	// the managers that the field is shared or conflicting with, sorted.
+	Managers []string
+	// Reason explains the decision.
+	Reason string
+}
+
+// String formats the decision on a single line.
+func (d PathDecision) String() string {
+	s := fmt.Sprintf("%v: %v", d.Path, d.Decision)
+	if len(d.Managers) > 0 {
+		s += fmt.Sprintf(" %q", d.Managers)
+	}
+	return s + " (" + d.Reason + ")"
+}
+
+// ApplyReport explains the decisions of an apply, field by field. The
+// fields are at the version of the apply, except for the conflicting
+// fields, that are at the version of the managers they conflict with,
+// and for the pruned and restored fields, that are at the version of the
+// last apply of the manager.
+type ApplyReport struct {
+	Manager string
+	Forced  bool
+	// Decisions are sorted by path, and a path can have several
+	// decisions, e.g. a field that is both newly owned and conflicting.
+	Decisions []PathDecision
+}
+
+// Paths returns the paths with the given decision.
+func (r *ApplyReport) Paths(decision Decision) *fieldpath.Set {
+	set := fieldpath.NewSet()
+	for _, d := range r.Decisions {
+		if d.Decision == decision {
+			set.Insert(d.Path)
+		}
+	}
+	return set
+}
+
+// String formats the report with one decision per line.
+func (r *ApplyReport) String() string {
+	lines := make([]string, 0, len(r.Decisions))
+	for _, d := range r.Decisions {
+		lines = append(lines, d.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reporter builds an ApplyReport along an apply. A nil reporter reports
+// nothing, so that applies that aren't explained don't pay for it.
+type reporter struct {
+	report *ApplyReport
+	// decisions are indexed by path and decision, to merge the managers
+	// of the same decision.
+	decisions map[string]*PathDecision
+	// shared has the fields of the applied configuration that other
+	// managers own, until the conflicting ones are known.
+	shared map[string]*fieldpath.Set
+}
+
+func newReporter(manager string, force bool) *reporter {
+	return &reporter{
+		report:    &ApplyReport{Manager: manager, Forced: force},
+		decisions: map[string]*PathDecision{},
+	}
+}
+
+func (r *reporter) add(p fieldpath.Path, decision Decision, manager, reason string) {
+	key := p.String() + " " + string(decision)
+	d, ok := r.decisions[key]
+	if !ok {
+		d = &PathDecision{Path: p.Copy(), Decision: decision, Reason: reason}
+		r.decisions[key] = d
+	}
+	if manager != "" {
+		d.Managers = append(d.Managers, manager)
+	}
+}
+
+// applied records the fields of the applied configuration, set, that the
+// manager didn't apply last time, and the ones that other managers own at
+// the same version.
+func (r *reporter) applied(set *fieldpath.Set, version fieldpath.APIVersion, lastSet fieldpath.VersionedSet, managers fieldpath.ManagedFields, manager string) {
+	if r == nil {
+		return
+	}
+	owned := set
+	if lastSet != nil && lastSet.APIVersion() == version {
+		owned = set.Difference(lastSet.Set())
+	}
+	owned.Iterate(func(p fieldpath.Path) {
+		r.add(p, DecisionOwned, "", "not applied by the manager before")
+	})
+	r.shared = map[string]*fieldpath.Set{}
+	for other, otherSet := range managers {
+		if other == manager || otherSet.APIVersion() != version {
+			continue
+		}
+		if shared := set.Intersection(otherSet.Set()); !shared.Empty() {
+			r.shared[other] = shared
+		}
+	}
+}
+
+// conflicts records the conflicts with other managers, and which of them
+// are forced.
+func (r *reporter) conflicts(conflicts, forced fieldpath.ManagedFields) {
+	if r == nil {
+		return
+	}
+	for manager, conflictSet := range conflicts {
+		forcedSet := fieldpath.NewSet()
+		if set, ok := forced[manager]; ok {
+			forcedSet = set.Set()
+		}
+		conflictSet.Set().Iterate(func(p fieldpath.Path) {
+			reason := "changed while owned by other managers"
+			if forcedSet.Has(p) {
+				reason += ", who no longer own it"
+			}
+			r.add(p, DecisionConflict, manager, reason)
+		})
+		// The shared fields are only of the managers at the version of
+		// the apply, and so at the version of their conflicts.
+		if shared, ok := r.shared[manager]; ok {
+			r.shared[manager] = shared.Difference(conflictSet.Set())
+		}
+	}
+}
+
+// pruned records the fields of merged, at the version of lastSet, that
+// prune removed, along with the managers that owned them, and the ones
+// that it added back because they were owned (in restoredOwned) or
+// dangling (in final but not in restoredOwned).
+func (r *reporter) pruned(merged, removed, restoredOwned, final *typed.TypedValue, managers fieldpath.ManagedFields, lastSet fieldpath.VersionedSet) error {
+	if r == nil {
+		return nil
+	}
+	version := lastSet.APIVersion()
+	fields := func(tv *typed.TypedValue) (*fieldpath.Set, error) {
+		set, err := tv.ToFieldSet()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create field set: %v", err)
+		}
+		return set.EnsureNamedFieldsAreMembers(merged.Schema(), merged.TypeRef()), nil
+	}
+	mergedSet, err := fields(merged)
+	if err != nil {
+		return err
+	}
+	removedSet, err := fields(removed)
+	if err != nil {
+		return err
+	}
+	ownedSet, err := fields(restoredOwned)
+	if err != nil {
+		return err
+	}
+	finalSet, err := fields(final)
+	if err != nil {
+		return err
+	}
+
+	last := lastSet.Set().EnsureNamedFieldsAreMembers(merged.Schema(), merged.TypeRef())
+	mergedSet.Difference(finalSet).Iterate(func(p fieldpath.Path) {
+		reason := "removed along with a pruned parent"
+		if last.Has(p) {
+			reason = "in the last applied configuration of the manager, but not in the new one"
+		}
+		// The managers of the same version that owned the field lose it.
+		owners := 0
+		for manager, managerSet := range managers {
+			if manager != r.report.Manager && managerSet.APIVersion() == version && managerSet.Set().Has(p) {
+				r.add(p, DecisionPruned, manager, reason)
+				owners++
+			}
+		}
+		if owners == 0 {
+			r.add(p, DecisionPruned, "", reason)
+		}
+	})
+	ownedSet.Difference(removedSet).Iterate(func(p fieldpath.Path) {
+		owners := []string{}
+		for manager, managerSet := range managers {
+			if managerSet.APIVersion() == version && owns(managerSet.Set(), p) {
+				owners = append(owners, manager)
+			}
+		}
+		if len(owners) == 0 {
+			r.add(p, DecisionRestoredOwned, "", "owned by managers of other versions")
+			return
+		}
+		for _, owner := range owners {
+			r.add(p, DecisionRestoredOwned, owner, "owned by the managers, or by the new applied configuration")
+		}
+	})
+	finalSet.Difference(ownedSet).Iterate(func(p fieldpath.Path) {
+		r.add(p, DecisionRestoredDangling, "", "not in the last applied configuration of the manager")
+	})
+	return nil
+}
+
+// owns returns true if set has p, a prefix of p (e.g. an atomic parent) or
+// a path that starts with p (a child of p).
+func owns(set *fieldpath.Set, p fieldpath.Path) bool {
+	for i := len(p); i > 0; i-- {
+		if set.Has(p[:i]) {
+			return true
+		}
+	}
+	for _, pe := range p {
+		set = set.WithPrefix(pe)
+	}
+	return !set.Empty()
+}
+
+// finish returns the report, with the shared fields that don't conflict,
+// and the decisions sorted.
+func (r *reporter) finish() *ApplyReport {
+	for manager, shared := range r.shared {
+		shared.Iterate(func(p fieldpath.Path) {
+			r.add(p, DecisionShared, manager, "applied with the same value as the other managers")
+		})
+	}
+	for _, d := range r.decisions {
+		sort.Strings(d.Managers)
+		r.report.Decisions = append(r.report.Decisions, *d)
+	}
+	sort.Slice(r.report.Decisions, func(i, j int) bool {
+		lhs, rhs := r.report.Decisions[i], r.report.Decisions[j]
+		if c := lhs.Path.Compare(rhs.Path); c != 0 {
+			return c < 0
+		}
+		return decisionOrder[lhs.Decision] < decisionOrder[rhs.Decision]
+	})
+	return r.report
+}
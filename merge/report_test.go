@@ -0,0 +1,195 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	. "sigs.k8s.io/structured-merge-diff/v6/internal/fixture"
+	"sigs.k8s.io/structured-merge-diff/v6/merge"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+var reportParser = func() Parser {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: a
+      type:
+        scalar: string
+    - name: b
+      type:
+        scalar: string
+    - name: c
+      type:
+        scalar: string
+    - name: list
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: associative
+    - name: items
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["name"]
+- name: item
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+    - name: value
+      type:
+        scalar: string
+`)
+	if err != nil {
+		panic(err)
+	}
+	return SameVersionParser{T: parser.Type("root")}
+}()
+
+func TestApplyWithReport(t *testing.T) {
+	tests := map[string]TestCase{
+		"conflict": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "2"}`,
+				},
+				ApplyWithReport{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "3", "c": "4"}`,
+					Conflicts:  merge.Conflicts{{Manager: "default", Path: _P("b")}},
+					Report: `
+						.a: Owned (not applied by the manager before)
+						.a: Shared ["default"] (applied with the same value as the other managers)
+						.b: Owned (not applied by the manager before)
+						.b: Conflict ["default"] (changed while owned by other managers)
+						.c: Owned (not applied by the manager before)
+					`,
+				},
+			},
+		},
+		"forced_conflict": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "2"}`,
+				},
+				ApplyWithReport{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "3", "c": "4"}`,
+					Force:      true,
+					Report: `
+						.a: Owned (not applied by the manager before)
+						.a: Shared ["default"] (applied with the same value as the other managers)
+						.b: Owned (not applied by the manager before)
+						.b: Conflict ["default"] (changed while owned by other managers, who no longer own it)
+						.c: Owned (not applied by the manager before)
+					`,
+				},
+			},
+			Managed: fieldpath.ManagedFields{
+				"default": fieldpath.NewVersionedSet(_NS(_P("a")), "v1", true),
+				"other":   fieldpath.NewVersionedSet(_NS(_P("a"), _P("b"), _P("c")), "v1", true),
+			},
+		},
+		"prune": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "2", "list": ["x", "y"]}`,
+				},
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object:     `{"list": ["y"]}`,
+				},
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "2", "list": ["x", "y"], "items": [{"name": "n", "value": "v"}]}`,
+				},
+				ApplyWithReport{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object:     `{"b": "2"}`,
+					Report: `
+						.a: Pruned (in the last applied configuration of the manager, but not in the new one)
+						.b: RestoredOwned ["other"] (owned by the managers, or by the new applied configuration)
+						.list: RestoredOwned ["default"] (owned by the managers, or by the new applied configuration)
+						.list[="x"]: Pruned (in the last applied configuration of the manager, but not in the new one)
+						.list[="y"]: RestoredOwned ["default"] (owned by the managers, or by the new applied configuration)
+					`,
+				},
+			},
+			Managed: fieldpath.ManagedFields{
+				"default":    fieldpath.NewVersionedSet(_NS(_P("list", _V("y"))), "v1", true),
+				"controller": fieldpath.NewVersionedSet(_NS(_P("items"), _P("items", _KBF("name", "n")), _P("items", _KBF("name", "n"), "name"), _P("items", _KBF("name", "n"), "value")), "v1", false),
+				"other":      fieldpath.NewVersionedSet(_NS(_P("b")), "v1", true),
+			},
+		},
+		"prune_with_owners": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object:     `{"items": [{"name": "n"}]}`,
+				},
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object:     `{"items": [{"name": "n", "value": "v"}]}`,
+				},
+				ApplyWithReport{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object:     `{"a": "1"}`,
+					Report: `
+						.a: Owned (not applied by the manager before)
+						.items: RestoredOwned ["controller"] (owned by the managers, or by the new applied configuration)
+						.items[name="n"]: Pruned (in the last applied configuration of the manager, but not in the new one)
+						.items[name="n"].name: Pruned (in the last applied configuration of the manager, but not in the new one)
+						.items[name="n"].value: Pruned ["controller"] (removed along with a pruned parent)
+					`,
+				},
+			},
+			Managed: fieldpath.ManagedFields{
+				"other": fieldpath.NewVersionedSet(_NS(_P("a")), "v1", true),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.Test(reportParser); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	. "sigs.k8s.io/structured-merge-diff/v6/internal/fixture"
+	"sigs.k8s.io/structured-merge-diff/v6/merge"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+var requiredParser = func() Parser {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: spec
+      type:
+        namedType: spec
+- name: spec
+  map:
+    fields:
+    - name: image
+      type:
+        scalar: string
+    - name: replicas
+      type:
+        scalar: integer
+    - name: args
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+        constraints:
+          minItems: 1
+  constraints:
+    required: ["image", "args"]
+`)
+	if err != nil {
+		panic(err)
+	}
+	return SameVersionParser{T: parser.Type("root")}
+}()
+
+// Apply configurations are partial objects, that don't have to satisfy
+// the constraints of complete objects.
+func TestApplyPartialRequired(t *testing.T) {
+	state := State{
+		Updater: &merge.Updater{Converter: &specificVersionConverter{AcceptedVersions: []fieldpath.APIVersion{"v1"}}},
+		Parser:  requiredParser,
+	}
+	if err := state.Apply(`{"spec": {"image": "nginx", "args": ["run"]}}`, "v1", "deployer", false); err != nil {
+		t.Fatalf("failed to apply: %v", err)
+	}
+	if err := state.Apply(`{"spec": {"replicas": 3}}`, "v1", "autoscaler", false); err != nil {
+		t.Fatalf("failed to apply a partial object: %v", err)
+	}
+	if err := state.Apply(`{"spec": {"args": []}}`, "v1", "other", true); err != nil {
+		t.Fatalf("failed to apply a partial object: %v", err)
+	}
+	if err := state.Apply(`{"spec": {"image": "nginx", "replicas": 3}}`, "v1", "autoscaler", true); err != nil {
+		t.Fatalf("failed to apply a partial object: %v", err)
+	}
+	if err := state.Live.Validate(typed.RequireComplete); err == nil {
+		t.Errorf("expected the live object to miss the items of args")
+	}
+	if diff, err := state.CompareLive(`{"spec": {"image": "nginx", "replicas": 3, "args": []}}`, "v1"); err != nil {
+		t.Fatalf("failed to compare live object: %v", err)
+	} else if diff != "" {
+		t.Errorf("unexpected live object (-expected +got):\n%v", diff)
+	}
+}
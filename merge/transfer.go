@@ -0,0 +1,130 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+// TransferOwnership moves the ownership of the fields of set, which are at
+// version, and of their children, from the manager from to the manager
+// to, without changing liveObject. This is e.g. how the fields of a
+// client-side applier can be given to a server-side applier.
+//
+// The fields that to receives are at its version, and it stays an applier
+// or an updater. If to doesn't manage any field yet, it gets the version
+// and the applied flag of from. The managers are returned as a copy, and
+// from is removed from them if it no longer owns any field.
+//
+// When the versions differ, the fields are converted by converting the
+// parts of liveObject that they own, so only the fields of liveObject can
+// be transferred, and the keys of the list items are transferred along
+// with the fields of the items. The fields that from owns and that aren't
+// transferred are kept as they are, even if they aren't in liveObject.
+func (s *Updater) TransferOwnership(liveObject *typed.TypedValue, managers fieldpath.ManagedFields, from, to string, set *fieldpath.Set, version fieldpath.APIVersion) (fieldpath.ManagedFields, error) {
+	managers = managers.Copy()
+	fromSet, ok := managers[from]
+	if !ok || from == to {
+		return managers, nil
+	}
+	owned, err := s.convertFieldSet(liveObject, fromSet.Set(), fromSet.APIVersion(), version)
+	if err != nil {
+		return nil, err
+	}
+	moved := owned.Difference(owned.RecursiveDifference(set))
+	if moved.Empty() {
+		return managers, nil
+	}
+	// Only the moved fields are removed from from. Converting them back
+	// can add the parents and the keys that they share with the kept
+	// fields, which are kept too.
+	movedBack, err := s.convertFieldSet(liveObject, moved, version, fromSet.APIVersion())
+	if err != nil {
+		return nil, err
+	}
+	keptBack, err := s.convertFieldSet(liveObject, owned.Difference(moved), version, fromSet.APIVersion())
+	if err != nil {
+		return nil, err
+	}
+	remaining := fromSet.Set().Difference(movedBack.Difference(keptBack))
+	if err := s.addOwnership(liveObject, managers, to, moved, version, fromSet); err != nil {
+		return nil, err
+	}
+	managers[from] = fieldpath.NewVersionedSet(remaining, fromSet.APIVersion(), fromSet.Applied())
+	if remaining.Empty() {
+		delete(managers, from)
+	}
+	return managers, nil
+}
+
+// TransferAllOwnership moves the ownership of all the fields of the
+// manager from to the manager to, and removes from, like
+// TransferOwnership.
+func (s *Updater) TransferAllOwnership(liveObject *typed.TypedValue, managers fieldpath.ManagedFields, from, to string) (fieldpath.ManagedFields, error) {
+	managers = managers.Copy()
+	fromSet, ok := managers[from]
+	if !ok || from == to {
+		return managers, nil
+	}
+	if err := s.addOwnership(liveObject, managers, to, fromSet.Set(), fromSet.APIVersion(), fromSet); err != nil {
+		return nil, err
+	}
+	delete(managers, from)
+	return managers, nil
+}
+
+// addOwnership adds the fields of set, at version, to the manager to,
+// which gets the version and the applied flag of fromSet if it doesn't
+// exist.
+func (s *Updater) addOwnership(liveObject *typed.TypedValue, managers fieldpath.ManagedFields, to string, set *fieldpath.Set, version fieldpath.APIVersion, fromSet fieldpath.VersionedSet) error {
+	toSet, ok := managers[to]
+	if !ok {
+		toSet = fieldpath.NewVersionedSet(fieldpath.NewSet(), fromSet.APIVersion(), fromSet.Applied())
+	}
+	converted, err := s.convertFieldSet(liveObject, set, version, toSet.APIVersion())
+	if err != nil {
+		return err
+	}
+	managers[to] = fieldpath.NewVersionedSet(toSet.Set().Union(converted), toSet.APIVersion(), toSet.Applied())
+	return nil
+}
+
+// convertFieldSet converts set, the fields of liveObject at version from,
+// to version to.
+func (s *Updater) convertFieldSet(liveObject *typed.TypedValue, set *fieldpath.Set, from, to fieldpath.APIVersion) (*fieldpath.Set, error) {
+	if from == to || set.Empty() {
+		return set, nil
+	}
+	object, err := s.Converter.Convert(liveObject, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert live object to version %v: %v", from, err)
+	}
+	// Extracting the non-leaf fields would extract all their children.
+	object = object.ExtractItems(set.Leaves(), typed.WithAppendKeyFields())
+	object, err = s.Converter.Convert(object, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert owned fields to version %v: %v", to, err)
+	}
+	converted, err := object.ToFieldSet()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get field set: %v", err)
+	}
+	return converted, nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	. "sigs.k8s.io/structured-merge-diff/v6/internal/fixture"
+	"sigs.k8s.io/structured-merge-diff/v6/merge"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+var transferParser = func() Parser {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: a
+      type:
+        scalar: string
+    - name: b
+      type:
+        scalar: string
+    - name: c
+      type:
+        scalar: string
+    - name: items
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["name"]
+- name: item
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+    - name: value
+      type:
+        scalar: string
+`)
+	if err != nil {
+		panic(err)
+	}
+	return SameVersionParser{T: parser.Type("root")}
+}()
+
+func TestTransferOwnership(t *testing.T) {
+	tests := map[string]TestCase{
+		"fields": {
+			Ops: []Operation{
+				Update{
+					Manager:    "kubectl-client-side-apply",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "1", "items": [{"name": "n", "value": "1"}]}`,
+				},
+				Apply{
+					Manager:    "kubectl",
+					APIVersion: "v1",
+					Object:     `{"c": "1"}`,
+				},
+				TransferOwnership{
+					From:       "kubectl-client-side-apply",
+					To:         "kubectl",
+					Fields:     _NS(_P("a"), _P("items")),
+					APIVersion: "v1",
+				},
+			},
+			Managed: fieldpath.ManagedFields{
+				"kubectl-client-side-apply": fieldpath.NewVersionedSet(_NS(_P("b")), "v1", false),
+				"kubectl": fieldpath.NewVersionedSet(_NS(
+					_P("a"),
+					_P("c"),
+					_P("items"),
+					_P("items", _KBF("name", "n")),
+					_P("items", _KBF("name", "n"), "name"),
+					_P("items", _KBF("name", "n"), "value"),
+				), "v1", true),
+			},
+		},
+		"all_fields": {
+			Ops: []Operation{
+				Update{
+					Manager:    "kubectl-client-side-apply",
+					APIVersion: "v1",
+					Object:     `{"a": "1"}`,
+				},
+				Apply{
+					Manager:    "kubectl",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "1"}`,
+				},
+				TransferAllOwnership{
+					From: "kubectl-client-side-apply",
+					To:   "kubectl",
+				},
+			},
+			Managed: fieldpath.ManagedFields{
+				"kubectl": fieldpath.NewVersionedSet(_NS(_P("a"), _P("b")), "v1", true),
+			},
+		},
+		"new_manager": {
+			Ops: []Operation{
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "1"}`,
+				},
+				TransferOwnership{
+					From:       "controller",
+					To:         "new-controller",
+					Fields:     _NS(_P("b"), _P("c")),
+					APIVersion: "v1",
+				},
+			},
+			Managed: fieldpath.ManagedFields{
+				"controller":     fieldpath.NewVersionedSet(_NS(_P("a")), "v1", false),
+				"new-controller": fieldpath.NewVersionedSet(_NS(_P("b")), "v1", false),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.Test(transferParser); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestTransferOwnershipVersions(t *testing.T) {
+	tests := map[string]TestCase{
+		"fields": {
+			Ops: []Operation{
+				Update{
+					Manager:    "kubectl-client-side-apply",
+					APIVersion: "v1",
+					Object:     `{"struct": {"name": "a", "scalarField_v1": "a"}}`,
+				},
+				Apply{
+					Manager:    "kubectl",
+					APIVersion: "v2",
+					Object:     `{"version": "v2"}`,
+				},
+				TransferOwnership{
+					From:       "kubectl-client-side-apply",
+					To:         "kubectl",
+					Fields:     _NS(_P("struct", "scalarField_v3")),
+					APIVersion: "v3",
+				},
+			},
+			Managed: fieldpath.ManagedFields{
+				"kubectl-client-side-apply": fieldpath.NewVersionedSet(_NS(_P("struct"), _P("struct", "name")), "v1", false),
+				"kubectl":                   fieldpath.NewVersionedSet(_NS(_P("version"), _P("struct", "scalarField_v2")), "v2", true),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.TestWithConverter(structMultiversionParser, renamingConverter{structMultiversionParser}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// The fields that aren't transferred are kept, even if they aren't in the
+// live object and can't be converted.
+func TestTransferOwnershipKeepsFieldsNotInLiveObject(t *testing.T) {
+	live, err := structMultiversionParser.Type("v1").FromYAML(`{"struct": {"name": "a", "scalarField_v1": "a"}}`)
+	if err != nil {
+		t.Fatalf("failed to parse live object: %v", err)
+	}
+	managers := fieldpath.ManagedFields{
+		"controller": fieldpath.NewVersionedSet(_NS(
+			_P("struct", "name"),
+			_P("struct", "scalarField_v1"),
+			_P("struct", "complexField_v1", "name"),
+		), "v1", false),
+	}
+	updater := &merge.Updater{Converter: renamingConverter{structMultiversionParser}}
+	got, err := updater.TransferOwnership(live, managers, "controller", "new-controller", _NS(_P("struct", "scalarField_v2")), "v2")
+	if err != nil {
+		t.Fatalf("failed to transfer ownership: %v", err)
+	}
+	expected := fieldpath.ManagedFields{
+		"controller":     fieldpath.NewVersionedSet(_NS(_P("struct", "name"), _P("struct", "complexField_v1", "name")), "v1", false),
+		"new-controller": fieldpath.NewVersionedSet(_NS(_P("struct", "scalarField_v1")), "v1", false),
+	}
+	if !got.Equals(expected) {
+		t.Errorf("expected managers:\n%v\ngot:\n%v", expected, got)
+	}
+}
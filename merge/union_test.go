@@ -0,0 +1,264 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	. "sigs.k8s.io/structured-merge-diff/v6/internal/fixture"
+	"sigs.k8s.io/structured-merge-diff/v6/merge"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+var unionFieldsParser = func() Parser {
+	parser, err := typed.NewParser(`types:
+- name: unionFields
+  map:
+    fields:
+    - name: numeric
+      type:
+        scalar: numeric
+    - name: discriminator
+      type:
+        scalar: string
+    - name: one
+      type:
+        scalar: numeric
+    - name: two
+      type:
+        scalar: numeric
+    - name: letter
+      type:
+        scalar: string
+    - name: a
+      type:
+        scalar: numeric
+    - name: b
+      type:
+        scalar: numeric
+    unions:
+    - discriminator: discriminator
+      deduceInvalidDiscriminator: true
+      fields:
+      - fieldName: one
+        discriminatorValue: One
+      - fieldName: two
+        discriminatorValue: TWO
+    - discriminator: letter
+      fields:
+      - fieldName: a
+        discriminatorValue: A
+      - fieldName: b
+        discriminatorValue: B`)
+	if err != nil {
+		panic(err)
+	}
+	return SameVersionParser{T: parser.Type("unionFields")}
+}()
+
+func TestUnion(t *testing.T) {
+	tests := map[string]TestCase{
+		"union_apply_switch_field": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						numeric: 1
+						one: 1
+					`,
+				},
+				Apply{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object: `
+						two: 2
+					`,
+				},
+			},
+			Object: `
+				numeric: 1
+				discriminator: TWO
+				two: 2
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"default": fieldpath.NewVersionedSet(
+					_NS(
+						_P("numeric"),
+					),
+					"v1",
+					true,
+				),
+				"other": fieldpath.NewVersionedSet(
+					_NS(
+						_P("two"),
+					),
+					"v1",
+					true,
+				),
+			},
+		},
+		"union_update_deduces_discriminator": {
+			Ops: []Operation{
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						one: 1
+					`,
+				},
+			},
+			Object: `
+				discriminator: One
+				one: 1
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"controller": fieldpath.NewVersionedSet(
+					_NS(
+						_P("discriminator"),
+						_P("one"),
+					),
+					"v1",
+					false,
+				),
+			},
+		},
+		"union_update_switch_discriminator": {
+			Ops: []Operation{
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						letter: A
+						a: 1
+					`,
+				},
+				Update{
+					Manager:    "controller",
+					APIVersion: "v1",
+					Object: `
+						letter: B
+						a: 1
+					`,
+				},
+			},
+			Object: `
+				letter: B
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"controller": fieldpath.NewVersionedSet(
+					_NS(
+						_P("letter"),
+					),
+					"v1",
+					false,
+				),
+			},
+		},
+		"union_apply_discriminator_conflict": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						letter: A
+						a: 1
+					`,
+				},
+				Apply{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object: `
+						b: 2
+					`,
+					Conflicts: merge.Conflicts{
+						merge.Conflict{Manager: "default", Path: _P("letter")},
+					},
+				},
+				ForceApply{
+					Manager:    "other",
+					APIVersion: "v1",
+					Object: `
+						letter: B
+					`,
+				},
+			},
+			Object: `
+				letter: B
+			`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"other": fieldpath.NewVersionedSet(
+					_NS(
+						_P("letter"),
+					),
+					"v1",
+					true,
+				),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.Test(unionFieldsParser); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestUnionErrors(t *testing.T) {
+	tests := map[string]TestCase{
+		"union_apply_mismatched_discriminator": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						letter: A
+						b: 1
+					`,
+				},
+			},
+		},
+		"union_apply_two_fields": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "default",
+					APIVersion: "v1",
+					Object: `
+						one: 1
+						two: 2
+					`,
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.Test(unionFieldsParser); err == nil {
+				t.Fatal("Should fail")
+			}
+		})
+	}
+}
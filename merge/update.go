@@ -44,14 +44,30 @@ type UpdaterBuilder struct {
 	// Comparing has become more expensive too now that we're not using
 	// `Compare` but `value.Equals` so this gives an option to avoid it.
 	ReturnInputOnNoop bool
+
+	// CompareOptions are used to compare the objects before and after
+	// each operation, e.g. so that scalar values that are semantically
+	// equal don't change the ownership of their fields.
+	CompareOptions []typed.CompareOption
+
+	// DefaultEquality considers the fields that are absent from one
+	// object, and set to their default in the other, as unchanged. This
+	// way, omitting a defaulted field neither removes it from its managers
+	// nor conflicts with them. See typed.WithDefaultEquality.
+	DefaultEquality bool
 }
 
 func (u *UpdaterBuilder) BuildUpdater() *Updater {
+	compareOptions := u.CompareOptions
+	if u.DefaultEquality {
+		compareOptions = append(append([]typed.CompareOption{}, u.CompareOptions...), typed.WithDefaultEquality())
+	}
 	return &Updater{
 		Converter:         u.Converter,
 		IgnoreFilter:      u.IgnoreFilter,
 		IgnoredFields:     u.IgnoredFields,
 		returnInputOnNoop: u.ReturnInputOnNoop,
+		compareOptions:    compareOptions,
 	}
 }
 
@@ -68,17 +84,21 @@ type Updater struct {
 	IgnoreFilter map[fieldpath.APIVersion]fieldpath.Filter
 
 	returnInputOnNoop bool
+
+	compareOptions []typed.CompareOption
 }
 
-func (s *Updater) update(oldObject, newObject *typed.TypedValue, version fieldpath.APIVersion, managers fieldpath.ManagedFields, workflow string, force bool) (fieldpath.ManagedFields, *typed.Comparison, error) {
+func (s *Updater) update(oldObject, newObject *typed.TypedValue, version fieldpath.APIVersion, managers fieldpath.ManagedFields, workflow string, force *ForcePolicy, r *reporter) (fieldpath.ManagedFields, *typed.Comparison, error) {
 	conflicts := fieldpath.ManagedFields{}
 	removed := fieldpath.ManagedFields{}
-	compare, err := oldObject.Compare(newObject)
+	compare, err := oldObject.Compare(newObject, s.compareOptions...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to compare objects: %v", err)
 	}
 
 	var versions map[fieldpath.APIVersion]*typed.Comparison
+	// objects are the converted objects, for the values of the conflicts.
+	objects := map[fieldpath.APIVersion]versionedObjects{}
 
 	if s.IgnoredFields != nil && s.IgnoreFilter != nil {
 		return nil, nil, fmt.Errorf("IgnoreFilter and IgnoreFilter may not both be set")
@@ -116,10 +136,11 @@ func (s *Updater) update(oldObject, newObject *typed.TypedValue, version fieldpa
 				}
 				return nil, nil, fmt.Errorf("failed to convert new object: %v", err)
 			}
-			compare, err = versionedOldObject.Compare(versionedNewObject)
+			compare, err = versionedOldObject.Compare(versionedNewObject, s.compareOptions...)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to compare objects: %v", err)
 			}
+			objects[managerSet.APIVersion()] = versionedObjects{old: versionedOldObject, new: versionedNewObject}
 
 			if s.IgnoredFields != nil {
 				versions[managerSet.APIVersion()] = compare.ExcludeFields(s.IgnoredFields[managerSet.APIVersion()])
@@ -130,7 +151,7 @@ func (s *Updater) update(oldObject, newObject *typed.TypedValue, version fieldpa
 
 		conflictSet := managerSet.Set().Intersection(compare.Modified.Union(compare.Added))
 		if !conflictSet.Empty() {
-			conflicts[manager] = fieldpath.NewVersionedSet(conflictSet, managerSet.APIVersion(), false)
+			conflicts[manager] = fieldpath.NewVersionedSet(conflictSet, managerSet.APIVersion(), managerSet.Applied())
 		}
 
 		if !compare.Removed.Empty() {
@@ -138,11 +159,25 @@ func (s *Updater) update(oldObject, newObject *typed.TypedValue, version fieldpa
 		}
 	}
 
-	if !force && len(conflicts) != 0 {
-		return nil, nil, ConflictsFromManagers(conflicts)
+	forced := fieldpath.ManagedFields{}
+	unforced := fieldpath.ManagedFields{}
+	for manager, conflictSet := range conflicts {
+		forcedSet := force.forced(manager, conflictSet.Set())
+		if !forcedSet.Empty() {
+			forced[manager] = fieldpath.NewVersionedSet(forcedSet, conflictSet.APIVersion(), conflictSet.Applied())
+		}
+		if unforcedSet := conflictSet.Set().Difference(forcedSet); !unforcedSet.Empty() {
+			unforced[manager] = fieldpath.NewVersionedSet(unforcedSet, conflictSet.APIVersion(), conflictSet.Applied())
+		}
 	}
 
-	for manager, conflictSet := range conflicts {
+	r.conflicts(conflicts, forced)
+	if len(unforced) != 0 {
+		objects[version] = versionedObjects{old: oldObject, new: newObject}
+		return nil, nil, conflictsFromManagers(unforced, objects)
+	}
+
+	for manager, conflictSet := range forced {
 		managers[manager] = fieldpath.NewVersionedSet(managers[manager].Set().Difference(conflictSet.Set()), managers[manager].APIVersion(), managers[manager].Applied())
 	}
 
@@ -165,12 +200,33 @@ func (s *Updater) update(oldObject, newObject *typed.TypedValue, version fieldpa
 // PATCH call), and liveObject must be the original object (empty if
 // this is a CREATE call).
 func (s *Updater) Update(liveObject, newObject *typed.TypedValue, version fieldpath.APIVersion, managers fieldpath.ManagedFields, manager string) (*typed.TypedValue, fieldpath.ManagedFields, error) {
+	return s.updateTouched(liveObject, newObject, version, managers, manager, nil)
+}
+
+// UpdateJSONPatch is like Update for a PATCH call with a JSON Patch: it
+// applies patch to liveObject, and manager gets the ownership of all the
+// fields that the patch touched, even those whose value didn't change.
+func (s *Updater) UpdateJSONPatch(liveObject *typed.TypedValue, patch typed.JSONPatch, version fieldpath.APIVersion, managers fieldpath.ManagedFields, manager string) (*typed.TypedValue, fieldpath.ManagedFields, error) {
+	newObject, touched, err := liveObject.ApplyJSONPatch(patch)
+	if err != nil {
+		return nil, fieldpath.ManagedFields{}, err
+	}
+	return s.updateTouched(liveObject, newObject, version, managers, manager, touched)
+}
+
+// updateTouched implements Update. The fields of touched that are in
+// newObject are owned by manager, in addition to the fields that changed.
+func (s *Updater) updateTouched(liveObject, newObject *typed.TypedValue, version fieldpath.APIVersion, managers fieldpath.ManagedFields, manager string, touched *fieldpath.Set) (*typed.TypedValue, fieldpath.ManagedFields, error) {
 	var err error
 	managers, err = s.reconcileManagedFieldsWithSchemaChanges(liveObject, managers)
 	if err != nil {
 		return nil, fieldpath.ManagedFields{}, err
 	}
-	managers, compare, err := s.update(liveObject, newObject, version, managers, manager, true)
+	newObject, err = liveObject.NormalizeUnions(newObject)
+	if err != nil {
+		return nil, fieldpath.ManagedFields{}, fmt.Errorf("failed to normalize unions: %v", err)
+	}
+	managers, compare, err := s.update(liveObject, newObject, version, managers, manager, forceAll, nil)
 	if err != nil {
 		return nil, fieldpath.ManagedFields{}, err
 	}
@@ -178,6 +234,13 @@ func (s *Updater) Update(liveObject, newObject *typed.TypedValue, version fieldp
 		managers[manager] = fieldpath.NewVersionedSet(fieldpath.NewSet(), version, false)
 	}
 	set := managers[manager].Set().Difference(compare.Removed).Union(compare.Modified).Union(compare.Added)
+	if touched != nil {
+		fields, err := newObject.ToFieldSet()
+		if err != nil {
+			return nil, fieldpath.ManagedFields{}, fmt.Errorf("failed to get field set: %v", err)
+		}
+		set = set.Union(touched.Intersection(fields))
+	}
 
 	if s.IgnoredFields != nil && s.IgnoreFilter != nil {
 		return nil, nil, fmt.Errorf("IgnoreFilter and IgnoreFilter may not both be set")
@@ -207,6 +270,102 @@ func (s *Updater) Update(liveObject, newObject *typed.TypedValue, version fieldp
 // well as the configuration that is applied. This will merge the object
 // and return it.
 func (s *Updater) Apply(liveObject, configObject *typed.TypedValue, version fieldpath.APIVersion, managers fieldpath.ManagedFields, manager string, force bool) (*typed.TypedValue, fieldpath.ManagedFields, error) {
+	return s.apply(liveObject, configObject, version, managers, manager, forcePolicy(force), nil)
+}
+
+// ApplyWithForcePolicy is like Apply, but only forces the conflicts that
+// force selects, e.g. the conflicts on some fields, or with some managers.
+// The other conflicts fail the apply, and are returned as Conflicts. A nil
+// policy forces nothing.
+func (s *Updater) ApplyWithForcePolicy(liveObject, configObject *typed.TypedValue, version fieldpath.APIVersion, managers fieldpath.ManagedFields, manager string, force *ForcePolicy) (*typed.TypedValue, fieldpath.ManagedFields, error) {
+	return s.apply(liveObject, configObject, version, managers, manager, force, nil)
+}
+
+// ApplyYielding is like Apply, but instead of failing on conflicts, or
+// forcing them, it yields them: the conflicting fields are dropped from
+// configObject, and the rest is applied. The yielded fields keep their
+// values and their managers, and manager doesn't own them. The yielded
+// conflicts are returned, if any.
+func (s *Updater) ApplyYielding(liveObject, configObject *typed.TypedValue, version fieldpath.APIVersion, managers fieldpath.ManagedFields, manager string) (*typed.TypedValue, fieldpath.ManagedFields, Conflicts, error) {
+	var yielded Conflicts
+	for {
+		newObject, newManagers, err := s.apply(liveObject, configObject, version, managers, manager, nil, nil)
+		conflicts, ok := err.(Conflicts)
+		if !ok {
+			if err != nil {
+				return nil, fieldpath.ManagedFields{}, nil, err
+			}
+			return newObject, newManagers, yielded, nil
+		}
+		// Dropping the conflicting fields can change what is pruned, and
+		// so what conflicts, so this applies again until nothing does.
+		yieldedObject, err := s.removeConflicts(configObject, version, conflicts)
+		if err != nil {
+			return nil, fieldpath.ManagedFields{}, nil, fmt.Errorf("failed to yield conflicts: %v", err)
+		}
+		if value.Equals(yieldedObject.AsValue(), configObject.AsValue()) {
+			// The conflicts aren't on fields of the configuration, e.g.
+			// they are on fields that the normalization of unions changed.
+			return nil, fieldpath.ManagedFields{}, nil, conflicts
+		}
+		configObject = yieldedObject
+		yielded = append(yielded, conflicts...)
+	}
+}
+
+// removeConflicts removes the fields of the conflicts, which are at the
+// versions of their managers, from configObject, at version.
+func (s *Updater) removeConflicts(configObject *typed.TypedValue, version fieldpath.APIVersion, conflicts Conflicts) (*typed.TypedValue, error) {
+	sets := map[fieldpath.APIVersion]*fieldpath.Set{}
+	for _, conflict := range conflicts {
+		if _, ok := sets[conflict.APIVersion]; !ok {
+			sets[conflict.APIVersion] = fieldpath.NewSet()
+		}
+		sets[conflict.APIVersion].Insert(conflict.Path)
+	}
+	if set, ok := sets[version]; ok {
+		configObject = configObject.RemoveItems(set)
+		delete(sets, version)
+	}
+	for conflictVersion, set := range sets {
+		converted, err := s.Converter.Convert(configObject, conflictVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert config to version %v: %v", conflictVersion, err)
+		}
+		configObject, err = s.Converter.Convert(converted.RemoveItems(set), version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert config from version %v: %v", conflictVersion, err)
+		}
+	}
+	return configObject, nil
+}
+
+// forcePolicy returns the policy of an apply that is forced or not.
+func forcePolicy(force bool) *ForcePolicy {
+	if force {
+		return forceAll
+	}
+	return nil
+}
+
+// ApplyWithReport is like Apply, but also returns a report of what the
+// apply decided for each field: which fields are newly owned, shared with
+// or conflicting with other managers, pruned, or kept from being pruned
+// and why. When the apply fails because of conflicts, the report is
+// returned along with the Conflicts, so that it can explain a dry run.
+func (s *Updater) ApplyWithReport(liveObject, configObject *typed.TypedValue, version fieldpath.APIVersion, managers fieldpath.ManagedFields, manager string, force bool) (*typed.TypedValue, fieldpath.ManagedFields, *ApplyReport, error) {
+	r := newReporter(manager, force)
+	newObject, managers, err := s.apply(liveObject, configObject, version, managers, manager, forcePolicy(force), r)
+	if err != nil {
+		if _, ok := err.(Conflicts); !ok {
+			return nil, managers, nil, err
+		}
+	}
+	return newObject, managers, r.finish(), err
+}
+
+// apply implements Apply, reporting its decisions to r if it isn't nil.
+func (s *Updater) apply(liveObject, configObject *typed.TypedValue, version fieldpath.APIVersion, managers fieldpath.ManagedFields, manager string, force *ForcePolicy, r *reporter) (*typed.TypedValue, fieldpath.ManagedFields, error) {
 	var err error
 	managers, err = s.reconcileManagedFieldsWithSchemaChanges(liveObject, managers)
 	if err != nil {
@@ -216,6 +375,10 @@ func (s *Updater) Apply(liveObject, configObject *typed.TypedValue, version fiel
 	if err != nil {
 		return nil, fieldpath.ManagedFields{}, fmt.Errorf("failed to merge config: %v", err)
 	}
+	newObject, err = configObject.NormalizeUnionsApply(newObject)
+	if err != nil {
+		return nil, fieldpath.ManagedFields{}, fmt.Errorf("failed to normalize unions: %v", err)
+	}
 	lastSet := managers[manager]
 	set, err := configObject.ToFieldSet()
 	if err != nil {
@@ -234,12 +397,13 @@ func (s *Updater) Apply(liveObject, configObject *typed.TypedValue, version fiel
 	if ignoreFilter != nil {
 		set = ignoreFilter.Filter(set)
 	}
+	r.applied(set, version, lastSet, managers, manager)
 	managers[manager] = fieldpath.NewVersionedSet(set, version, true)
-	newObject, err = s.prune(newObject, managers, manager, lastSet)
+	newObject, err = s.prune(newObject, managers, manager, lastSet, r)
 	if err != nil {
 		return nil, fieldpath.ManagedFields{}, fmt.Errorf("failed to prune fields: %v", err)
 	}
-	managers, _, err = s.update(liveObject, newObject, version, managers, manager, force)
+	managers, _, err = s.update(liveObject, newObject, version, managers, manager, force, r)
 	if err != nil {
 		return nil, fieldpath.ManagedFields{}, err
 	}
@@ -253,7 +417,7 @@ func (s *Updater) Apply(liveObject, configObject *typed.TypedValue, version fiel
 // * applyingManager applied it last time
 // * applyingManager didn't apply it this time
 // * no other applier claims to manage it
-func (s *Updater) prune(merged *typed.TypedValue, managers fieldpath.ManagedFields, applyingManager string, lastSet fieldpath.VersionedSet) (*typed.TypedValue, error) {
+func (s *Updater) prune(merged *typed.TypedValue, managers fieldpath.ManagedFields, applyingManager string, lastSet fieldpath.VersionedSet, r *reporter) (*typed.TypedValue, error) {
 	if lastSet == nil || lastSet.Set().Empty() {
 		return merged, nil
 	}
@@ -267,15 +431,27 @@ func (s *Updater) prune(merged *typed.TypedValue, managers fieldpath.ManagedFiel
 	}
 
 	sc, tr := convertedMerged.Schema(), convertedMerged.TypeRef()
-	pruned := convertedMerged.RemoveItems(lastSet.Set().EnsureNamedFieldsAreMembers(sc, tr))
-	pruned, err = s.addBackOwnedItems(convertedMerged, pruned, version, managers, applyingManager)
+	removed := convertedMerged.RemoveItems(lastSet.Set().EnsureNamedFieldsAreMembers(sc, tr))
+	pruned, err := s.addBackOwnedItems(convertedMerged, removed, version, managers, applyingManager)
 	if err != nil {
 		return nil, fmt.Errorf("failed add back owned items: %v", err)
 	}
+	restoredOwned := pruned
 	pruned, err = s.addBackDanglingItems(convertedMerged, pruned, lastSet)
 	if err != nil {
 		return nil, fmt.Errorf("failed add back dangling items: %v", err)
 	}
+	if r != nil {
+		// addBackOwnedItems leaves its result at the version of the last
+		// managers it added back.
+		restoredOwned, err = s.Converter.Convert(restoredOwned, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert pruned object to last applied version: %v", err)
+		}
+		if err := r.pruned(convertedMerged, removed, restoredOwned, pruned, managers, lastSet); err != nil {
+			return nil, fmt.Errorf("failed to report pruned fields: %v", err)
+		}
+	}
 	return s.Converter.Convert(pruned, managers[applyingManager].APIVersion())
 }
 
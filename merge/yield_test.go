@@ -0,0 +1,179 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	. "sigs.k8s.io/structured-merge-diff/v6/internal/fixture"
+	"sigs.k8s.io/structured-merge-diff/v6/merge"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+var yieldParser = func() Parser {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: a
+      type:
+        scalar: string
+    - name: b
+      type:
+        scalar: string
+    - name: c
+      type:
+        scalar: string
+    - name: items
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["name"]
+- name: item
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+    - name: value
+      type:
+        scalar: string
+`)
+	if err != nil {
+		panic(err)
+	}
+	return SameVersionParser{T: parser.Type("root")}
+}()
+
+func TestApplyYielding(t *testing.T) {
+	tests := map[string]TestCase{
+		"no_conflicts": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "reconciler",
+					APIVersion: "v1",
+					Object:     `{"a": "1"}`,
+				},
+				ApplyYielding{
+					Manager:    "reconciler",
+					APIVersion: "v1",
+					Object:     `{"a": "2", "b": "2"}`,
+				},
+			},
+			Object:     `{"a": "2", "b": "2"}`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"reconciler": fieldpath.NewVersionedSet(_NS(_P("a"), _P("b")), "v1", true),
+			},
+		},
+		"updated_field": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "reconciler",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "1"}`,
+				},
+				Update{
+					Manager:    "human",
+					APIVersion: "v2",
+					Object:     `{"a": "5", "b": "1"}`,
+				},
+				ApplyYielding{
+					Manager:    "reconciler",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "2", "c": "3"}`,
+					Yielded:    merge.Conflicts{{Manager: "human", Path: _P("a")}},
+				},
+			},
+			Object:     `{"a": "5", "b": "2", "c": "3"}`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"reconciler": fieldpath.NewVersionedSet(_NS(_P("b"), _P("c")), "v1", true),
+				"human":      fieldpath.NewVersionedSet(_NS(_P("a")), "v2", false),
+			},
+		},
+		"shared_field": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "reconciler",
+					APIVersion: "v1",
+					Object:     `{"a": "1", "b": "1"}`,
+				},
+				Apply{
+					Manager:    "human",
+					APIVersion: "v1",
+					Object:     `{"a": "1"}`,
+				},
+				ApplyYielding{
+					Manager:    "reconciler",
+					APIVersion: "v1",
+					Object:     `{"a": "2", "b": "2"}`,
+					Yielded:    merge.Conflicts{{Manager: "human", Path: _P("a")}},
+				},
+			},
+			Object:     `{"a": "1", "b": "2"}`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"reconciler": fieldpath.NewVersionedSet(_NS(_P("b")), "v1", true),
+				"human":      fieldpath.NewVersionedSet(_NS(_P("a")), "v1", true),
+			},
+		},
+		"item_field": {
+			Ops: []Operation{
+				Apply{
+					Manager:    "reconciler",
+					APIVersion: "v1",
+					Object:     `{"items": [{"name": "n", "value": "1"}]}`,
+				},
+				Update{
+					Manager:    "human",
+					APIVersion: "v1",
+					Object:     `{"items": [{"name": "n", "value": "5"}]}`,
+				},
+				ApplyYielding{
+					Manager:    "reconciler",
+					APIVersion: "v1",
+					Object:     `{"items": [{"name": "n", "value": "2"}, {"name": "m", "value": "2"}]}`,
+					Yielded:    merge.Conflicts{{Manager: "human", Path: _P("items", _KBF("name", "n"), "value")}},
+				},
+			},
+			Object:     `{"items": [{"name": "n", "value": "5"}, {"name": "m", "value": "2"}]}`,
+			APIVersion: "v1",
+			Managed: fieldpath.ManagedFields{
+				"reconciler": fieldpath.NewVersionedSet(_NS(
+					_P("items", _KBF("name", "n")),
+					_P("items", _KBF("name", "n"), "name"),
+					_P("items", _KBF("name", "m")),
+					_P("items", _KBF("name", "m"), "name"),
+					_P("items", _KBF("name", "m"), "value"),
+				), "v1", true),
+				"human": fieldpath.NewVersionedSet(_NS(_P("items", _KBF("name", "n"), "value")), "v1", false),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := test.TestWithConverter(yieldParser, &specificVersionConverter{AcceptedVersions: []fieldpath.APIVersion{"v1", "v2"}}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Document is an OpenAPI v3 document. Only its component schemas are
+// read, the rest of the document is ignored.
+type Document struct {
+	OpenAPI    string     `json:"openapi,omitempty"`
+	Info       *Info      `json:"info,omitempty"`
+	Components Components `json:"components,omitempty"`
+}
+
+// Info is the metadata of a Document.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Components holds the reusable objects of a Document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema is an OpenAPI v3 schema object, with the Kubernetes extensions.
+// Only the keywords that matter to the conversion are kept.
+type Schema struct {
+	Ref string `json:"$ref,omitempty"`
+
+	Type   string `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+
+	Properties           map[string]*Schema    `json:"properties,omitempty"`
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties,omitempty"`
+	Required             []string              `json:"required,omitempty"`
+	Items                *Schema               `json:"items,omitempty"`
+	Default              interface{}           `json:"default,omitempty"`
+
+	Enum          []interface{} `json:"enum,omitempty"`
+	Minimum       *float64      `json:"minimum,omitempty"`
+	Maximum       *float64      `json:"maximum,omitempty"`
+	Pattern       string        `json:"pattern,omitempty"`
+	MinLength     *int64        `json:"minLength,omitempty"`
+	MaxLength     *int64        `json:"maxLength,omitempty"`
+	MaxProperties *int64        `json:"maxProperties,omitempty"`
+	MinItems      *int64        `json:"minItems,omitempty"`
+	MaxItems      *int64        `json:"maxItems,omitempty"`
+
+	AllOf []*Schema `json:"allOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+
+	// ListType is either "atomic", "set" or "map".
+	ListType string `json:"x-kubernetes-list-type,omitempty"`
+	// ListMapKeys are the keys of the items of lists of type "map".
+	ListMapKeys []string `json:"x-kubernetes-list-map-keys,omitempty"`
+	// MapType is either "atomic" or "granular".
+	MapType               string `json:"x-kubernetes-map-type,omitempty"`
+	PreserveUnknownFields bool   `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	IntOrString           bool   `json:"x-kubernetes-int-or-string,omitempty"`
+	// PatchStrategy and PatchMergeKey describe how strategic merge
+	// patches merge lists, and are used for the lists without ListType.
+	PatchStrategy string `json:"x-kubernetes-patch-strategy,omitempty"`
+	PatchMergeKey string `json:"x-kubernetes-patch-merge-key,omitempty"`
+	// Unions are the sets of properties of which only one can be set.
+	Unions []Union `json:"x-kubernetes-unions,omitempty"`
+}
+
+// Union is a set of properties of an object of which only one can be set,
+// optionally with the property that tells which one is set.
+type Union struct {
+	Discriminator string `json:"discriminator,omitempty"`
+	// FieldsToDiscriminateBy maps the properties of the union to the value
+	// of the discriminator when they are set.
+	FieldsToDiscriminateBy map[string]string `json:"fields-to-discriminateBy,omitempty"`
+}
+
+// AdditionalProperties is either a boolean, that allows (or forbids) any
+// additional property, or the Schema of the additional properties.
+type AdditionalProperties struct {
+	Allows bool
+	Schema *Schema
+}
+
+// UnmarshalJSON unmarshals either a boolean or a Schema.
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	var allows bool
+	if err := json.Unmarshal(data, &allows); err == nil {
+		*a = AdditionalProperties{Allows: allows}
+		return nil
+	}
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("additionalProperties must be a boolean or a schema: %v", err)
+	}
+	*a = AdditionalProperties{Allows: true, Schema: &s}
+	return nil
+}
+
+// MarshalJSON marshals the Schema, or the boolean if there is none.
+func (a AdditionalProperties) MarshalJSON() ([]byte, error) {
+	if a.Schema != nil {
+		return json.Marshal(a.Schema)
+	}
+	return json.Marshal(a.Allows)
+}
+
+// Parse parses an OpenAPI v3 document, in JSON or YAML.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %v", err)
+	}
+	return &doc, nil
+}
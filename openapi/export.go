@@ -0,0 +1,311 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+)
+
+// InlinedRootName is the name of the component schema of the root type
+// exported by FromSchema, when it isn't a named type.
+const InlinedRootName = "__inlined_root_"
+
+// FromSchema exports the root type of s, and the named types it refers to,
+// as the component schemas of an OpenAPI v3 document, named after their
+// TypeDefs. The document has no Info, which is up to the caller.
+//
+// References to named types become references to their component
+// schemas, with an allOf when they need a default or an element
+// relationship of their own. Maps become objects, with an
+// x-kubernetes-map-type of "atomic" if they are atomic, and lists become
+// arrays, with an x-kubernetes-list-type of "atomic", "set" or "map" (with
+// x-kubernetes-list-map-keys). The unions of maps become
+// x-kubernetes-unions, and formats and constraints are kept, with the
+// float scalars becoming numbers of the "double" format. The
+// untyped types become schemas without a type: the untyped scalar becomes
+// an x-kubernetes-int-or-string, and the maps of UntypedDeducedName
+// preserve their unknown fields.
+//
+// The types that can't be exported, such as atoms that are several kinds
+// of values, or unions that deduce invalid discriminators, are exported as schemas without a
+// type, and reported by returning Errors along with the Document.
+func FromSchema(s *schema.Schema, root schema.TypeRef) (*Document, error) {
+	e := exporter{
+		schema:  s,
+		schemas: map[string]*Schema{},
+		queued:  map[string]bool{},
+	}
+	if root.NamedType != nil {
+		e.enqueue(*root.NamedType)
+	} else {
+		if _, ok := s.FindNamedType(InlinedRootName); ok {
+			e.errorf(componentPath(InlinedRootName), "the inlined root type can't be named after an existing type")
+		}
+		e.schemas[InlinedRootName] = e.typeRef(componentPath(InlinedRootName), root)
+	}
+	for len(e.queue) > 0 {
+		name := e.queue[0]
+		e.queue = e.queue[1:]
+		path := componentPath(name)
+		td, ok := s.FindNamedType(name)
+		if !ok {
+			e.errorf(path, "unknown type %q", name)
+			e.schemas[name] = &Schema{}
+			continue
+		}
+		e.schemas[name] = e.atom(path, td.Atom, nil)
+	}
+
+	doc := &Document{
+		OpenAPI:    "3.0.0",
+		Components: Components{Schemas: e.schemas},
+	}
+	if len(e.errs) > 0 {
+		return doc, e.errs
+	}
+	return doc, nil
+}
+
+type exporter struct {
+	schema  *schema.Schema
+	schemas map[string]*Schema
+	// queue has the names of the types that are referred to, and are
+	// yet to be exported.
+	queue  []string
+	queued map[string]bool
+	errs   Errors
+}
+
+func (e *exporter) errorf(path, format string, args ...interface{}) {
+	e.errs = append(e.errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *exporter) enqueue(name string) {
+	if !e.queued[name] {
+		e.queued[name] = true
+		e.queue = append(e.queue, name)
+	}
+}
+
+// typeRef returns the schema of the type that tr refers to.
+func (e *exporter) typeRef(path string, tr schema.TypeRef) *Schema {
+	if tr.NamedType == nil {
+		return e.atom(path, tr.Inlined, tr.ElementRelationship)
+	}
+	name := *tr.NamedType
+	switch name {
+	case UntypedAtomicName:
+		return &Schema{}
+	case UntypedDeducedName:
+		return &Schema{PreserveUnknownFields: true}
+	}
+	td, ok := e.schema.FindNamedType(name)
+	if !ok {
+		e.errorf(path, "unknown type %q", name)
+		return &Schema{}
+	}
+	e.enqueue(name)
+	ref := &Schema{Ref: componentsPrefix + escapeName(name)}
+	if tr.ElementRelationship == nil {
+		return ref
+	}
+	out := &Schema{AllOf: []*Schema{ref}}
+	switch {
+	case td.Atom.List != nil && td.Atom.Map == nil:
+		out.ListType, out.ListMapKeys = e.listType(path, td.Atom.List, *tr.ElementRelationship)
+	case td.Atom.Map != nil && td.Atom.List == nil:
+		out.MapType = mapType(*tr.ElementRelationship)
+		if out.MapType == "" {
+			out.MapType = "granular"
+		}
+	default:
+		e.errorf(path, "element relationship of type %q, which is not a list or a map", name)
+	}
+	return out
+}
+
+// atom returns the schema of the values of a. er overrides the element
+// relationship of the list or map of a, if it is set.
+func (e *exporter) atom(path string, a schema.Atom, er *schema.ElementRelationship) *Schema {
+	var out *Schema
+	switch {
+	case a.Scalar == nil && a.List == nil && a.Map == nil:
+		return &Schema{}
+	case a.Scalar != nil && *a.Scalar == schema.Untyped && a.List != nil && a.Map != nil:
+		// The untyped values of UntypedAtomicName or UntypedDeducedName.
+		relationship := a.Map.ElementRelationship
+		if er != nil {
+			relationship = *er
+		}
+		if relationship == schema.Atomic {
+			return &Schema{}
+		}
+		return &Schema{PreserveUnknownFields: true}
+	case a.List != nil && a.Map != nil, a.Scalar != nil && (a.List != nil || a.Map != nil):
+		e.errorf(path, "values that are either scalars, lists or maps are not supported")
+		return &Schema{}
+	case a.Scalar != nil:
+		out = e.scalar(path, *a.Scalar)
+	case a.List != nil:
+		out = e.list(path, a.List, er)
+	default:
+		out = e.mapSchema(path, a.Map, er)
+	}
+	if a.Format != "" {
+		out.Format = string(a.Format)
+	}
+	if c := a.Constraints; c != nil {
+		out.Enum = c.Enum
+		out.Minimum, out.Maximum = c.Minimum, c.Maximum
+		out.Pattern = c.Pattern
+		out.MinLength, out.MaxLength = c.MinLength, c.MaxLength
+		out.Required = c.Required
+		out.MaxProperties = c.MaxProperties
+		out.MinItems, out.MaxItems = c.MinItems, c.MaxItems
+	}
+	return out
+}
+
+func (e *exporter) scalar(path string, s schema.Scalar) *Schema {
+	switch s {
+	case schema.String:
+		return &Schema{Type: "string"}
+	case schema.Numeric:
+		return &Schema{Type: "number"}
+	case schema.Float:
+		return &Schema{Type: "number", Format: "double"}
+	case schema.Integer:
+		return &Schema{Type: "integer"}
+	case schema.Boolean:
+		return &Schema{Type: "boolean"}
+	case schema.Untyped:
+		return &Schema{
+			IntOrString: true,
+			AnyOf:       []*Schema{{Type: "integer"}, {Type: "string"}},
+		}
+	}
+	e.errorf(path, "unknown scalar type %q", s)
+	return &Schema{}
+}
+
+func (e *exporter) list(path string, l *schema.List, er *schema.ElementRelationship) *Schema {
+	relationship := l.ElementRelationship
+	if er != nil {
+		relationship = *er
+	}
+	out := &Schema{
+		Type:  "array",
+		Items: e.typeRef(path+".items", l.ElementType),
+	}
+	out.ListType, out.ListMapKeys = e.listType(path, l, relationship)
+	return out
+}
+
+func (e *exporter) listType(path string, l *schema.List, relationship schema.ElementRelationship) (string, []string) {
+	switch relationship {
+	case schema.Atomic:
+		return "atomic", nil
+	case schema.Associative:
+		if len(l.Keys) > 0 {
+			return "map", l.Keys
+		}
+		return "set", nil
+	}
+	e.errorf(path, "list with element relationship %q is not supported", relationship)
+	return "", nil
+}
+
+func (e *exporter) mapSchema(path string, m *schema.Map, er *schema.ElementRelationship) *Schema {
+	relationship := m.ElementRelationship
+	if er != nil {
+		relationship = *er
+	}
+	out := &Schema{Type: "object", MapType: mapType(relationship)}
+	if len(m.Fields) > 0 {
+		out.Properties = make(map[string]*Schema, len(m.Fields))
+	}
+	for _, f := range m.Fields {
+		prop := e.typeRef(path+".properties."+f.Name, f.Type)
+		if f.Default != nil {
+			if prop.Ref != "" {
+				// The siblings of a reference are ignored.
+				prop = &Schema{AllOf: []*Schema{prop}}
+			}
+			prop.Default = f.Default
+		}
+		out.Properties[f.Name] = prop
+	}
+	switch {
+	case isUntypedDeduced(m.ElementType):
+		out.PreserveUnknownFields = true
+	case m.ElementType != (schema.TypeRef{}):
+		out.AdditionalProperties = &AdditionalProperties{
+			Allows: true,
+			Schema: e.typeRef(path+".additionalProperties", m.ElementType),
+		}
+	case len(m.Fields) == 0:
+		// Objects without properties allow any property otherwise.
+		out.AdditionalProperties = &AdditionalProperties{}
+	}
+	for _, u := range m.Unions {
+		if u.DeduceInvalidDiscriminator {
+			e.errorf(path, "unions that deduce invalid discriminators are not supported")
+		}
+		union := Union{FieldsToDiscriminateBy: make(map[string]string, len(u.Fields))}
+		if u.Discriminator != nil {
+			union.Discriminator = *u.Discriminator
+		}
+		for _, f := range u.Fields {
+			union.FieldsToDiscriminateBy[f.FieldName] = f.DiscriminatorValue
+		}
+		out.Unions = append(out.Unions, union)
+	}
+	return out
+}
+
+// mapType returns the x-kubernetes-map-type of maps with the relationship.
+func mapType(relationship schema.ElementRelationship) string {
+	if relationship == schema.Atomic {
+		return "atomic"
+	}
+	return ""
+}
+
+// isUntypedDeduced returns true if tr refers to the type of
+// UntypedDeducedName, by name or inlined.
+func isUntypedDeduced(tr schema.TypeRef) bool {
+	if tr.NamedType != nil {
+		return *tr.NamedType == UntypedDeducedName && tr.ElementRelationship == nil
+	}
+	return tr.ElementRelationship == nil && tr.Inlined.Equals(ptr(untypedDeduced()))
+}
+
+func ptr(a schema.Atom) *schema.Atom {
+	return &a
+}
+
+func componentPath(name string) string {
+	return "components.schemas." + name
+}
+
+// escapeName escapes the name of a component schema for a reference.
+func escapeName(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,374 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/openapi"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	sigsyaml "sigs.k8s.io/yaml"
+	yaml "sigs.k8s.io/yaml/goyaml.v2"
+)
+
+func TestFromSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   typed.YAMLObject
+		root     string
+		expected string
+	}{{
+		name: "scalars",
+		schema: `types:
+- name: root
+  map:
+    fields:
+    - name: string
+      type:
+        scalar: string
+      default: a
+    - name: numeric
+      type:
+        scalar: numeric
+    - name: integer
+      type:
+        scalar: integer
+        format: int32
+        constraints:
+          minimum: 0
+          maximum: 10
+    - name: float
+      type:
+        scalar: float
+    - name: boolean
+      type:
+        scalar: boolean
+    - name: intOrString
+      type:
+        scalar: untyped
+    - name: atomic
+      type:
+        namedType: __untyped_atomic_
+    - name: deduced
+      type:
+        namedType: __untyped_deduced_
+`,
+		root: "root",
+		expected: `{"components": {"schemas": {"root": {"type": "object", "properties": {
+			"string": {"type": "string", "default": "a"},
+			"numeric": {"type": "number"},
+			"integer": {"type": "integer", "format": "int32", "minimum": 0, "maximum": 10},
+			"float": {"type": "number", "format": "double"},
+			"boolean": {"type": "boolean"},
+			"intOrString": {"x-kubernetes-int-or-string": true, "anyOf": [{"type": "integer"}, {"type": "string"}]},
+			"atomic": {},
+			"deduced": {"x-kubernetes-preserve-unknown-fields": true}
+		}}}}}`,
+	}, {
+		name: "lists",
+		schema: `types:
+- name: root
+  map:
+    fields:
+    - name: atomic
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: set
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: associative
+        constraints:
+          maxItems: 3
+    - name: map
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["name"]
+    - name: override
+      type:
+        namedType: items
+        elementRelationship: atomic
+- name: item
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+  constraints:
+    required: ["name"]
+- name: items
+  list:
+    elementType:
+      namedType: item
+    elementRelationship: associative
+    keys: ["name"]
+`,
+		root: "root",
+		expected: `{"components": {"schemas": {
+			"root": {"type": "object", "properties": {
+				"atomic": {"type": "array", "items": {"type": "string"}, "x-kubernetes-list-type": "atomic"},
+				"set": {"type": "array", "items": {"type": "string"}, "x-kubernetes-list-type": "set", "maxItems": 3},
+				"map": {"type": "array", "items": {"$ref": "#/components/schemas/item"}, "x-kubernetes-list-type": "map", "x-kubernetes-list-map-keys": ["name"]},
+				"override": {"allOf": [{"$ref": "#/components/schemas/items"}], "x-kubernetes-list-type": "atomic"}
+			}},
+			"item": {"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]},
+			"items": {"type": "array", "items": {"$ref": "#/components/schemas/item"}, "x-kubernetes-list-type": "map", "x-kubernetes-list-map-keys": ["name"]}
+		}}}`,
+	}, {
+		name: "maps",
+		schema: `types:
+- name: root
+  map:
+    fields:
+    - name: strings
+      type:
+        map:
+          elementType:
+            scalar: string
+    - name: atomic
+      type:
+        map:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: empty
+      type:
+        map: {}
+    - name: child
+      type:
+        namedType: a/b
+      default: {}
+    - name: granular
+      type:
+        namedType: a/b
+        elementRelationship: separable
+    - name: preserved
+      type:
+        map:
+          fields:
+          - name: a
+            type:
+              scalar: string
+          elementType:
+            namedType: __untyped_deduced_
+    unions:
+    - discriminator: type
+      fields:
+      - fieldName: strings
+        discriminatorValue: Strings
+      - fieldName: atomic
+        discriminatorValue: Atomic
+- name: a/b
+  map:
+    fields:
+    - name: a
+      type:
+        scalar: string
+    elementRelationship: atomic
+- name: unused
+  scalar: string
+`,
+		root: "root",
+		expected: `{"components": {"schemas": {
+			"root": {"type": "object", "properties": {
+				"strings": {"type": "object", "additionalProperties": {"type": "string"}},
+				"atomic": {"type": "object", "additionalProperties": {"type": "string"}, "x-kubernetes-map-type": "atomic"},
+				"empty": {"type": "object", "additionalProperties": false},
+				"child": {"allOf": [{"$ref": "#/components/schemas/a~1b"}], "default": {}},
+				"granular": {"allOf": [{"$ref": "#/components/schemas/a~1b"}], "x-kubernetes-map-type": "granular"},
+				"preserved": {"type": "object", "properties": {"a": {"type": "string"}}, "x-kubernetes-preserve-unknown-fields": true}
+			}, "x-kubernetes-unions": [{"discriminator": "type", "fields-to-discriminateBy": {"strings": "Strings", "atomic": "Atomic"}}]},
+			"a/b": {"type": "object", "properties": {"a": {"type": "string"}}, "x-kubernetes-map-type": "atomic"}
+		}}}`,
+	}, {
+		name: "inlined root",
+		schema: `types:
+- name: child
+  scalar: string
+`,
+		expected: `{"components": {"schemas": {
+			"__inlined_root_": {"type": "array", "items": {"$ref": "#/components/schemas/child"}, "x-kubernetes-list-type": "set"},
+			"child": {"type": "string"}
+		}}}`,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := typed.NewParser(tt.schema)
+			if err != nil {
+				t.Fatalf("failed to parse schema: %v", err)
+			}
+			root := schema.TypeRef{NamedType: &tt.root}
+			if tt.root == "" {
+				root = schema.TypeRef{Inlined: schema.Atom{List: &schema.List{
+					ElementType:         schema.TypeRef{NamedType: &parser.Schema.Types[0].Name},
+					ElementRelationship: schema.Associative,
+				}}}
+			}
+			doc, err := openapi.FromSchema(&parser.Schema, root)
+			if err != nil {
+				t.Fatalf("failed to export schema: %v", err)
+			}
+			expected, err := openapi.Parse([]byte(tt.expected))
+			if err != nil {
+				t.Fatalf("failed to parse expected document: %v", err)
+			}
+			expected.OpenAPI = "3.0.0"
+			gotYAML, err := sigsyaml.Marshal(doc)
+			if err != nil {
+				t.Fatalf("failed to marshal document: %v", err)
+			}
+			expectedYAML, err := sigsyaml.Marshal(expected)
+			if err != nil {
+				t.Fatalf("failed to marshal expected document: %v", err)
+			}
+			if string(gotYAML) != string(expectedYAML) {
+				t.Errorf("expected:\n%s\ngot:\n%s", expectedYAML, gotYAML)
+			}
+		})
+	}
+}
+
+func TestFromSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema typed.YAMLObject
+		errors []string
+	}{{
+		name: "unknown type",
+		schema: `types:
+- name: root
+  map:
+    fields:
+    - name: a
+      type:
+        namedType: missing
+`,
+		errors: []string{`components.schemas.root.properties.a: unknown type "missing"`},
+	}, {
+		name: "several kinds",
+		schema: `types:
+- name: root
+  scalar: string
+  map:
+    elementType:
+      scalar: string
+`,
+		errors: []string{"components.schemas.root: values that are either scalars, lists or maps are not supported"},
+	}, {
+		name: "separable list",
+		schema: `types:
+- name: root
+  list:
+    elementType:
+      scalar: string
+    elementRelationship: separable
+`,
+		errors: []string{`components.schemas.root: list with element relationship "separable" is not supported`},
+	}, {
+		name: "deduced union",
+		schema: `types:
+- name: root
+  map:
+    fields:
+    - name: a
+      type:
+        scalar: string
+    unions:
+    - deduceInvalidDiscriminator: true
+      fields:
+      - fieldName: a
+        discriminatorValue: A
+`,
+		errors: []string{"components.schemas.root: unions that deduce invalid discriminators are not supported"},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := typed.NewParser(tt.schema)
+			if err != nil {
+				t.Fatalf("failed to parse schema: %v", err)
+			}
+			root := "root"
+			doc, err := openapi.FromSchema(&parser.Schema, schema.TypeRef{NamedType: &root})
+			if doc == nil {
+				t.Fatalf("expected a document along with the errors")
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v, got none", tt.errors)
+			}
+			for _, expected := range tt.errors {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error %q, got:\n%v", expected, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFromSchemaRoundTrip(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("..", "internal", "testdata", "k8s-schema.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	var s schema.Schema
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	for _, name := range []string{
+		"io.k8s.api.apps.v1.Deployment",
+		"io.k8s.api.core.v1.Pod",
+		"io.k8s.apiextensions-apiserver.pkg.apis.apiextensions.v1beta1.CustomResourceDefinition",
+	} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			doc, err := openapi.FromSchema(&s, schema.TypeRef{NamedType: &name})
+			if err != nil {
+				t.Fatalf("failed to export schema: %v", err)
+			}
+			got, err := openapi.ToSchema(doc)
+			if err != nil {
+				t.Fatalf("failed to import schema: %v", err)
+			}
+			for _, td := range got.Types {
+				expected, ok := s.FindNamedType(td.Name)
+				if !ok {
+					t.Errorf("unexpected type %q", td.Name)
+					continue
+				}
+				if !td.Atom.Equals(&expected.Atom) {
+					gotYAML, _ := yaml.Marshal(td)
+					expectedYAML, _ := yaml.Marshal(expected)
+					t.Errorf("expected:\n%s\ngot:\n%s", expectedYAML, gotYAML)
+				}
+			}
+		})
+	}
+}
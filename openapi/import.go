@@ -0,0 +1,567 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+)
+
+const (
+	// UntypedAtomicName is the name of the type of the values that can
+	// be anything, and are atomic.
+	UntypedAtomicName = "__untyped_atomic_"
+	// UntypedDeducedName is the name of the type of the values that can
+	// be anything, and whose maps are merged field by field.
+	UntypedDeducedName = "__untyped_deduced_"
+
+	componentsPrefix = "#/components/schemas/"
+)
+
+// Error is a construct of an OpenAPI document that can't be converted.
+type Error struct {
+	// Path is the location of the construct in the document, e.g.
+	// "components.schemas.Foo.properties.bar".
+	Path    string
+	Message string
+}
+
+// Error returns a human readable error message.
+func (e Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Errors is the list of the constructs of an OpenAPI document that can't
+// be converted.
+type Errors []Error
+
+// Error returns a human readable error message reporting each error in the
+// list.
+func (errs Errors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	messages := []string{"errors:"}
+	for _, e := range errs {
+		messages = append(messages, "  "+e.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// ToSchema converts the component schemas of doc to a Schema, with a
+// TypeDef of the same name for each of them, followed by the untyped
+// types that they use. References to component schemas become references
+// to their TypeDefs, with the element relationship of their
+// x-kubernetes-list-type or x-kubernetes-map-type if they have one, and
+// allOf is merged into a single schema.
+//
+// Objects become maps, which are atomic if their x-kubernetes-map-type
+// is "atomic", and whose unknown fields are untyped if they have no
+// properties, allow additional properties or preserve unknown fields
+// (with x-kubernetes-preserve-unknown-fields). Arrays become lists, which
+// are atomic, unless their x-kubernetes-list-type is "set" or "map" (with
+// the keys of x-kubernetes-list-map-keys), or, without a list type, their
+// x-kubernetes-patch-strategy is "merge". The x-kubernetes-unions of
+// objects become the unions of their maps. Integers become integer
+// scalars, numbers become numeric scalars, or float scalars if their
+// format is "float" or "double", and the values of
+// x-kubernetes-int-or-string, and of schemas without a type, become
+// untyped. The other formats are kept, and so are the constraints, such
+// as enum, minimum, pattern, required or maxItems.
+//
+// The constructs that can't be converted, such as oneOf, anyOf (other
+// than for x-kubernetes-int-or-string), not, or references to schemas
+// that aren't in the components, are converted to untyped atomic values,
+// and reported by returning Errors along with the Schema. The constraints
+// next to references are ignored, and reported the same way.
+func ToSchema(doc *Document) (*schema.Schema, error) {
+	c := converter{schemas: doc.Components.Schemas}
+	names := make([]string, 0, len(c.schemas))
+	for name := range c.schemas {
+		if name != UntypedAtomicName && name != UntypedDeducedName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	s := &schema.Schema{}
+	for _, name := range names {
+		s.Types = append(s.Types, c.typeDef(name))
+	}
+	s.Types = append(s.Types, schema.TypeDef{Name: UntypedAtomicName, Atom: untypedAtomic()})
+	if c.usesDeduced {
+		s.Types = append(s.Types, schema.TypeDef{Name: UntypedDeducedName, Atom: untypedDeduced()})
+	}
+	if len(c.errs) > 0 {
+		return s, c.errs
+	}
+	return s, nil
+}
+
+type converter struct {
+	schemas     map[string]*Schema
+	errs        Errors
+	usesDeduced bool
+}
+
+func (c *converter) errorf(path, format string, args ...interface{}) {
+	c.errs = append(c.errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *converter) typeDef(name string) schema.TypeDef {
+	path := componentPath(name)
+	s := c.flatten(path, c.schemas[name])
+	// Follow the references of the schemas that are aliases of other
+	// component schemas.
+	seen := map[string]bool{name: true}
+	for s != nil && s.Ref != "" {
+		target, ok := c.refName(path, s.Ref)
+		if !ok {
+			return schema.TypeDef{Name: name, Atom: untypedAtomic()}
+		}
+		if seen[target] {
+			c.errorf(path, "circular reference to %q", s.Ref)
+			return schema.TypeDef{Name: name, Atom: untypedAtomic()}
+		}
+		seen[target] = true
+		path = componentPath(target)
+		s = c.flatten(path, c.schemas[target])
+	}
+	if s == nil || isFreeForm(s) {
+		return schema.TypeDef{Name: name, Atom: untypedAtomic()}
+	}
+	return schema.TypeDef{Name: name, Atom: c.atom(path, s)}
+}
+
+// typeRef returns the reference to the type of s, which is a named type
+// if s is a reference to a component schema.
+func (c *converter) typeRef(path string, s *Schema) schema.TypeRef {
+	s = c.flatten(path, s)
+	switch {
+	case s == nil || isFreeForm(s):
+		return namedType(UntypedAtomicName)
+	case s.Ref != "":
+		name, ok := c.refName(path, s.Ref)
+		if !ok {
+			return namedType(UntypedAtomicName)
+		}
+		if constraints(s) != nil {
+			c.errorf(path, "constraints next to a reference are not supported")
+		}
+		tr := namedType(name)
+		tr.ElementRelationship = c.relationship(path, s)
+		return tr
+	}
+	return schema.TypeRef{Inlined: c.atom(path, s)}
+}
+
+// relationship returns the element relationship that the list or map
+// type of a reference overrides, if any.
+func (c *converter) relationship(path string, s *Schema) *schema.ElementRelationship {
+	var er schema.ElementRelationship
+	switch {
+	case s.ListType == "atomic", s.MapType == "atomic":
+		er = schema.Atomic
+	case s.ListType == "set", s.ListType == "map":
+		er = schema.Associative
+	case s.MapType == "granular":
+		er = schema.Separable
+	case s.ListType == "" && s.MapType == "":
+		return nil
+	default:
+		c.errorf(path, "unknown x-kubernetes-list-type %q or x-kubernetes-map-type %q", s.ListType, s.MapType)
+		return nil
+	}
+	return &er
+}
+
+func (c *converter) atom(path string, s *Schema) schema.Atom {
+	a := c.shape(path, s)
+	a.Constraints = constraints(s)
+	return a
+}
+
+// shape returns the atom of s, without its constraints.
+func (c *converter) shape(path string, s *Schema) schema.Atom {
+	if s.IntOrString || s.Format == "int-or-string" {
+		return scalar(schema.Untyped)
+	}
+	switch {
+	case len(s.OneOf) > 0:
+		c.errorf(path, "oneOf is not supported")
+		return untypedAtomic()
+	case len(s.AnyOf) > 0:
+		c.errorf(path, "anyOf is not supported, except with x-kubernetes-int-or-string")
+		return untypedAtomic()
+	case s.Not != nil:
+		c.errorf(path, "not is not supported")
+		return untypedAtomic()
+	}
+	switch s.Type {
+	case "object":
+		return c.mapAtom(path, s)
+	case "array":
+		return c.listAtom(path, s)
+	case "string":
+		return formatted(scalar(schema.String), s.Format)
+	case "integer":
+		return formatted(scalar(schema.Integer), s.Format)
+	case "number":
+		if s.Format == "float" || s.Format == "double" {
+			return scalar(schema.Float)
+		}
+		return formatted(scalar(schema.Numeric), s.Format)
+	case "boolean":
+		return formatted(scalar(schema.Boolean), s.Format)
+	case "":
+		switch {
+		case len(s.Properties) > 0 || s.AdditionalProperties != nil:
+			return c.mapAtom(path, s)
+		case s.Items != nil:
+			return c.listAtom(path, s)
+		case s.PreserveUnknownFields:
+			c.usesDeduced = true
+			return untypedDeduced()
+		}
+		return untypedAtomic()
+	}
+	c.errorf(path, "unknown type %q", s.Type)
+	return untypedAtomic()
+}
+
+func (c *converter) mapAtom(path string, s *Schema) schema.Atom {
+	m := &schema.Map{}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop := s.Properties[name]
+		sf := schema.StructField{
+			Name: name,
+			Type: c.typeRef(path+".properties."+name, prop),
+		}
+		if prop != nil {
+			sf.Default = prop.Default
+		}
+		m.Fields = append(m.Fields, sf)
+	}
+
+	additional := s.AdditionalProperties
+	switch {
+	case additional != nil && additional.Schema != nil:
+		m.ElementType = c.typeRef(path+".additionalProperties", additional.Schema)
+	case s.PreserveUnknownFields,
+		additional != nil && additional.Allows,
+		additional == nil && len(s.Properties) == 0:
+		c.usesDeduced = true
+		m.ElementType = schema.TypeRef{Inlined: untypedDeduced()}
+	}
+
+	for _, u := range s.Unions {
+		m.Unions = append(m.Unions, union(u))
+	}
+
+	switch s.MapType {
+	case "", "granular":
+	case "atomic":
+		m.ElementRelationship = schema.Atomic
+	default:
+		c.errorf(path, "unknown x-kubernetes-map-type %q", s.MapType)
+	}
+	return schema.Atom{Map: m}
+}
+
+func (c *converter) listAtom(path string, s *Schema) schema.Atom {
+	if s.Items == nil {
+		c.errorf(path, "array without items")
+	}
+	l := &schema.List{
+		ElementType:         c.typeRef(path+".items", s.Items),
+		ElementRelationship: schema.Atomic,
+	}
+	switch s.ListType {
+	case "atomic":
+	case "set":
+		l.ElementRelationship = schema.Associative
+	case "map":
+		l.ElementRelationship = schema.Associative
+		l.Keys = s.ListMapKeys
+		if len(l.Keys) == 0 {
+			c.errorf(path, "x-kubernetes-list-type map without x-kubernetes-list-map-keys")
+		}
+	case "":
+		// Lists that predate the list types only describe how strategic
+		// merge patches merge them.
+		if strategies := strings.Split(s.PatchStrategy, ","); contains(strategies, "merge") {
+			l.ElementRelationship = schema.Associative
+			if s.PatchMergeKey != "" {
+				l.Keys = []string{s.PatchMergeKey}
+			}
+		}
+	default:
+		c.errorf(path, "unknown x-kubernetes-list-type %q", s.ListType)
+	}
+	return schema.Atom{List: l}
+}
+
+// flatten returns s with the schemas of its allOf merged into it, or s
+// itself if it has no allOf. A single reference with no other types, the
+// usual way of describing or defaulting a reference, or of overriding its
+// list or map type, is kept as is.
+func (c *converter) flatten(path string, s *Schema) *Schema {
+	if s == nil || len(s.AllOf) == 0 {
+		return s
+	}
+	out := *s
+	out.AllOf = nil
+	if len(s.AllOf) == 1 && s.AllOf[0] != nil && s.AllOf[0].Ref != "" && !hasType(&out) {
+		out.Ref = s.AllOf[0].Ref
+		return &out
+	}
+	for i, sub := range s.AllOf {
+		subPath := fmt.Sprintf("%s.allOf[%d]", path, i)
+		if sub != nil && sub.Ref != "" {
+			name, ok := c.refName(subPath, sub.Ref)
+			if !ok {
+				continue
+			}
+			sub = c.schemas[name]
+		}
+		if sub = c.flatten(subPath, sub); sub == nil {
+			continue
+		}
+		if sub.Ref != "" {
+			c.errorf(subPath, "allOf with a reference to a reference is not supported")
+			continue
+		}
+		c.merge(subPath, &out, sub)
+	}
+	return &out
+}
+
+// merge merges the schema src of an allOf into dst.
+func (c *converter) merge(path string, dst, src *Schema) {
+	switch {
+	case dst.Type == "":
+		dst.Type = src.Type
+	case src.Type != "" && src.Type != dst.Type:
+		c.errorf(path, "allOf with types %q and %q", dst.Type, src.Type)
+	}
+	if len(src.Properties) > 0 {
+		props := make(map[string]*Schema, len(dst.Properties)+len(src.Properties))
+		for name, prop := range dst.Properties {
+			props[name] = prop
+		}
+		for name, prop := range src.Properties {
+			if existing, ok := props[name]; ok && !reflect.DeepEqual(existing, prop) {
+				c.errorf(path, "allOf with different schemas for property %q", name)
+				continue
+			}
+			props[name] = prop
+		}
+		dst.Properties = props
+	}
+	dst.Required = append(append([]string{}, dst.Required...), src.Required...)
+	if dst.AdditionalProperties == nil {
+		dst.AdditionalProperties = src.AdditionalProperties
+	}
+	if dst.Items == nil {
+		dst.Items = src.Items
+	}
+	if dst.Format == "" {
+		dst.Format = src.Format
+	}
+	if dst.Default == nil {
+		dst.Default = src.Default
+	}
+	if dst.Enum == nil {
+		dst.Enum = src.Enum
+	}
+	if dst.Minimum == nil {
+		dst.Minimum = src.Minimum
+	}
+	if dst.Maximum == nil {
+		dst.Maximum = src.Maximum
+	}
+	if dst.Pattern == "" {
+		dst.Pattern = src.Pattern
+	}
+	if dst.MinLength == nil {
+		dst.MinLength = src.MinLength
+	}
+	if dst.MaxLength == nil {
+		dst.MaxLength = src.MaxLength
+	}
+	if dst.MaxProperties == nil {
+		dst.MaxProperties = src.MaxProperties
+	}
+	if dst.MinItems == nil {
+		dst.MinItems = src.MinItems
+	}
+	if dst.MaxItems == nil {
+		dst.MaxItems = src.MaxItems
+	}
+	dst.OneOf = append(append([]*Schema{}, dst.OneOf...), src.OneOf...)
+	dst.AnyOf = append(append([]*Schema{}, dst.AnyOf...), src.AnyOf...)
+	if dst.Not == nil {
+		dst.Not = src.Not
+	}
+	if dst.ListType == "" {
+		dst.ListType, dst.ListMapKeys = src.ListType, src.ListMapKeys
+	}
+	if dst.MapType == "" {
+		dst.MapType = src.MapType
+	}
+	dst.PreserveUnknownFields = dst.PreserveUnknownFields || src.PreserveUnknownFields
+	dst.IntOrString = dst.IntOrString || src.IntOrString
+	if dst.PatchStrategy == "" {
+		dst.PatchStrategy, dst.PatchMergeKey = src.PatchStrategy, src.PatchMergeKey
+	}
+	dst.Unions = append(append([]Union{}, dst.Unions...), src.Unions...)
+}
+
+// union returns the schema union of u, with its fields sorted by name.
+func union(u Union) schema.Union {
+	out := schema.Union{}
+	if u.Discriminator != "" {
+		discriminator := u.Discriminator
+		out.Discriminator = &discriminator
+	}
+	names := make([]string, 0, len(u.FieldsToDiscriminateBy))
+	for name := range u.FieldsToDiscriminateBy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.Fields = append(out.Fields, schema.UnionField{
+			FieldName:          name,
+			DiscriminatorValue: u.FieldsToDiscriminateBy[name],
+		})
+	}
+	return out
+}
+
+// refName returns the name of the component schema that ref refers to.
+func (c *converter) refName(path, ref string) (string, bool) {
+	if !strings.HasPrefix(ref, componentsPrefix) {
+		c.errorf(path, "reference %q is not to a component schema", ref)
+		return "", false
+	}
+	name := strings.TrimPrefix(ref, componentsPrefix)
+	name = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+	if _, ok := c.schemas[name]; !ok {
+		c.errorf(path, "reference to unknown schema %q", ref)
+		return "", false
+	}
+	return name, true
+}
+
+// isFreeForm returns true if any value is allowed by s.
+func isFreeForm(s *Schema) bool {
+	return s.Ref == "" && !hasType(s) && !s.PreserveUnknownFields && !s.IntOrString &&
+		len(s.OneOf) == 0 && len(s.AnyOf) == 0 && s.Not == nil
+}
+
+// hasType returns true if s restricts the type of its values.
+func hasType(s *Schema) bool {
+	return s.Type != "" || len(s.Properties) > 0 || s.AdditionalProperties != nil || s.Items != nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if strings.TrimSpace(item) == s {
+			return true
+		}
+	}
+	return false
+}
+
+func namedType(name string) schema.TypeRef {
+	return schema.TypeRef{NamedType: &name}
+}
+
+func scalar(s schema.Scalar) schema.Atom {
+	return schema.Atom{Scalar: &s}
+}
+
+// formatted returns a with the format f.
+func formatted(a schema.Atom, f string) schema.Atom {
+	a.Format = schema.Format(f)
+	return a
+}
+
+// constraints returns the constraints of s, or nil if it has none.
+func constraints(s *Schema) *schema.Constraints {
+	c := &schema.Constraints{
+		Enum:          s.Enum,
+		Minimum:       s.Minimum,
+		Maximum:       s.Maximum,
+		Pattern:       s.Pattern,
+		MinLength:     s.MinLength,
+		MaxLength:     s.MaxLength,
+		Required:      s.Required,
+		MaxProperties: s.MaxProperties,
+		MinItems:      s.MinItems,
+		MaxItems:      s.MaxItems,
+	}
+	if len(c.Enum) == 0 {
+		c.Enum = nil
+	}
+	if len(c.Required) == 0 {
+		c.Required = nil
+	}
+	if c.Enum == nil && c.Minimum == nil && c.Maximum == nil && c.Pattern == "" &&
+		c.MinLength == nil && c.MaxLength == nil && c.Required == nil &&
+		c.MaxProperties == nil && c.MinItems == nil && c.MaxItems == nil {
+		return nil
+	}
+	return c
+}
+
+// untypedAtomic returns the atom of UntypedAtomicName.
+func untypedAtomic() schema.Atom {
+	a := scalar(schema.Untyped)
+	a.List = &schema.List{
+		ElementType:         namedType(UntypedAtomicName),
+		ElementRelationship: schema.Atomic,
+	}
+	a.Map = &schema.Map{
+		ElementType:         namedType(UntypedAtomicName),
+		ElementRelationship: schema.Atomic,
+	}
+	return a
+}
+
+// untypedDeduced returns the atom of UntypedDeducedName.
+func untypedDeduced() schema.Atom {
+	a := scalar(schema.Untyped)
+	a.List = &schema.List{
+		ElementType:         namedType(UntypedAtomicName),
+		ElementRelationship: schema.Atomic,
+	}
+	a.Map = &schema.Map{
+		ElementType:         namedType(UntypedDeducedName),
+		ElementRelationship: schema.Separable,
+	}
+	return a
+}
@@ -0,0 +1,493 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/openapi"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	yaml "sigs.k8s.io/yaml/goyaml.v2"
+)
+
+const untypedAtomic = `
+- name: __untyped_atomic_
+  scalar: untyped
+  list:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic
+  map:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic`
+
+const untypedDeduced = `
+- name: __untyped_deduced_
+  scalar: untyped
+  list:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic
+  map:
+    elementType:
+      namedType: __untyped_deduced_
+    elementRelationship: separable`
+
+func TestToSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		expected string
+	}{{
+		name: "scalars",
+		document: `{"components": {"schemas": {
+			"str": {"type": "string"},
+			"int": {"type": "integer", "format": "int32"},
+			"num": {"type": "number"},
+			"bool": {"type": "boolean"},
+			"intOrString": {"x-kubernetes-int-or-string": true, "anyOf": [{"type": "integer"}, {"type": "string"}]},
+			"intOrStringFormat": {"type": "string", "format": "int-or-string"},
+			"any": {}
+		}}}`,
+		expected: `types:
+- name: any
+  scalar: untyped
+  list:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic
+  map:
+    elementType:
+      namedType: __untyped_atomic_
+    elementRelationship: atomic
+- name: bool
+  scalar: boolean
+- name: int
+  scalar: integer
+  format: int32
+- name: intOrString
+  scalar: untyped
+- name: intOrStringFormat
+  scalar: untyped
+- name: num
+  scalar: numeric
+- name: str
+  scalar: string` + untypedAtomic,
+	}, {
+		name: "formats and constraints",
+		document: `{"components": {"schemas": {"root": {
+			"type": "object",
+			"required": ["name"],
+			"maxProperties": 3,
+			"properties": {
+				"name": {"type": "string", "pattern": "^[a-z]+$", "minLength": 1, "maxLength": 8},
+				"mode": {"type": "string", "enum": ["a", "b"]},
+				"ratio": {"type": "number", "format": "double", "minimum": 0, "maximum": 1},
+				"uid": {"type": "string", "format": "uuid"},
+				"items": {"type": "array", "items": {"type": "integer"}, "minItems": 1, "maxItems": 2}
+			}
+		}}}}`,
+		expected: `types:
+- name: root
+  map:
+    fields:
+    - name: items
+      type:
+        list:
+          elementType:
+            scalar: integer
+          elementRelationship: atomic
+        constraints:
+          minItems: 1
+          maxItems: 2
+    - name: mode
+      type:
+        scalar: string
+        constraints:
+          enum: [a, b]
+    - name: name
+      type:
+        scalar: string
+        constraints:
+          pattern: ^[a-z]+$
+          minLength: 1
+          maxLength: 8
+    - name: ratio
+      type:
+        scalar: float
+        constraints:
+          minimum: 0
+          maximum: 1
+    - name: uid
+      type:
+        scalar: string
+        format: uuid
+  constraints:
+    required: [name]
+    maxProperties: 3` + untypedAtomic,
+	}, {
+		name: "lists",
+		document: `{"components": {"schemas": {"root": {"type": "object", "properties": {
+			"none": {"type": "array", "items": {"type": "string"}},
+			"atomic": {"type": "array", "items": {"type": "string"}, "x-kubernetes-list-type": "atomic"},
+			"set": {"type": "array", "items": {"type": "string"}, "x-kubernetes-list-type": "set"},
+			"map": {"type": "array", "items": {"$ref": "#/components/schemas/item"}, "x-kubernetes-list-type": "map", "x-kubernetes-list-map-keys": ["name", "port"]},
+			"strategic": {"type": "array", "items": {"$ref": "#/components/schemas/item"}, "x-kubernetes-patch-strategy": "merge,retainKeys", "x-kubernetes-patch-merge-key": "name"},
+			"strategicSet": {"type": "array", "items": {"type": "string"}, "x-kubernetes-patch-strategy": "merge"}
+		}}, "item": {"type": "object", "properties": {"name": {"type": "string"}, "port": {"type": "integer"}}}}}}`,
+		expected: `types:
+- name: item
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+    - name: port
+      type:
+        scalar: integer
+- name: root
+  map:
+    fields:
+    - name: atomic
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: map
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["name", "port"]
+    - name: none
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: set
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: associative
+    - name: strategic
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["name"]
+    - name: strategicSet
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: associative` + untypedAtomic,
+	}, {
+		name: "maps",
+		document: `{"components": {"schemas": {"root": {"type": "object", "properties": {
+			"strings": {"type": "object", "additionalProperties": {"type": "string"}},
+			"atomic": {"type": "object", "additionalProperties": {"type": "string"}, "x-kubernetes-map-type": "atomic"},
+			"granular": {"type": "object", "properties": {"a": {"type": "string", "default": "a"}}, "x-kubernetes-map-type": "granular"},
+			"preserved": {"type": "object", "properties": {"a": {"type": "string"}}, "x-kubernetes-preserve-unknown-fields": true},
+			"free": {"type": "object"},
+			"additional": {"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": true},
+			"any": {"x-kubernetes-preserve-unknown-fields": true}
+		}}}}}`,
+		expected: `types:
+- name: root
+  map:
+    fields:
+    - name: additional
+      type:
+        map:
+          fields:
+          - name: a
+            type:
+              scalar: string
+          elementType:
+            scalar: untyped
+            list:
+              elementType:
+                namedType: __untyped_atomic_
+              elementRelationship: atomic
+            map:
+              elementType:
+                namedType: __untyped_deduced_
+              elementRelationship: separable
+    - name: any
+      type:
+        scalar: untyped
+        list:
+          elementType:
+            namedType: __untyped_atomic_
+          elementRelationship: atomic
+        map:
+          elementType:
+            namedType: __untyped_deduced_
+          elementRelationship: separable
+    - name: atomic
+      type:
+        map:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: free
+      type:
+        map:
+          elementType:
+            scalar: untyped
+            list:
+              elementType:
+                namedType: __untyped_atomic_
+              elementRelationship: atomic
+            map:
+              elementType:
+                namedType: __untyped_deduced_
+              elementRelationship: separable
+    - name: granular
+      type:
+        map:
+          fields:
+          - name: a
+            type:
+              scalar: string
+            default: a
+    - name: preserved
+      type:
+        map:
+          fields:
+          - name: a
+            type:
+              scalar: string
+          elementType:
+            scalar: untyped
+            list:
+              elementType:
+                namedType: __untyped_atomic_
+              elementRelationship: atomic
+            map:
+              elementType:
+                namedType: __untyped_deduced_
+              elementRelationship: separable
+    - name: strings
+      type:
+        map:
+          elementType:
+            scalar: string` + untypedAtomic + untypedDeduced,
+	}, {
+		name: "references",
+		document: `{"components": {"schemas": {
+			"root": {"type": "object", "properties": {
+				"ref": {"$ref": "#/components/schemas/child"},
+				"allOf": {"allOf": [{"$ref": "#/components/schemas/child"}], "default": {}},
+				"merged": {"allOf": [{"$ref": "#/components/schemas/child"}, {"properties": {"b": {"type": "string"}}}]},
+				"escaped": {"$ref": "#/components/schemas/a~1b"}
+			}},
+			"child": {"type": "object", "properties": {"a": {"type": "string"}}},
+			"alias": {"$ref": "#/components/schemas/child"},
+			"a/b": {"type": "string"}
+		}}}`,
+		expected: `types:
+- name: a/b
+  scalar: string
+- name: alias
+  map:
+    fields:
+    - name: a
+      type:
+        scalar: string
+- name: child
+  map:
+    fields:
+    - name: a
+      type:
+        scalar: string
+- name: root
+  map:
+    fields:
+    - name: allOf
+      type:
+        namedType: child
+      default: {}
+    - name: escaped
+      type:
+        namedType: a/b
+    - name: merged
+      type:
+        map:
+          fields:
+          - name: a
+            type:
+              scalar: string
+          - name: b
+            type:
+              scalar: string
+    - name: ref
+      type:
+        namedType: child` + untypedAtomic,
+	}, {
+		name: "overrides and unions",
+		document: `{"components": {"schemas": {
+			"root": {"type": "object", "properties": {
+				"atomic": {"allOf": [{"$ref": "#/components/schemas/child"}], "x-kubernetes-map-type": "atomic"},
+				"set": {"allOf": [{"$ref": "#/components/schemas/list"}], "x-kubernetes-list-type": "set"},
+				"type": {"type": "string"}
+			}, "x-kubernetes-unions": [{"discriminator": "type", "fields-to-discriminateBy": {"set": "Set", "atomic": "Atomic"}}]},
+			"child": {"type": "object", "properties": {"a": {"type": "string"}}},
+			"list": {"type": "array", "items": {"type": "string"}}
+		}}}`,
+		expected: `types:
+- name: child
+  map:
+    fields:
+    - name: a
+      type:
+        scalar: string
+- name: list
+  list:
+    elementType:
+      scalar: string
+    elementRelationship: atomic
+- name: root
+  map:
+    fields:
+    - name: atomic
+      type:
+        namedType: child
+        elementRelationship: atomic
+    - name: set
+      type:
+        namedType: list
+        elementRelationship: associative
+    - name: type
+      type:
+        scalar: string
+    unions:
+    - discriminator: type
+      fields:
+      - fieldName: atomic
+        discriminatorValue: Atomic
+      - fieldName: set
+        discriminatorValue: Set` + untypedAtomic,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := openapi.Parse([]byte(tt.document))
+			if err != nil {
+				t.Fatalf("failed to parse document: %v", err)
+			}
+			got, err := openapi.ToSchema(doc)
+			if err != nil {
+				t.Fatalf("failed to convert document: %v", err)
+			}
+			var expected schema.Schema
+			if err := yaml.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatalf("failed to parse expected schema: %v", err)
+			}
+			// Defaults are compared in YAML, since they are decoded from
+			// JSON in the document but from YAML in the expected schema.
+			gotYAML, err := yaml.Marshal(got)
+			if err != nil {
+				t.Fatalf("failed to marshal schema: %v", err)
+			}
+			expectedYAML, err := yaml.Marshal(&expected)
+			if err != nil {
+				t.Fatalf("failed to marshal expected schema: %v", err)
+			}
+			if string(gotYAML) != string(expectedYAML) {
+				t.Errorf("expected:\n%s\ngot:\n%s", expectedYAML, gotYAML)
+			}
+		})
+	}
+}
+
+func TestToSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		errors   []string
+	}{{
+		name:     "oneOf",
+		document: `{"components": {"schemas": {"root": {"oneOf": [{"type": "string"}, {"type": "integer"}]}}}}`,
+		errors:   []string{"components.schemas.root: oneOf is not supported"},
+	}, {
+		name:     "anyOf",
+		document: `{"components": {"schemas": {"root": {"type": "object", "properties": {"a": {"anyOf": [{"type": "string"}]}}}}}}`,
+		errors:   []string{"components.schemas.root.properties.a: anyOf is not supported"},
+	}, {
+		name:     "unknown reference",
+		document: `{"components": {"schemas": {"root": {"type": "array", "items": {"$ref": "#/components/schemas/missing"}}}}}`,
+		errors:   []string{`components.schemas.root.items: reference to unknown schema "#/components/schemas/missing"`},
+	}, {
+		name:     "external reference",
+		document: `{"components": {"schemas": {"root": {"$ref": "other.yaml#/root"}}}}`,
+		errors:   []string{`components.schemas.root: reference "other.yaml#/root" is not to a component schema`},
+	}, {
+		name:     "circular reference",
+		document: `{"components": {"schemas": {"a": {"$ref": "#/components/schemas/b"}, "b": {"$ref": "#/components/schemas/a"}}}}`,
+		errors: []string{
+			`components.schemas.b: circular reference to "#/components/schemas/a"`,
+			`components.schemas.a: circular reference to "#/components/schemas/b"`,
+		},
+	}, {
+		name:     "map list without keys",
+		document: `{"components": {"schemas": {"root": {"type": "array", "items": {"type": "object"}, "x-kubernetes-list-type": "map"}}}}`,
+		errors:   []string{"components.schemas.root: x-kubernetes-list-type map without x-kubernetes-list-map-keys"},
+	}, {
+		name:     "unknown map type",
+		document: `{"components": {"schemas": {"root": {"type": "object", "x-kubernetes-map-type": "merged"}}}}`,
+		errors:   []string{`components.schemas.root: unknown x-kubernetes-map-type "merged"`},
+	}, {
+		name:     "conflicting allOf",
+		document: `{"components": {"schemas": {"root": {"type": "string", "allOf": [{"type": "integer"}, {"type": "string"}]}}}}`,
+		errors:   []string{`components.schemas.root.allOf[0]: allOf with types "string" and "integer"`},
+	}, {
+		name:     "constraints next to a reference",
+		document: `{"components": {"schemas": {"a": {"type": "string"}, "root": {"type": "object", "properties": {"b": {"allOf": [{"$ref": "#/components/schemas/a"}], "maxLength": 3}}}}}}`,
+		errors:   []string{"components.schemas.root.properties.b: constraints next to a reference are not supported"},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := openapi.Parse([]byte(tt.document))
+			if err != nil {
+				t.Fatalf("failed to parse document: %v", err)
+			}
+			s, err := openapi.ToSchema(doc)
+			if s == nil {
+				t.Fatalf("expected a schema along with the errors")
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v, got none", tt.errors)
+			}
+			for _, expected := range tt.errors {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error %q, got:\n%v", expected, err)
+				}
+			}
+		})
+	}
+}
@@ -24,5 +24,5 @@ limitations under the License.
 //
 // This schema was derived by observing the API objects used by Kubernetes, and
 // formalizing a model which allows certain operations ("apply") to be more
-// well defined. It is currently missing one feature: one-of ("unions").
+// well defined.
 package schema
@@ -17,6 +17,7 @@ limitations under the License.
 package schema
 
 import (
+	"regexp"
 	"sync"
 )
 
@@ -70,21 +71,104 @@ type Atom struct {
 	*Scalar `yaml:"scalar,omitempty"`
 	*List   `yaml:"list,omitempty"`
 	*Map    `yaml:"map,omitempty"`
+
+	// Format optionally restricts the values of the Scalar further, e.g.
+	// to timestamps or to 32-bit integers. It has no effect on lists and
+	// maps.
+	Format Format `yaml:"format,omitempty"`
+
+	// Constraints optionally restricts the values of the type further,
+	// e.g. to a range of numbers or to lists of a maximum length.
+	Constraints *Constraints `yaml:"constraints,omitempty"`
+}
+
+// Constraints are the restrictions on the values of a type, on top of its
+// shape. Each constraint only applies to the values it is relevant for,
+// e.g. Minimum only applies to numbers, and is ignored for other values.
+// Unset constraints don't restrict the values.
+//
+// Constraints are considered immutable.
+type Constraints struct {
+	// Enum is the list of the allowed values of scalars.
+	Enum []interface{} `yaml:"enum,omitempty"`
+
+	// Minimum and Maximum are the inclusive bounds of numbers.
+	Minimum *float64 `yaml:"minimum,omitempty"`
+	Maximum *float64 `yaml:"maximum,omitempty"`
+
+	// Pattern is a regular expression, in the syntax of the regexp
+	// package, that strings must match.
+	Pattern string `yaml:"pattern,omitempty"`
+	// MinLength and MaxLength are the bounds of the length of strings, in
+	// characters.
+	MinLength *int64 `yaml:"minLength,omitempty"`
+	MaxLength *int64 `yaml:"maxLength,omitempty"`
+
+	// Required is the list of the fields that maps must have.
+	Required []string `yaml:"required,omitempty"`
+	// MaxProperties is the maximum number of fields of maps.
+	MaxProperties *int64 `yaml:"maxProperties,omitempty"`
+
+	// MinItems and MaxItems are the bounds of the number of items of
+	// lists.
+	MinItems *int64 `yaml:"minItems,omitempty"`
+	MaxItems *int64 `yaml:"maxItems,omitempty"`
+
+	once    sync.Once
+	pattern *regexp.Regexp
+	err     error
+}
+
+// PatternRegexp returns the compiled Pattern, or nil if there is no
+// Pattern. An error is returned if the Pattern is invalid.
+func (c *Constraints) PatternRegexp() (*regexp.Regexp, error) {
+	c.once.Do(func() {
+		if c.Pattern != "" {
+			c.pattern, c.err = regexp.Compile(c.Pattern)
+		}
+	})
+	return c.pattern, c.err
 }
 
 // Scalar (AKA "primitive") represents a type which has a single value which is
 // either numeric, string, or boolean, or untyped for any of them.
 //
-// TODO: split numeric into float/int? Something even more fine-grained?
+// Numeric values can be either integers or floats. Integer only allows
+// integers, and floats without a fractional part (which is how integers
+// are decoded from JSON), while Float allows both integers and floats.
 type Scalar string
 
 const (
 	Numeric = Scalar("numeric")
+	Integer = Scalar("integer")
+	Float   = Scalar("float")
 	String  = Scalar("string")
 	Boolean = Scalar("boolean")
 	Untyped = Scalar("untyped")
 )
 
+// Format is the format of the values of a scalar type. Unknown formats
+// are allowed, and are not enforced.
+type Format string
+
+const (
+	// FormatDateTime is for strings that are RFC 3339 timestamps, e.g.
+	// "2006-01-02T15:04:05Z".
+	FormatDateTime = Format("date-time")
+	// FormatInt32 is for integers that fit in 32 bits.
+	FormatInt32 = Format("int32")
+	// FormatInt64 is for integers that fit in 64 bits.
+	FormatInt64 = Format("int64")
+	// FormatByte is for strings that are base64 encoded bytes.
+	FormatByte = Format("byte")
+	// FormatQuantity is for resource quantities, e.g. "100m" or "1Gi",
+	// which are strings or numbers.
+	FormatQuantity = Format("quantity")
+	// FormatDuration is for strings that are durations as parsed by
+	// time.ParseDuration, e.g. "1h30m".
+	FormatDuration = Format("duration")
+)
+
 // ElementRelationship is an enum of the different possible relationships
 // between the elements of container types (maps, lists).
 type ElementRelationship string
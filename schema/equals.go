@@ -83,6 +83,12 @@ func (a *Atom) Equals(b *Atom) bool {
 	if (a.Map == nil) != (b.Map == nil) {
 		return false
 	}
+	if a.Format != b.Format {
+		return false
+	}
+	if !a.Constraints.Equals(b.Constraints) {
+		return false
+	}
 	switch {
 	case a.Scalar != nil:
 		return *a.Scalar == *b.Scalar
@@ -94,6 +100,51 @@ func (a *Atom) Equals(b *Atom) bool {
 	return true
 }
 
+// Equals returns true iff the two Constraints are equal.
+func (a *Constraints) Equals(b *Constraints) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if !reflect.DeepEqual(a.Enum, b.Enum) {
+		return false
+	}
+	if !float64PtrEquals(a.Minimum, b.Minimum) || !float64PtrEquals(a.Maximum, b.Maximum) {
+		return false
+	}
+	if a.Pattern != b.Pattern {
+		return false
+	}
+	if !int64PtrEquals(a.MinLength, b.MinLength) || !int64PtrEquals(a.MaxLength, b.MaxLength) {
+		return false
+	}
+	if len(a.Required) != len(b.Required) {
+		return false
+	}
+	for i := range a.Required {
+		if a.Required[i] != b.Required[i] {
+			return false
+		}
+	}
+	if !int64PtrEquals(a.MaxProperties, b.MaxProperties) {
+		return false
+	}
+	return int64PtrEquals(a.MinItems, b.MinItems) && int64PtrEquals(a.MaxItems, b.MaxItems)
+}
+
+func float64PtrEquals(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func int64PtrEquals(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
 // Equals returns true iff the two Maps are equal.
 func (a *Map) Equals(b *Map) bool {
 	if a == nil || b == nil {
@@ -59,6 +59,13 @@ func (Atom) Generate(rand *rand.Rand, size int) reflect.Value {
 	return reflect.ValueOf(a)
 }
 
+func (*Constraints) Generate(rand *rand.Rand, size int) reflect.Value {
+	c := Constraints{}
+	f := randfill.New().RandSource(rand).MaxDepth(4).Funcs(fuzzInterface)
+	f.Fill(&c)
+	return reflect.ValueOf(&c)
+}
+
 func (StructField) Generate(rand *rand.Rand, size int) reflect.Value {
 	a := StructField{}
 	f := randfill.New().RandSource(rand).MaxDepth(4).Funcs(fuzzInterface)
@@ -107,8 +114,27 @@ func TestEquals(t *testing.T) {
 			y.Scalar = x.Scalar
 			y.List = x.List
 			y.Map = x.Map
+			y.Format = x.Format
+			y.Constraints = x.Constraints
 			return x.Equals(&y) == reflect.DeepEqual(x, y)
 		},
+		func(x *Constraints) bool {
+			if !x.Equals(x) {
+				return false
+			}
+			var y Constraints
+			y.Enum = x.Enum
+			y.Minimum = x.Minimum
+			y.Maximum = x.Maximum
+			y.Pattern = x.Pattern
+			y.MinLength = x.MinLength
+			y.MaxLength = x.MaxLength
+			y.Required = x.Required
+			y.MaxProperties = x.MaxProperties
+			y.MinItems = x.MinItems
+			y.MaxItems = x.MaxItems
+			return x.Equals(&y) == reflect.DeepEqual(x, &y)
+		},
 		func(x *Map) bool {
 			if !x.Equals(x) {
 				return false
@@ -39,6 +39,12 @@ var SchemaSchemaYAML = `types:
     - name: scalar
       type:
         scalar: string
+    - name: format
+      type:
+        scalar: string
+    - name: constraints
+      type:
+        namedType: constraints
     - name: map
       type:
         namedType: map
@@ -57,6 +63,12 @@ var SchemaSchemaYAML = `types:
     - name: scalar
       type:
         scalar: string
+    - name: format
+      type:
+        scalar: string
+    - name: constraints
+      type:
+        namedType: constraints
     - name: map
       type:
         namedType: map
@@ -93,6 +105,45 @@ var SchemaSchemaYAML = `types:
     - name: elementRelationship
       type:
         scalar: string
+- name: constraints
+  map:
+    fields:
+    - name: enum
+      type:
+        list:
+          elementType:
+            namedType: __untyped_atomic_
+          elementRelationship: atomic
+    - name: minimum
+      type:
+        scalar: numeric
+    - name: maximum
+      type:
+        scalar: numeric
+    - name: pattern
+      type:
+        scalar: string
+    - name: minLength
+      type:
+        scalar: integer
+    - name: maxLength
+      type:
+        scalar: integer
+    - name: required
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: maxProperties
+      type:
+        scalar: integer
+    - name: minItems
+      type:
+        scalar: integer
+    - name: maxItems
+      type:
+        scalar: integer
 - name: unionField
   map:
     fields:
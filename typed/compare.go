@@ -18,6 +18,7 @@ package typed
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
@@ -82,12 +83,98 @@ func (c *Comparison) FilterFields(filter fieldpath.Filter) *Comparison {
 	return c
 }
 
+// ScalarEqualityFunc returns true if the two scalar values, of the same
+// type, are semantically equal.
+type ScalarEqualityFunc func(lhs, rhs value.Value) bool
+
+// CompareOption is an option of Compare.
+type CompareOption func(*compareOptions)
+
+type compareOptions struct {
+	scalarEquality map[schema.Scalar]ScalarEqualityFunc
+	typeEquality   map[string]ScalarEqualityFunc
+	defaults       bool
+}
+
+// WithScalarEquality configures Compare to use eq to tell if the values of
+// the scalar fields of type scalar were modified, instead of the default
+// value.Equals.
+func WithScalarEquality(scalar schema.Scalar, eq ScalarEqualityFunc) CompareOption {
+	return func(opts *compareOptions) {
+		if opts.scalarEquality == nil {
+			opts.scalarEquality = map[schema.Scalar]ScalarEqualityFunc{}
+		}
+		opts.scalarEquality[scalar] = eq
+	}
+}
+
+// WithNamedTypeEquality configures Compare to use eq to tell if the values
+// of the fields of the scalar type named typeName, such as resource
+// quantities, were modified. It takes precedence over WithScalarEquality.
+func WithNamedTypeEquality(typeName string, eq ScalarEqualityFunc) CompareOption {
+	return func(opts *compareOptions) {
+		if opts.typeEquality == nil {
+			opts.typeEquality = map[string]ScalarEqualityFunc{}
+		}
+		opts.typeEquality[typeName] = eq
+	}
+}
+
+// WithNumericEquality configures Compare to use NumericEquals for numeric,
+// integer and float fields.
+func WithNumericEquality() CompareOption {
+	return func(opts *compareOptions) {
+		for _, scalar := range []schema.Scalar{schema.Numeric, schema.Integer, schema.Float} {
+			WithScalarEquality(scalar, NumericEquals)(opts)
+		}
+	}
+}
+
+// WithDefaultEquality configures Compare to consider the fields that are
+// absent from one side, and set to the Default of their StructField on the
+// other, as unchanged rather than added or removed. Likewise, the fields
+// within a default are compared to the fields that are set on the other
+// side. Fields are only compared to their defaults if the map that holds
+// them is on both sides.
+func WithDefaultEquality() CompareOption {
+	return func(opts *compareOptions) {
+		opts.defaults = true
+	}
+}
+
+// NumericEquals returns true if lhs and rhs are the same number, whether
+// they are ints or floats. Unlike value.Equals, ints are only equal to
+// floats that represent them exactly, and not to the closest floats of
+// large ints.
+func NumericEquals(lhs, rhs value.Value) bool {
+	switch {
+	case lhs.IsInt() && rhs.IsInt():
+		return lhs.AsInt() == rhs.AsInt()
+	case lhs.IsInt() && rhs.IsFloat():
+		return intEqualsFloat(lhs.AsInt(), rhs.AsFloat())
+	case lhs.IsFloat() && rhs.IsInt():
+		return intEqualsFloat(rhs.AsInt(), lhs.AsFloat())
+	}
+	return value.Equals(lhs, rhs)
+}
+
+func intEqualsFloat(i int64, f float64) bool {
+	// float64(math.MaxInt64) rounds up to 2^63, which isn't an int64.
+	if math.IsNaN(f) || f < -(1<<63) || f >= 1<<63 {
+		return false
+	}
+	return float64(int64(f)) == f && int64(f) == i
+}
+
 type compareWalker struct {
 	lhs     value.Value
 	rhs     value.Value
 	schema  *schema.Schema
 	typeRef schema.TypeRef
 
+	// options are the options of the comparison, if any.
+	options *compareOptions
+
 	// Current path that we are comparing
 	path fieldpath.Path
 
@@ -104,14 +191,14 @@ type compareWalker struct {
 }
 
 // compare compares stuff.
-func (w *compareWalker) compare(prefixFn func() string) (errs ValidationErrors) {
+func (w *compareWalker) compare(pe *fieldpath.PathElement) (errs ValidationErrors) {
 	if w.lhs == nil && w.rhs == nil {
 		// check this condidition here instead of everywhere below.
 		return errorf("at least one of lhs and rhs must be provided")
 	}
 	a, ok := w.schema.Resolve(w.typeRef)
 	if !ok {
-		return errorf("schema error: no type found matching: %v", *w.typeRef.NamedType)
+		return typedErrorf(ErrorTypeSchema, "schema error: no type found matching: %v", *w.typeRef.NamedType)
 	}
 
 	alhs := deduceAtom(a, w.lhs)
@@ -136,12 +223,13 @@ func (w *compareWalker) compare(prefixFn func() string) (errs ValidationErrors)
 			w.comparison.Removed.Insert(w.path)
 		}
 	}
-	return errs.WithLazyPrefix(prefixFn)
+	return errs.withLazyPathElementPrefix(pe)
 }
 
 // doLeaf should be called on leaves before descending into children, if there
-// will be a descent. It modifies w.inLeaf.
-func (w *compareWalker) doLeaf() {
+// will be a descent. It modifies w.inLeaf. eq tells if the leaf was
+// modified, or value.Equals if nil.
+func (w *compareWalker) doLeaf(eq ScalarEqualityFunc) {
 	if w.inLeaf {
 		// We're in a "big leaf", an atomic map or list. Ignore
 		// subsequent leaves.
@@ -154,23 +242,39 @@ func (w *compareWalker) doLeaf() {
 		w.comparison.Added.Insert(w.path)
 	} else if w.rhs == nil {
 		w.comparison.Removed.Insert(w.path)
+	} else if eq != nil {
+		if !eq(w.lhs, w.rhs) {
+			w.comparison.Modified.Insert(w.path)
+		}
 	} else if !value.EqualsUsing(w.allocator, w.rhs, w.lhs) {
-		// TODO: Equality is not sufficient for this.
-		// Need to implement equality check on the value type.
 		w.comparison.Modified.Insert(w.path)
 	}
 }
 
+// scalarEquality returns the equality configured for the scalar type t
+// of the current field, if any.
+func (w *compareWalker) scalarEquality(t schema.Scalar) ScalarEqualityFunc {
+	if w.options == nil {
+		return nil
+	}
+	if w.typeRef.NamedType != nil {
+		if eq, ok := w.options.typeEquality[*w.typeRef.NamedType]; ok {
+			return eq
+		}
+	}
+	return w.options.scalarEquality[t]
+}
+
 func (w *compareWalker) doScalar(t *schema.Scalar) ValidationErrors {
 	// Make sure at least one side is a valid scalar.
-	lerrs := validateScalar(t, w.lhs, "lhs: ")
-	rerrs := validateScalar(t, w.rhs, "rhs: ")
+	lerrs := validateScalar(t, w.typeRef, w.lhs, "lhs: ")
+	rerrs := validateScalar(t, w.typeRef, w.rhs, "rhs: ")
 	if len(lerrs) > 0 && len(rerrs) > 0 {
 		return append(lerrs, rerrs...)
 	}
 
 	// All scalars are leaf fields.
-	w.doLeaf()
+	w.doLeaf(w.scalarEquality(*t))
 
 	return nil
 }
@@ -208,7 +312,7 @@ func (w *compareWalker) derefMap(prefix string, v value.Value) (value.Map, Valid
 	}
 	m, err := mapValue(w.allocator, v)
 	if err != nil {
-		return nil, errorf("%v: %v", prefix, err)
+		return nil, typeMismatchf(v, w.typeRef, "%v: %v", prefix, err)
 	}
 	return m, nil
 }
@@ -237,7 +341,7 @@ func (w *compareWalker) visitListItems(t *schema.List, lhs, rhs value.List) (err
 		child := lhs.At(i)
 		pe, err := listItemToPathElement(w.allocator, w.schema, t, child)
 		if err != nil {
-			errs = append(errs, errorf("element %v: %v", i, err.Error())...)
+			errs = append(errs, listItemErrorf(i, child, err)...)
 			// If we can't construct the path element, we can't
 			// even report errors deeper in the schema, so bail on
 			// this element.
@@ -259,7 +363,7 @@ func (w *compareWalker) visitListItems(t *schema.List, lhs, rhs value.List) (err
 		rValue := rhs.At(i)
 		pe, err := listItemToPathElement(w.allocator, w.schema, t, rValue)
 		if err != nil {
-			errs = append(errs, errorf("element %v: %v", i, err.Error())...)
+			errs = append(errs, listItemErrorf(i, rValue, err)...)
 			// If we can't construct the path element, we can't
 			// even report errors deeper in the schema, so bail on
 			// this element.
@@ -351,7 +455,7 @@ func (w *compareWalker) indexListPathElements(t *schema.List, list value.List) (
 		child := list.At(i)
 		pe, err := listItemToPathElement(w.allocator, w.schema, t, child)
 		if err != nil {
-			errs = append(errs, errorf("element %v: %v", i, err.Error())...)
+			errs = append(errs, listItemErrorf(i, child, err)...)
 			// If we can't construct the path element, we can't
 			// even report errors deeper in the schema, so bail on
 			// this element.
@@ -371,7 +475,7 @@ func (w *compareWalker) compareListItem(t *schema.List, pe fieldpath.PathElement
 	w2 := w.prepareDescent(pe, t.ElementType, w.comparison)
 	w2.lhs = lChild
 	w2.rhs = rChild
-	errs := w2.compare(pe.String)
+	errs := w2.compare(&pe)
 	w.finishDescent(w2)
 	return errs
 }
@@ -382,7 +486,7 @@ func (w *compareWalker) derefList(prefix string, v value.Value) (value.List, Val
 	}
 	l, err := listValue(w.allocator, v)
 	if err != nil {
-		return nil, errorf("%v: %v", prefix, err)
+		return nil, typeMismatchf(v, w.typeRef, "%v: %v", prefix, err)
 	}
 	return l, nil
 }
@@ -403,7 +507,7 @@ func (w *compareWalker) doList(t *schema.List) (errs ValidationErrors) {
 	emptyPromoteToLeaf := (lhs == nil || lhs.Length() == 0) && (rhs == nil || rhs.Length() == 0)
 
 	if t.ElementRelationship == schema.Atomic || emptyPromoteToLeaf {
-		w.doLeaf()
+		w.doLeaf(nil)
 		return nil
 	}
 
@@ -416,16 +520,23 @@ func (w *compareWalker) doList(t *schema.List) (errs ValidationErrors) {
 	return errs
 }
 
-func (w *compareWalker) visitMapItem(t *schema.Map, out map[string]interface{}, key string, lhs, rhs value.Value) (errs ValidationErrors) {
+func (w *compareWalker) visitMapItem(t *schema.Map, out map[string]interface{}, key string, lhs, rhs value.Value, defaults bool) (errs ValidationErrors) {
 	fieldType := t.ElementType
 	if sf, ok := t.FindField(key); ok {
 		fieldType = sf.Type
+		if defaults && sf.Default != nil {
+			if lhs == nil {
+				lhs = value.NewValueInterface(sf.Default)
+			} else if rhs == nil {
+				rhs = value.NewValueInterface(sf.Default)
+			}
+		}
 	}
 	pe := fieldpath.PathElement{FieldName: &key}
 	w2 := w.prepareDescent(pe, fieldType, w.comparison)
 	w2.lhs = lhs
 	w2.rhs = rhs
-	errs = append(errs, w2.compare(pe.String)...)
+	errs = append(errs, w2.compare(&pe)...)
 	w.finishDescent(w2)
 	return errs
 }
@@ -433,8 +544,9 @@ func (w *compareWalker) visitMapItem(t *schema.Map, out map[string]interface{},
 func (w *compareWalker) visitMapItems(t *schema.Map, lhs, rhs value.Map) (errs ValidationErrors) {
 	out := map[string]interface{}{}
 
+	defaults := w.options != nil && w.options.defaults && lhs != nil && rhs != nil
 	value.MapZipUsing(w.allocator, lhs, rhs, value.Unordered, func(key string, lhsValue, rhsValue value.Value) bool {
-		errs = append(errs, w.visitMapItem(t, out, key, lhsValue, rhsValue)...)
+		errs = append(errs, w.visitMapItem(t, out, key, lhsValue, rhsValue, defaults)...)
 		return true
 	})
 
@@ -456,7 +568,7 @@ func (w *compareWalker) doMap(t *schema.Map) (errs ValidationErrors) {
 	emptyPromoteToLeaf := (lhs == nil || lhs.Empty()) && (rhs == nil || rhs.Empty())
 
 	if t.ElementRelationship == schema.Atomic || emptyPromoteToLeaf {
-		w.doLeaf()
+		w.doLeaf(nil)
 		return nil
 	}
 
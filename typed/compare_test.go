@@ -0,0 +1,259 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed_test
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+var compareParser = func() typed.ParseableType {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: replicas
+      type:
+        scalar: numeric
+    - name: memory
+      type:
+        namedType: quantity
+    - name: name
+      type:
+        scalar: string
+- name: quantity
+  scalar: string
+`)
+	if err != nil {
+		panic(err)
+	}
+	return parser.Type("root")
+}()
+
+// milliEquals is a simplified quantity equality, where "1" and "1000m"
+// are equal.
+func milliEquals(lhs, rhs value.Value) bool {
+	parse := func(v value.Value) (float64, bool) {
+		s, scale := v.AsString(), 1.0
+		if strings.HasSuffix(s, "m") {
+			s, scale = strings.TrimSuffix(s, "m"), 1e-3
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		return f * scale, err == nil
+	}
+	l, lok := parse(lhs)
+	r, rok := parse(rhs)
+	if !lok || !rok {
+		return lhs.AsString() == rhs.AsString()
+	}
+	return l == r
+}
+
+func foldEquals(lhs, rhs value.Value) bool {
+	return strings.EqualFold(lhs.AsString(), rhs.AsString())
+}
+
+func TestCompareOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		lhs, rhs typed.YAMLObject
+		opts     []typed.CompareOption
+		modified *fieldpath.Set
+	}{{
+		name:     "int and float",
+		lhs:      `{"replicas": 1}`,
+		rhs:      `{"replicas": 1.0}`,
+		modified: fieldpath.NewSet(),
+	}, {
+		name:     "numeric equality",
+		lhs:      `{"replicas": 1}`,
+		rhs:      `{"replicas": 1.0}`,
+		opts:     []typed.CompareOption{typed.WithNumericEquality()},
+		modified: fieldpath.NewSet(),
+	}, {
+		name:     "different numbers",
+		lhs:      `{"replicas": 1}`,
+		rhs:      `{"replicas": 1.5}`,
+		opts:     []typed.CompareOption{typed.WithNumericEquality()},
+		modified: _NS(_P("replicas")),
+	}, {
+		name:     "quantities",
+		lhs:      `{"memory": "1", "name": "a"}`,
+		rhs:      `{"memory": "1000m", "name": "a"}`,
+		modified: _NS(_P("memory")),
+	}, {
+		name:     "named type equality",
+		lhs:      `{"memory": "1", "name": "1"}`,
+		rhs:      `{"memory": "1000m", "name": "1000m"}`,
+		opts:     []typed.CompareOption{typed.WithNamedTypeEquality("quantity", milliEquals)},
+		modified: _NS(_P("name")),
+	}, {
+		name: "named type equality takes precedence",
+		lhs:  `{"memory": "1", "name": "a"}`,
+		rhs:  `{"memory": "1000m", "name": "A"}`,
+		opts: []typed.CompareOption{
+			typed.WithNamedTypeEquality("quantity", milliEquals),
+			typed.WithScalarEquality(schema.String, foldEquals),
+		},
+		modified: fieldpath.NewSet(),
+	}, {
+		name:     "scalar equality",
+		lhs:      `{"memory": "1", "name": "a"}`,
+		rhs:      `{"memory": "1000m", "name": "A"}`,
+		opts:     []typed.CompareOption{typed.WithScalarEquality(schema.String, foldEquals)},
+		modified: _NS(_P("memory")),
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			lhs, err := compareParser.FromYAML(tt.lhs)
+			if err != nil {
+				t.Fatalf("failed to parse lhs: %v", err)
+			}
+			rhs, err := compareParser.FromYAML(tt.rhs)
+			if err != nil {
+				t.Fatalf("failed to parse rhs: %v", err)
+			}
+			c, err := lhs.Compare(rhs, tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to compare: %v", err)
+			}
+			if !c.Modified.Equals(tt.modified) {
+				t.Errorf("expected modified:\n%v\ngot:\n%v", tt.modified, c.Modified)
+			}
+			if !c.Added.Empty() || !c.Removed.Empty() {
+				t.Errorf("expected no added or removed fields, got:\n%v", c)
+			}
+		})
+	}
+}
+
+func TestNumericEquals(t *testing.T) {
+	tests := []struct {
+		lhs, rhs interface{}
+		expected bool
+	}{
+		{int64(1), int64(1), true},
+		{int64(1), int64(2), false},
+		{int64(1), float64(1), true},
+		{float64(1), int64(1), true},
+		{float64(1.5), int64(1), false},
+		{float64(1.5), float64(1.5), true},
+		{int64(1<<53 + 1), float64(1 << 53), false},
+		{float64(1 << 53), int64(1<<53 + 1), false},
+		{int64(1 << 53), float64(1 << 53), true},
+		{int64(math.MaxInt64), float64(math.MaxInt64), false},
+		{int64(0), math.NaN(), false},
+		{"1", int64(1), false},
+	}
+	for _, tt := range tests {
+		lhs, rhs := value.NewValueInterface(tt.lhs), value.NewValueInterface(tt.rhs)
+		if got := typed.NumericEquals(lhs, rhs); got != tt.expected {
+			t.Errorf("expected NumericEquals(%v, %v) to be %v, got %v", tt.lhs, tt.rhs, tt.expected, got)
+		}
+	}
+}
+
+func TestCompareDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		lhs, rhs typed.YAMLObject
+		// expected is the comparison without defaults, and
+		// expectDefaults the comparison with WithDefaultEquality.
+		expected, expectDefaults *typed.Comparison
+	}{{
+		name:           "removed default",
+		lhs:            `{"replicas": 1}`,
+		rhs:            `{}`,
+		expected:       &typed.Comparison{Added: _NS(), Modified: _NS(), Removed: _NS(_P("replicas"))},
+		expectDefaults: &typed.Comparison{Added: _NS(), Modified: _NS(), Removed: _NS()},
+	}, {
+		name:           "added default",
+		lhs:            `{}`,
+		rhs:            `{"replicas": 1}`,
+		expected:       &typed.Comparison{Added: _NS(_P("replicas")), Modified: _NS(), Removed: _NS()},
+		expectDefaults: &typed.Comparison{Added: _NS(), Modified: _NS(), Removed: _NS()},
+	}, {
+		name:           "removed value",
+		lhs:            `{"replicas": 2}`,
+		rhs:            `{}`,
+		expected:       &typed.Comparison{Added: _NS(), Modified: _NS(), Removed: _NS(_P("replicas"))},
+		expectDefaults: &typed.Comparison{Added: _NS(), Modified: _NS(_P("replicas")), Removed: _NS()},
+	}, {
+		name: "nested defaults",
+		lhs:  `{"spec": {"image": "nginx", "command": ["run"]}}`,
+		rhs:  `{}`,
+		expected: &typed.Comparison{Added: _NS(), Modified: _NS(), Removed: _NS(
+			_P("spec"), _P("spec", "image"), _P("spec", "command"),
+		)},
+		expectDefaults: &typed.Comparison{Added: _NS(), Modified: _NS(), Removed: _NS()},
+	}, {
+		name: "list items",
+		lhs:  `{"items": [{"key": "a", "protocol": "TCP", "value": 0}]}`,
+		rhs:  `{"items": [{"key": "a"}]}`,
+		expected: &typed.Comparison{Added: _NS(), Modified: _NS(), Removed: _NS(
+			_P("items", _KBF("key", "a", "protocol", "TCP"), "protocol"),
+			_P("items", _KBF("key", "a", "protocol", "TCP"), "value"),
+		)},
+		expectDefaults: &typed.Comparison{Added: _NS(), Modified: _NS(), Removed: _NS()},
+	}, {
+		name: "removed map",
+		lhs:  `{"labels": {"a": {"image": "nginx"}}}`,
+		rhs:  `{"labels": {}}`,
+		expected: &typed.Comparison{Added: _NS(), Modified: _NS(), Removed: _NS(
+			_P("labels", "a"), _P("labels", "a", "image"),
+		)},
+		expectDefaults: &typed.Comparison{Added: _NS(), Modified: _NS(), Removed: _NS(
+			_P("labels", "a"), _P("labels", "a", "image"),
+		)},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			lhs, err := defaultsParser.FromYAML(tt.lhs)
+			if err != nil {
+				t.Fatalf("failed to parse lhs: %v", err)
+			}
+			rhs, err := defaultsParser.FromYAML(tt.rhs)
+			if err != nil {
+				t.Fatalf("failed to parse rhs: %v", err)
+			}
+			for _, c := range []struct {
+				opts     []typed.CompareOption
+				expected *typed.Comparison
+			}{
+				{nil, tt.expected},
+				{[]typed.CompareOption{typed.WithDefaultEquality()}, tt.expectDefaults},
+			} {
+				got, err := lhs.Compare(rhs, c.opts...)
+				if err != nil {
+					t.Fatalf("failed to compare: %v", err)
+				}
+				if !got.Added.Equals(c.expected.Added) || !got.Modified.Equals(c.expected.Modified) || !got.Removed.Equals(c.expected.Removed) {
+					t.Errorf("expected (%v options):\n%v\ngot:\n%v", len(c.opts), c.expected, got)
+				}
+			}
+		})
+	}
+}
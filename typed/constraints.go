@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed
+
+import (
+	"unicode/utf8"
+
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// constraintErrorf returns an error of type ErrorTypeConstraint for the
+// value v, of type tr.
+func constraintErrorf(v value.Value, tr schema.TypeRef, format string, args ...interface{}) ValidationErrors {
+	errs := typedErrorf(ErrorTypeConstraint, format, args...)
+	errs[0].Value = copyValue(v)
+	errs[0].Expected = tr
+	return errs
+}
+
+// validateScalarConstraints returns the errors of the scalar v, of type
+// tr, that doesn't satisfy the constraints c.
+func validateScalarConstraints(c *schema.Constraints, tr schema.TypeRef, v value.Value) (errs ValidationErrors) {
+	if c == nil || v == nil || v.IsNull() {
+		return nil
+	}
+	if len(c.Enum) > 0 {
+		found := false
+		for _, e := range c.Enum {
+			if value.Equals(v, value.NewValueInterface(e)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, constraintErrorf(v, tr, "value %v is not one of the allowed values %v", value.ToString(v), value.ToString(value.NewValueInterface(c.Enum)))...)
+		}
+	}
+	if v.IsInt() || v.IsFloat() {
+		n := float64(0)
+		if v.IsInt() {
+			n = float64(v.AsInt())
+		} else {
+			n = v.AsFloat()
+		}
+		if c.Minimum != nil && n < *c.Minimum {
+			errs = append(errs, constraintErrorf(v, tr, "value %v is less than the minimum %v", value.ToString(v), *c.Minimum)...)
+		}
+		if c.Maximum != nil && n > *c.Maximum {
+			errs = append(errs, constraintErrorf(v, tr, "value %v is greater than the maximum %v", value.ToString(v), *c.Maximum)...)
+		}
+	}
+	if v.IsString() {
+		s := v.AsString()
+		length := int64(utf8.RuneCountInString(s))
+		if c.MinLength != nil && length < *c.MinLength {
+			errs = append(errs, constraintErrorf(v, tr, "length %v is less than the minimum length %v", length, *c.MinLength)...)
+		}
+		if c.MaxLength != nil && length > *c.MaxLength {
+			errs = append(errs, constraintErrorf(v, tr, "length %v is greater than the maximum length %v", length, *c.MaxLength)...)
+		}
+		pattern, err := c.PatternRegexp()
+		if err != nil {
+			errs = append(errs, typedErrorf(ErrorTypeSchema, "schema error: invalid pattern %q: %v", c.Pattern, err)...)
+		} else if pattern != nil && !pattern.MatchString(s) {
+			errs = append(errs, constraintErrorf(v, tr, "value %v doesn't match the pattern %q", value.ToString(v), c.Pattern)...)
+		}
+	}
+	return errs
+}
+
+// validateListConstraints returns the errors of the list v, of type tr,
+// that doesn't satisfy the constraints c. MinItems is only checked if the
+// list is complete.
+func validateListConstraints(c *schema.Constraints, tr schema.TypeRef, v value.Value, list value.List, complete bool) (errs ValidationErrors) {
+	if c == nil {
+		return nil
+	}
+	length := int64(list.Length())
+	if complete && c.MinItems != nil && length < *c.MinItems {
+		errs = append(errs, constraintErrorf(v, tr, "%v items is less than the minimum of %v items", length, *c.MinItems)...)
+	}
+	if c.MaxItems != nil && length > *c.MaxItems {
+		errs = append(errs, constraintErrorf(v, tr, "%v items is more than the maximum of %v items", length, *c.MaxItems)...)
+	}
+	return errs
+}
+
+// validateMapConstraints returns the errors of the map v, of type tr,
+// that doesn't satisfy the constraints c. Required is only checked if the
+// map is complete, and the errors of missing required fields have the path
+// of the field.
+func validateMapConstraints(c *schema.Constraints, tr schema.TypeRef, v value.Value, m value.Map, complete bool) (errs ValidationErrors) {
+	if c == nil {
+		return nil
+	}
+	if length := int64(m.Length()); c.MaxProperties != nil && length > *c.MaxProperties {
+		errs = append(errs, constraintErrorf(v, tr, "%v fields is more than the maximum of %v fields", length, *c.MaxProperties)...)
+	}
+	if !complete {
+		return errs
+	}
+	for _, name := range c.Required {
+		if !m.Has(name) {
+			errs = append(errs, typedErrorf(ErrorTypeRequired, "required field is missing").withFieldNamePrefix(name)...)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed
+
+import (
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// ApplyDefaults sets the fields of tv that are missing to the Default of
+// their StructField in the schema, and returns the result, validated with
+// opts, along with the set of fields that were defaulted. tv is left
+// unchanged.
+//
+// Defaults are applied recursively, in the fields of maps, in the items of
+// lists and within the defaults that were just set, but fields that are
+// null are not defaulted, nor are the fields of null maps. The defaulted
+// set has the fields of the result that were set by a default, and a
+// default set within an atomic map or list defaults the whole map or list.
+func (tv TypedValue) ApplyDefaults(opts ...ValidationOptions) (*TypedValue, *fieldpath.Set, error) {
+	d := defaulter{
+		schema:    tv.schema,
+		defaulted: fieldpath.NewSet(),
+	}
+	doc := deepCopyValue(tv.value)
+	if errs := d.apply(doc, tv.typeRef); len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	out, err := AsTyped(value.NewValueInterface(doc), tv.schema, tv.typeRef, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if d.defaulted.Empty() && !d.defaultedRoot {
+		return out, d.defaulted, nil
+	}
+	fields, err := out.ToFieldSet()
+	if err != nil {
+		return nil, nil, err
+	}
+	defaulted := fieldpath.NewSet()
+	fields.Iterate(func(p fieldpath.Path) {
+		for i := 1; i <= len(p); i++ {
+			if d.defaultedRoot || d.defaulted.Has(p[:i]) {
+				defaulted.Insert(p)
+				return
+			}
+		}
+	})
+	return out, defaulted, nil
+}
+
+// defaulter applies defaults to an unstructured document, in place.
+type defaulter struct {
+	schema *schema.Schema
+	// path is the path of the current value, and atomicDepth is one more
+	// than the length of the path of the outermost atomic map or list
+	// that contains it, or zero if there is none.
+	path        fieldpath.Path
+	atomicDepth int
+	// defaulted has the paths of the fields that were defaulted, or of
+	// the atomic maps and lists in which fields were defaulted, and
+	// defaultedRoot is set if the root is such an atomic map or list.
+	defaulted     *fieldpath.Set
+	defaultedRoot bool
+}
+
+// apply applies the defaults of the type tr to the unstructured v.
+func (d *defaulter) apply(v interface{}, tr schema.TypeRef) ValidationErrors {
+	a, errs := resolveTypeRef(d.schema, tr)
+	if len(errs) > 0 {
+		return errs
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if a.Map == nil {
+			return nil
+		}
+		return d.applyMap(a.Map, v)
+	case []interface{}:
+		if a.List == nil {
+			return nil
+		}
+		return d.applyList(a.List, v)
+	}
+	return nil
+}
+
+func (d *defaulter) applyMap(t *schema.Map, m map[string]interface{}) (errs ValidationErrors) {
+	defer d.enterAtomic(t.ElementRelationship)()
+	for _, sf := range t.Fields {
+		if _, ok := m[sf.Name]; ok || sf.Default == nil {
+			continue
+		}
+		m[sf.Name] = deepCopyValue(value.NewValueInterface(sf.Default))
+		name := sf.Name
+		d.insert(append(d.path, fieldpath.PathElement{FieldName: &name}))
+	}
+	for _, key := range sortedKeys(value.NewValueInterface(m).AsMap()) {
+		tr := t.ElementType
+		if sf, ok := t.FindField(key); ok {
+			tr = sf.Type
+		}
+		name := key
+		d.path = append(d.path, fieldpath.PathElement{FieldName: &name})
+		errs = append(errs, d.apply(m[key], tr).withFieldNamePrefix(key)...)
+		d.path = d.path[:len(d.path)-1]
+	}
+	return errs
+}
+
+func (d *defaulter) applyList(t *schema.List, l []interface{}) (errs ValidationErrors) {
+	defer d.enterAtomic(t.ElementRelationship)()
+	for i := range l {
+		i := i
+		pe := fieldpath.PathElement{Index: &i}
+		if t.ElementRelationship == schema.Associative {
+			// Missing keys are given their defaults, so the items keep
+			// their path element once defaulted.
+			var err error
+			item := value.NewValueInterface(l[i])
+			pe, err = listItemToPathElement(value.HeapAllocator, d.schema, t, item)
+			if err != nil {
+				errs = append(errs, listItemErrorf(i, item, err)...)
+				continue
+			}
+		}
+		d.path = append(d.path, pe)
+		errs = append(errs, d.apply(l[i], t.ElementType).WithPathElementPrefix(pe)...)
+		d.path = d.path[:len(d.path)-1]
+	}
+	return errs
+}
+
+// enterAtomic records that the current value is an atomic map or list,
+// if the relationship is atomic, and returns the function that undoes it.
+func (d *defaulter) enterAtomic(relationship schema.ElementRelationship) func() {
+	if relationship != schema.Atomic || d.atomicDepth > 0 {
+		return func() {}
+	}
+	d.atomicDepth = len(d.path) + 1
+	return func() { d.atomicDepth = 0 }
+}
+
+// insert records that the field at p was defaulted.
+func (d *defaulter) insert(p fieldpath.Path) {
+	if d.atomicDepth > 0 {
+		p = d.path[:d.atomicDepth-1]
+	}
+	if len(p) == 0 {
+		d.defaultedRoot = true
+		return
+	}
+	d.defaulted.Insert(p.Copy())
+}
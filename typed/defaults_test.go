@@ -0,0 +1,203 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+var defaultsParser = func() typed.ParseableType {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: replicas
+      type:
+        scalar: numeric
+      default: 1
+    - name: name
+      type:
+        scalar: string
+    - name: spec
+      type:
+        namedType: spec
+      default: {}
+    - name: labels
+      type:
+        map:
+          elementType:
+            namedType: spec
+    - name: items
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["key", "protocol"]
+    - name: atomic
+      type:
+        map:
+          fields:
+          - name: a
+            type:
+              scalar: string
+          - name: b
+            type:
+              scalar: string
+            default: b
+          elementRelationship: atomic
+- name: spec
+  map:
+    fields:
+    - name: image
+      type:
+        scalar: string
+      default: nginx
+    - name: command
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+      default: ["run"]
+- name: item
+  map:
+    fields:
+    - name: key
+      type:
+        scalar: string
+    - name: protocol
+      type:
+        scalar: string
+      default: TCP
+    - name: value
+      type:
+        scalar: numeric
+      default: 0
+`)
+	if err != nil {
+		panic(err)
+	}
+	return parser.Type("root")
+}()
+
+func TestApplyDefaults(t *testing.T) {
+	tests := []struct {
+		name      string
+		object    typed.YAMLObject
+		expected  string
+		defaulted *fieldpath.Set
+	}{{
+		name:     "empty",
+		object:   `{}`,
+		expected: `{"replicas": 1, "spec": {"image": "nginx", "command": ["run"]}}`,
+		defaulted: _NS(
+			_P("replicas"),
+			_P("spec", "image"),
+			_P("spec", "command"),
+		),
+	}, {
+		name:      "set fields",
+		object:    `{"replicas": 3, "spec": {"image": "busybox", "command": []}}`,
+		expected:  `{"replicas": 3, "spec": {"image": "busybox", "command": []}}`,
+		defaulted: _NS(),
+	}, {
+		name:      "null fields",
+		object:    `{"replicas": null, "spec": null}`,
+		expected:  `{"replicas": null, "spec": null}`,
+		defaulted: _NS(),
+	}, {
+		name:     "map values",
+		object:   `{"replicas": 3, "spec": {"image": "busybox"}, "labels": {"a": {}, "b": {"image": "busybox"}}}`,
+		expected: `{"replicas": 3, "spec": {"image": "busybox", "command": ["run"]}, "labels": {"a": {"image": "nginx", "command": ["run"]}, "b": {"image": "busybox", "command": ["run"]}}}`,
+		defaulted: _NS(
+			_P("spec", "command"),
+			_P("labels", "a", "image"),
+			_P("labels", "a", "command"),
+			_P("labels", "b", "command"),
+		),
+	}, {
+		name:     "list items",
+		object:   `{"replicas": 3, "spec": {"image": "busybox", "command": []}, "items": [{"key": "a"}, {"key": "a", "protocol": "UDP", "value": 2}]}`,
+		expected: `{"replicas": 3, "spec": {"image": "busybox", "command": []}, "items": [{"key": "a", "protocol": "TCP", "value": 0}, {"key": "a", "protocol": "UDP", "value": 2}]}`,
+		defaulted: _NS(
+			_P("items", _KBF("key", "a", "protocol", "TCP"), "protocol"),
+			_P("items", _KBF("key", "a", "protocol", "TCP"), "value"),
+		),
+	}, {
+		name:      "atomic",
+		object:    `{"replicas": 3, "spec": {"image": "busybox", "command": []}, "atomic": {"a": "a"}}`,
+		expected:  `{"replicas": 3, "spec": {"image": "busybox", "command": []}, "atomic": {"a": "a", "b": "b"}}`,
+		defaulted: _NS(_P("atomic")),
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			object, err := defaultsParser.FromYAML(tt.object)
+			if err != nil {
+				t.Fatalf("failed to parse object: %v", err)
+			}
+			got, defaulted, err := object.ApplyDefaults()
+			if err != nil {
+				t.Fatalf("failed to apply defaults: %v", err)
+			}
+			expected, err := value.FromJSON([]byte(tt.expected))
+			if err != nil {
+				t.Fatalf("failed to parse expected object: %v", err)
+			}
+			if !value.Equals(got.AsValue(), expected) {
+				t.Errorf("expected:\n%v\ngot:\n%v", value.ToString(expected), value.ToString(got.AsValue()))
+			}
+			if !defaulted.Equals(tt.defaulted) {
+				t.Errorf("expected defaulted fields:\n%v\ngot:\n%v", tt.defaulted, defaulted)
+			}
+			original, err := defaultsParser.FromYAML(tt.object)
+			if err != nil {
+				t.Fatalf("failed to parse object: %v", err)
+			}
+			if !value.Equals(object.AsValue(), original.AsValue()) {
+				t.Errorf("expected the object to be left unchanged, got:\n%v", value.ToString(object.AsValue()))
+			}
+		})
+	}
+}
+
+func TestApplyDefaultsErrors(t *testing.T) {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: replicas
+      type:
+        scalar: numeric
+      default: one
+`)
+	if err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	object, err := parser.Type("root").FromYAML(`{}`)
+	if err != nil {
+		t.Fatalf("failed to parse object: %v", err)
+	}
+	if _, _, err := object.ApplyDefaults(); err == nil {
+		t.Errorf("expected an error applying an invalid default")
+	}
+}
@@ -0,0 +1,530 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// DiffEntryType is the kind of change of a DiffEntry.
+type DiffEntryType string
+
+const (
+	DiffAdded    DiffEntryType = "Added"
+	DiffModified DiffEntryType = "Modified"
+	DiffRemoved  DiffEntryType = "Removed"
+)
+
+// DiffEntry is a field that changed between two objects.
+type DiffEntry struct {
+	Path fieldpath.Path
+	Type DiffEntryType
+	// Old and New are the values of the field in the left and right hand
+	// side objects, or nil if the field is absent from the object.
+	Old, New value.Value
+}
+
+type diffEntry struct {
+	Path string           `json:"path"`
+	Type DiffEntryType    `json:"type"`
+	Old  *json.RawMessage `json:"old,omitempty"`
+	New  *json.RawMessage `json:"new,omitempty"`
+}
+
+// MarshalJSON serializes the entry, with its values if present.
+func (e DiffEntry) MarshalJSON() ([]byte, error) {
+	out := diffEntry{Path: e.Path.String(), Type: e.Type}
+	for _, v := range []struct {
+		value value.Value
+		out   **json.RawMessage
+	}{{e.Old, &out.Old}, {e.New, &out.New}} {
+		if v.value == nil {
+			continue
+		}
+		raw, err := value.ToJSON(v.value)
+		if err != nil {
+			return nil, err
+		}
+		r := json.RawMessage(raw)
+		*v.out = &r
+	}
+	return json.Marshal(&out)
+}
+
+// Diff is the difference between two objects, with the value of each
+// changed field, as returned by TypedValue.Diff.
+type Diff struct {
+	// Entries are the changed fields, ordered by path. The fields within
+	// added or removed fields are only included through their parent.
+	Entries []DiffEntry
+
+	lhs, rhs value.Value
+}
+
+// IsSame returns true if the two objects are the same.
+func (d *Diff) IsSame() bool {
+	return len(d.Entries) == 0
+}
+
+// MarshalJSON serializes the diff as the list of its entries.
+func (d *Diff) MarshalJSON() ([]byte, error) {
+	if d.Entries == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(d.Entries)
+}
+
+// Diff compares tv and rhs like Compare, with opts, and returns the
+// changed fields with their values. See the comments on the `Diff` struct for details on
+// the return value.
+//
+// tv and rhs must both be of the same type (their Schema and TypeRef must
+// match), or an error will be returned. Validation errors will be returned
+// if the objects don't conform to the schema.
+func (tv TypedValue) Diff(rhs *TypedValue, opts ...CompareOption) (*Diff, error) {
+	c, err := tv.Compare(rhs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	d := &Diff{lhs: tv.value, rhs: rhs.value}
+	for _, set := range []struct {
+		set       *fieldpath.Set
+		entryType DiffEntryType
+	}{{c.Added, DiffAdded}, {c.Modified, DiffModified}, {c.Removed, DiffRemoved}} {
+		set.set.Iterate(func(p fieldpath.Path) {
+			for i := 1; i < len(p); i++ {
+				if set.set.Has(p[:i]) {
+					return
+				}
+			}
+			e := DiffEntry{Path: p.Copy(), Type: set.entryType}
+			if set.entryType != DiffAdded {
+				e.Old = lookupPath(tv.schema, tv.typeRef, tv.value, p)
+			}
+			if set.entryType != DiffRemoved {
+				e.New = lookupPath(tv.schema, tv.typeRef, rhs.value, p)
+			}
+			d.Entries = append(d.Entries, e)
+		})
+	}
+	sort.SliceStable(d.Entries, func(i, j int) bool {
+		return d.Entries[i].Path.Compare(d.Entries[j].Path) < 0
+	})
+	return d, nil
+}
+
+// Lookup returns the value at the path in tv, or nil if tv doesn't have
+// the path.
+func (tv TypedValue) Lookup(p fieldpath.Path) value.Value {
+	return lookupPath(tv.schema, tv.typeRef, tv.value, p)
+}
+
+// lookupPath returns the value at the path in v, of type tr, or nil if
+// it doesn't have the path.
+func lookupPath(s *schema.Schema, tr schema.TypeRef, v value.Value, p fieldpath.Path) value.Value {
+	for _, pe := range p {
+		if v == nil {
+			return nil
+		}
+		a, ok := s.Resolve(tr)
+		if !ok {
+			return nil
+		}
+		switch {
+		case pe.FieldName != nil:
+			if !v.IsMap() || a.Map == nil {
+				return nil
+			}
+			tr = a.Map.ElementType
+			if sf, ok := a.Map.FindField(*pe.FieldName); ok {
+				tr = sf.Type
+			}
+			v, ok = v.AsMap().Get(*pe.FieldName)
+			if !ok {
+				return nil
+			}
+		case pe.Index != nil:
+			if !v.IsList() || a.List == nil || *pe.Index >= v.AsList().Length() {
+				return nil
+			}
+			tr = a.List.ElementType
+			v = v.AsList().At(*pe.Index)
+		default:
+			if !v.IsList() || a.List == nil {
+				return nil
+			}
+			tr = a.List.ElementType
+			list := v.AsList()
+			v = nil
+			for i := 0; i < list.Length(); i++ {
+				item := list.At(i)
+				itemPE, err := listItemToPathElement(value.HeapAllocator, s, a.List, item)
+				if err == nil && itemPE.Equals(pe) {
+					v = item
+					break
+				}
+			}
+		}
+	}
+	return v
+}
+
+// UnifiedDiffOptions are the options of Diff.WriteUnified.
+type UnifiedDiffOptions struct {
+	// FromName and ToName are the names of the two objects in the header
+	// of the diff.
+	FromName, ToName string
+	// Context is the number of unchanged lines shown around changes,
+	// usually DefaultUnifiedDiffContext.
+	Context int
+	// Color uses ANSI escape codes to color the diff.
+	Color bool
+}
+
+// DefaultUnifiedDiffContext is the number of context lines of unified
+// diffs that is used by String and by the diff tool.
+const DefaultUnifiedDiffContext = 3
+
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+)
+
+// WriteUnified writes a unified diff of the YAML serializations of the
+// two objects, which is empty if they are the same.
+func (d *Diff) WriteUnified(w io.Writer, opts UnifiedDiffOptions) error {
+	if d.IsSame() {
+		return nil
+	}
+	from, err := yamlLines(d.lhs)
+	if err != nil {
+		return err
+	}
+	to, err := yamlLines(d.rhs)
+	if err != nil {
+		return err
+	}
+	if opts.Context < 0 {
+		opts.Context = 0
+	}
+	if opts.FromName == "" {
+		opts.FromName = "lhs"
+	}
+	if opts.ToName == "" {
+		opts.ToName = "rhs"
+	}
+	color := func(c, s string) string {
+		if !opts.Color {
+			return s
+		}
+		return c + s + colorReset
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, color(colorBold, "--- "+opts.FromName))
+	fmt.Fprintln(bw, color(colorBold, "+++ "+opts.ToName))
+	edits := diffLines(from, to)
+	for _, h := range unifiedHunks(edits, opts.Context) {
+		fmt.Fprintln(bw, color(colorCyan, fmt.Sprintf("@@ -%v +%v @@", hunkRange(h.fromStart, h.fromLines), hunkRange(h.toStart, h.toLines))))
+		for _, e := range edits[h.start:h.end] {
+			switch e.op {
+			case '-':
+				fmt.Fprintln(bw, color(colorRed, "-"+e.line))
+			case '+':
+				fmt.Fprintln(bw, color(colorGreen, "+"+e.line))
+			default:
+				fmt.Fprintln(bw, " "+e.line)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func yamlLines(v value.Value) ([]string, error) {
+	if v == nil || v.IsNull() {
+		return nil, nil
+	}
+	b, err := value.ToYAML(v)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n"), nil
+}
+
+// lineEdit is a line of a diff: op is ' ' for a common line, '-' for a
+// removed line, and '+' for an added line.
+type lineEdit struct {
+	op   byte
+	line string
+}
+
+// diffLines returns a shortest edit script that turns from into to. It
+// is computed with the linear space variant of Myers' O(ND) algorithm, so
+// that its cost depends on the size of the changes rather than on the
+// size of the objects.
+func diffLines(from, to []string) []lineEdit {
+	d := lineDiffer{
+		from:    from,
+		to:      to,
+		removed: make([]bool, len(from)),
+		added:   make([]bool, len(to)),
+	}
+	d.compare(0, len(from), 0, len(to))
+
+	edits := make([]lineEdit, 0, len(from)+len(to))
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && d.removed[i]:
+			edits = append(edits, lineEdit{'-', from[i]})
+			i++
+		case j < len(to) && d.added[j]:
+			edits = append(edits, lineEdit{'+', to[j]})
+			j++
+		default:
+			edits = append(edits, lineEdit{' ', from[i]})
+			i++
+			j++
+		}
+	}
+	return edits
+}
+
+// lineDiffer marks the lines of from that are removed, and the lines of
+// to that are added, by a shortest edit script.
+type lineDiffer struct {
+	from, to       []string
+	removed, added []bool
+}
+
+// compare marks the edits that turn from[x0:x1] into to[y0:y1].
+func (d *lineDiffer) compare(x0, x1, y0, y1 int) {
+	for x0 < x1 && y0 < y1 && d.from[x0] == d.to[y0] {
+		x0++
+		y0++
+	}
+	for x0 < x1 && y0 < y1 && d.from[x1-1] == d.to[y1-1] {
+		x1--
+		y1--
+	}
+	switch {
+	case x0 == x1:
+		for y := y0; y < y1; y++ {
+			d.added[y] = true
+		}
+	case y0 == y1:
+		for x := x0; x < x1; x++ {
+			d.removed[x] = true
+		}
+	default:
+		x, y, ok := d.split(x0, x1, y0, y1)
+		if !ok {
+			// There is no common line.
+			d.compare(x0, x1, y0, y0)
+			d.compare(x1, x1, y0, y1)
+			return
+		}
+		d.compare(x0, x, y0, y)
+		d.compare(x, x1, y, y1)
+	}
+}
+
+// split returns a point of a shortest edit script that turns from[x0:x1]
+// into to[y0:y1], near its middle, where the paths searched forward from
+// the start and backward from the end overlap. ok is false if there is
+// no common line.
+func (d *lineDiffer) split(x0, x1, y0, y1 int) (x, y int, ok bool) {
+	n, m := x1-x0, y1-y0
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// forward[offset+k] is the furthest x reached on the diagonal k, in
+	// from[x0:x1], by the forward paths, and backward[offset+k] is the
+	// furthest distance from the end reached on the diagonal k by the
+	// backward paths, or -1 if the diagonal wasn't reached yet.
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+	delta := n - m
+	// The paths overlap in the forward search if delta is odd, and in the
+	// backward search otherwise.
+	front := delta%2 != 0
+	// The diagonals that went past the end of from or to are skipped.
+	kStart, kEnd, kbStart, kbEnd := 0, 0, 0, 0
+	for e := 0; e < maxD; e++ {
+		for k := -e + kStart; k <= e-kEnd; k += 2 {
+			var fx int
+			if k == -e || (k != e && forward[offset+k-1] < forward[offset+k+1]) {
+				fx = forward[offset+k+1]
+			} else {
+				fx = forward[offset+k-1] + 1
+			}
+			fy := fx - k
+			for fx < n && fy < m && d.from[x0+fx] == d.to[y0+fy] {
+				fx++
+				fy++
+			}
+			forward[offset+k] = fx
+			switch {
+			case fx > n:
+				kEnd += 2
+			case fy > m:
+				kStart += 2
+			case front:
+				if kb := offset + delta - k; kb >= 0 && kb < len(backward) && backward[kb] != -1 {
+					if fx >= n-backward[kb] {
+						return x0 + fx, y0 + fy, true
+					}
+				}
+			}
+		}
+		for k := -e + kbStart; k <= e-kbEnd; k += 2 {
+			var bx int
+			if k == -e || (k != e && backward[offset+k-1] < backward[offset+k+1]) {
+				bx = backward[offset+k+1]
+			} else {
+				bx = backward[offset+k-1] + 1
+			}
+			by := bx - k
+			for bx < n && by < m && d.from[x1-bx-1] == d.to[y1-by-1] {
+				bx++
+				by++
+			}
+			backward[offset+k] = bx
+			switch {
+			case bx > n:
+				kbEnd += 2
+			case by > m:
+				kbStart += 2
+			case !front:
+				if kf := offset + delta - k; kf >= 0 && kf < len(forward) && forward[kf] != -1 {
+					fx := forward[kf]
+					if fx >= n-bx {
+						return x0 + fx, y0 + fx - (kf - offset), true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// diffHunk is a range of edits, with the (1-based) position of its first
+// line and its number of lines in each file.
+type diffHunk struct {
+	start, end           int
+	fromStart, fromLines int
+	toStart, toLines     int
+}
+
+// unifiedHunks groups the changes of edits, with context lines around
+// them, into hunks.
+func unifiedHunks(edits []lineEdit, context int) []diffHunk {
+	var hunks []diffHunk
+	var h *diffHunk
+	fromLine, toLine := 1, 1
+	// lastChange is the index of the last changed line of the hunk.
+	lastChange := 0
+	for i, e := range edits {
+		if e.op != ' ' {
+			// A new hunk starts when the unchanged lines since the
+			// last change don't all fit in the context of both.
+			if h == nil || i-lastChange-1 > 2*context {
+				start := i - context
+				if start < 0 {
+					start = 0
+				}
+				if h != nil {
+					hunks = append(hunks, closeHunk(*h, edits, lastChange+context))
+				}
+				h = &diffHunk{start: start, fromStart: fromLine, toStart: toLine}
+				// Count the leading context lines.
+				h.fromStart -= i - start
+				h.toStart -= i - start
+			}
+			lastChange = i
+		}
+		switch e.op {
+		case '-':
+			fromLine++
+		case '+':
+			toLine++
+		default:
+			fromLine++
+			toLine++
+		}
+	}
+	if h != nil {
+		hunks = append(hunks, closeHunk(*h, edits, lastChange+context))
+	}
+	return hunks
+}
+
+// closeHunk ends h after the edit at index last, and counts its lines.
+func closeHunk(h diffHunk, edits []lineEdit, last int) diffHunk {
+	h.end = last + 1
+	if h.end > len(edits) {
+		h.end = len(edits)
+	}
+	for _, e := range edits[h.start:h.end] {
+		if e.op != '+' {
+			h.fromLines++
+		}
+		if e.op != '-' {
+			h.toLines++
+		}
+	}
+	return h
+}
+
+// hunkRange formats the range of lines of a hunk in one of the files.
+func hunkRange(start, lines int) string {
+	if lines == 0 {
+		// An empty range starts at the line before it.
+		start--
+	}
+	if lines == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%v,%v", start, lines)
+}
+
+// String returns the unified diff of the two objects, without colors.
+func (d *Diff) String() string {
+	var buf bytes.Buffer
+	if err := d.WriteUnified(&buf, UnifiedDiffOptions{Context: DefaultUnifiedDiffContext}); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
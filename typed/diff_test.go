@@ -0,0 +1,253 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		lhs, rhs typed.YAMLObject
+		json     string
+		unified  string
+	}{{
+		name:    "same",
+		lhs:     `{"name": "a", "items": [{"key": "a"}]}`,
+		rhs:     `{"name": "a", "items": [{"key": "a"}]}`,
+		json:    `[]`,
+		unified: ``,
+	}, {
+		name: "fields",
+		lhs:  `{"name": "a", "labels": {"a": "1", "b": "2"}, "args": ["a"]}`,
+		rhs:  `{"labels": {"a": "1", "b": "3", "c": "4"}, "args": ["b"]}`,
+		json: `[{"path":".args","type":"Modified","old":["a"],"new":["b"]},{"path":".labels.b","type":"Modified","old":"2","new":"3"},{"path":".labels.c","type":"Added","new":"4"},{"path":".name","type":"Removed","old":"a"}]`,
+		unified: `--- lhs
++++ rhs
+@@ -1,6 +1,6 @@
+ args:
+-- a
++- b
+ labels:
+   a: "1"
+-  b: "2"
+-name: a
++  b: "3"
++  c: "4"
+`,
+	}, {
+		name: "items",
+		lhs:  `{"items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}, {"key": "c", "value": 3}, {"key": "d", "value": 4}, {"key": "e", "value": 5}, {"key": "f", "value": 6}]}`,
+		rhs:  `{"items": [{"key": "a", "value": 10}, {"key": "b", "value": 2}, {"key": "c", "value": 3}, {"key": "d", "value": 4}, {"key": "e", "value": 5}]}`,
+		json: `[{"path":".items[key=\"a\"].value","type":"Modified","old":1,"new":10},{"path":".items[key=\"f\"]","type":"Removed","old":{"key":"f","value":6}}]`,
+		unified: `--- lhs
++++ rhs
+@@ -1,6 +1,6 @@
+ items:
+ - key: a
+-  value: 1
++  value: 10
+ - key: b
+   value: 2
+ - key: c
+@@ -9,5 +9,3 @@
+   value: 4
+ - key: e
+   value: 5
+-- key: f
+-  value: 6
+`,
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			lhs, err := patchParser.FromYAML(tt.lhs)
+			if err != nil {
+				t.Fatalf("failed to parse lhs: %v", err)
+			}
+			rhs, err := patchParser.FromYAML(tt.rhs)
+			if err != nil {
+				t.Fatalf("failed to parse rhs: %v", err)
+			}
+			d, err := lhs.Diff(rhs)
+			if err != nil {
+				t.Fatalf("failed to diff: %v", err)
+			}
+			b, err := json.Marshal(d)
+			if err != nil {
+				t.Fatalf("failed to serialize diff: %v", err)
+			}
+			if got := string(b); got != tt.json {
+				t.Errorf("expected JSON:\n%v\ngot:\n%v", tt.json, got)
+			}
+			if got := d.String(); got != tt.unified {
+				t.Errorf("expected unified diff:\n%v\ngot:\n%v", tt.unified, got)
+			}
+		})
+	}
+}
+
+func TestDiffColor(t *testing.T) {
+	lhs, err := patchParser.FromYAML(`{"name": "a"}`)
+	if err != nil {
+		t.Fatalf("failed to parse lhs: %v", err)
+	}
+	rhs, err := patchParser.FromYAML(`{"name": "b"}`)
+	if err != nil {
+		t.Fatalf("failed to parse rhs: %v", err)
+	}
+	d, err := lhs.Diff(rhs)
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := d.WriteUnified(&buf, typed.UnifiedDiffOptions{FromName: "old", ToName: "new", Context: typed.DefaultUnifiedDiffContext, Color: true}); err != nil {
+		t.Fatalf("failed to write diff: %v", err)
+	}
+	expected := strings.Join([]string{
+		"\x1b[1m--- old\x1b[0m",
+		"\x1b[1m+++ new\x1b[0m",
+		"\x1b[36m@@ -1 +1 @@\x1b[0m",
+		"\x1b[31m-name: a\x1b[0m",
+		"\x1b[32m+name: b\x1b[0m",
+		"",
+	}, "\n")
+	if got := buf.String(); got != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, got)
+	}
+}
+
+func TestDiffContext(t *testing.T) {
+	lhs, err := patchParser.FromYAML(`{"items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}, {"key": "c", "value": 3}]}`)
+	if err != nil {
+		t.Fatalf("failed to parse lhs: %v", err)
+	}
+	rhs, err := patchParser.FromYAML(`{"items": [{"key": "a", "value": 1}, {"key": "b", "value": 20}, {"key": "c", "value": 3}]}`)
+	if err != nil {
+		t.Fatalf("failed to parse rhs: %v", err)
+	}
+	d, err := lhs.Diff(rhs)
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+	tests := []struct {
+		name     string
+		context  int
+		expected string
+	}{{
+		name:    "default",
+		context: typed.DefaultUnifiedDiffContext,
+		expected: `--- lhs
++++ rhs
+@@ -2,6 +2,6 @@
+ - key: a
+   value: 1
+ - key: b
+-  value: 2
++  value: 20
+ - key: c
+   value: 3
+`,
+	}, {
+		name:    "one line",
+		context: 1,
+		expected: `--- lhs
++++ rhs
+@@ -4,3 +4,3 @@
+ - key: b
+-  value: 2
++  value: 20
+ - key: c
+`,
+	}, {
+		name:    "no context",
+		context: 0,
+		expected: `--- lhs
++++ rhs
+@@ -5 +5 @@
+-  value: 2
++  value: 20
+`,
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := d.WriteUnified(&buf, typed.UnifiedDiffOptions{Context: tt.context}); err != nil {
+				t.Fatalf("failed to write diff: %v", err)
+			}
+			if got := buf.String(); got != tt.expected {
+				t.Errorf("expected:\n%v\ngot:\n%v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiffLarge(t *testing.T) {
+	// The changes are spread across the objects, so that their lines can
+	// only be diffed efficiently if the cost depends on the changes.
+	var lhsArgs, rhsArgs []string
+	for i := 0; i < 50000; i++ {
+		lhsArgs = append(lhsArgs, fmt.Sprintf("arg-%v", i))
+		if i%1000 == 0 {
+			rhsArgs = append(rhsArgs, fmt.Sprintf("changed-%v", i))
+		} else {
+			rhsArgs = append(rhsArgs, fmt.Sprintf("arg-%v", i))
+		}
+	}
+	lhsJSON, err := json.Marshal(map[string]interface{}{"args": lhsArgs})
+	if err != nil {
+		t.Fatalf("failed to serialize lhs: %v", err)
+	}
+	rhsJSON, err := json.Marshal(map[string]interface{}{"args": rhsArgs})
+	if err != nil {
+		t.Fatalf("failed to serialize rhs: %v", err)
+	}
+	lhs, err := patchParser.FromYAML(typed.YAMLObject(lhsJSON))
+	if err != nil {
+		t.Fatalf("failed to parse lhs: %v", err)
+	}
+	rhs, err := patchParser.FromYAML(typed.YAMLObject(rhsJSON))
+	if err != nil {
+		t.Fatalf("failed to parse rhs: %v", err)
+	}
+	d, err := lhs.Diff(rhs)
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+	removed, added := 0, 0
+	for _, line := range strings.Split(d.String(), "\n") {
+		switch {
+		case strings.HasPrefix(line, "-- arg-"):
+			removed++
+		case strings.HasPrefix(line, "+- changed-"):
+			added++
+		case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+			t.Errorf("unexpected changed line %q", line)
+		}
+	}
+	if removed != 50 || added != 50 {
+		t.Errorf("expected 50 removed and 50 added lines, got %v and %v", removed, added)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed
+
+import (
+	"encoding/base64"
+	"math"
+	"regexp"
+	"time"
+
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// quantityRegexp matches the serialization of resource quantities: a
+// decimal number, followed by a binary suffix (Ki, Mi, ...), a decimal
+// suffix (n, u, m, k, M, ...) or an exponent.
+var quantityRegexp = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([KMGTPE]i|[numkMGTPE]|[eE][+-]?[0-9]+)?$`)
+
+// validateFormat returns an error if the scalar v, of type tr, doesn't
+// have the format f. Unknown formats are ignored.
+func validateFormat(f schema.Format, tr schema.TypeRef, v value.Value) ValidationErrors {
+	if v == nil || v.IsNull() {
+		return nil
+	}
+	valid := true
+	switch f {
+	case schema.FormatDateTime:
+		valid = v.IsString() && isDateTime(v.AsString())
+	case schema.FormatInt32:
+		valid = isIntInRange(v, math.MinInt32, math.MaxInt32)
+	case schema.FormatInt64:
+		valid = isIntInRange(v, math.MinInt64, math.MaxInt64)
+	case schema.FormatByte:
+		if valid = v.IsString(); valid {
+			_, err := base64.StdEncoding.DecodeString(v.AsString())
+			valid = err == nil
+		}
+	case schema.FormatQuantity:
+		valid = v.IsInt() || v.IsFloat() || (v.IsString() && quantityRegexp.MatchString(v.AsString()))
+	case schema.FormatDuration:
+		if valid = v.IsString(); valid {
+			_, err := time.ParseDuration(v.AsString())
+			valid = err == nil
+		}
+	}
+	if valid {
+		return nil
+	}
+	errs := typedErrorf(ErrorTypeFormat, "expected format %v, got %v", f, value.ToString(v))
+	errs[0].Value = copyValue(v)
+	errs[0].Expected = tr
+	return errs
+}
+
+func isDateTime(s string) bool {
+	_, err := time.Parse(time.RFC3339, s)
+	return err == nil
+}
+
+// isIntInRange returns true if v is an integer, or a float without a
+// fractional part, between min and max.
+func isIntInRange(v value.Value, min, max int64) bool {
+	switch {
+	case v.IsInt():
+		return v.AsInt() >= min && v.AsInt() <= max
+	case v.IsFloat():
+		f := v.AsFloat()
+		// float64(math.MaxInt64) rounds up to 2^63, which isn't an int64.
+		if !isIntegral(f) || f < -(1<<63) || f >= 1<<63 {
+			return false
+		}
+		return int64(f) >= min && int64(f) <= max
+	}
+	return false
+}
+
+// isIntegral returns true if f has no fractional part.
+func isIntegral(f float64) bool {
+	return !math.IsInf(f, 0) && f == math.Trunc(f)
+}
@@ -26,10 +26,62 @@ import (
 	"sigs.k8s.io/structured-merge-diff/v6/value"
 )
 
+// ValidationErrorType is a machine readable reason for a ValidationError.
+type ValidationErrorType string
+
+const (
+	// ErrorTypeInvalid is the type of errors without a more specific type.
+	ErrorTypeInvalid ValidationErrorType = "Invalid"
+	// ErrorTypeTypeMismatch is the type of errors for values that don't
+	// have the type required by the schema, e.g. a string for a numeric
+	// field or a scalar for a map. Expected is set to the expected type.
+	ErrorTypeTypeMismatch ValidationErrorType = "TypeMismatch"
+	// ErrorTypeDuplicateKey is the type of errors for items of an
+	// associative list that have the same key as a previous item.
+	ErrorTypeDuplicateKey ValidationErrorType = "DuplicateKey"
+	// ErrorTypeMissingKeyField is the type of errors for items of an
+	// associative list that omit one of the keys of the list, which has
+	// no default.
+	ErrorTypeMissingKeyField ValidationErrorType = "MissingKeyField"
+	// ErrorTypeUnknownField is the type of errors for fields that are not
+	// declared in the schema.
+	ErrorTypeUnknownField ValidationErrorType = "UnknownField"
+	// ErrorTypeUnion is the type of errors for unions with more than one
+	// of their fields set.
+	ErrorTypeUnion ValidationErrorType = "Union"
+	// ErrorTypeFormat is the type of errors for scalars that don't have
+	// the format required by the schema, e.g. a timestamp that isn't RFC
+	// 3339. Expected is set to the expected type.
+	ErrorTypeFormat ValidationErrorType = "Format"
+	// ErrorTypeConstraint is the type of errors for values that don't
+	// satisfy the constraints of the schema, e.g. a number greater than
+	// the maximum. Expected is set to the expected type.
+	ErrorTypeConstraint ValidationErrorType = "Constraint"
+	// ErrorTypeRequired is the type of errors for required fields that
+	// are missing. The path is the path of the missing field.
+	ErrorTypeRequired ValidationErrorType = "Required"
+	// ErrorTypeSchema is the type of errors caused by the schema rather
+	// than by the value, e.g. a reference to a type that doesn't exist.
+	ErrorTypeSchema ValidationErrorType = "Schema"
+)
+
 // ValidationError reports an error about a particular field
 type ValidationError struct {
 	Path         string
 	ErrorMessage string
+
+	// FieldPath is the path of the field, which is the parsed form of
+	// Path. It is nil if Path was set by WithPath to a string that can't
+	// be parsed, and it may be partial if Path was prefixed with strings
+	// that can't be parsed by WithPrefix or WithLazyPrefix.
+	FieldPath fieldpath.Path
+	// Type is the reason of the error.
+	Type ValidationErrorType
+	// Value is the offending value, if any.
+	Value value.Value
+	// Expected is the type that the value was expected to have, if
+	// known.
+	Expected schema.TypeRef
 }
 
 // Error returns a human readable error message.
@@ -58,16 +110,41 @@ func (errs ValidationErrors) Error() string {
 
 // Set the given path to all the validation errors.
 func (errs ValidationErrors) WithPath(p string) ValidationErrors {
+	fp, err := fieldpath.ParsePath(p)
+	if err != nil {
+		fp = nil
+	}
 	for i := range errs {
 		errs[i].Path = p
+		errs[i].FieldPath = fp
+	}
+	return errs
+}
+
+// withFieldPath is WithPath for a path that is already parsed.
+func (errs ValidationErrors) withFieldPath(p fieldpath.Path) ValidationErrors {
+	for i := range errs {
+		errs[i].Path = p.String()
+		errs[i].FieldPath = p.Copy()
 	}
 	return errs
 }
 
 // WithPrefix prefixes all errors path with the given pathelement. This
-// is useful when unwinding the stack on errors.
+// is useful when unwinding the stack on errors. FieldPath is only
+// prefixed if the prefix can be parsed as a path, so it may be partial.
+//
+// Deprecated: Use WithPathElementPrefix, which doesn't need to parse the
+// prefix to keep FieldPath up to date.
 func (errs ValidationErrors) WithPrefix(prefix string) ValidationErrors {
+	if len(errs) == 0 {
+		return errs
+	}
+	fp, err := fieldpath.ParsePath(prefix)
 	for i := range errs {
+		if err == nil {
+			errs[i].FieldPath = append(fp.Copy(), errs[i].FieldPath...)
+		}
 		errs[i].Path = prefix + errs[i].Path
 	}
 	return errs
@@ -75,7 +152,11 @@ func (errs ValidationErrors) WithPrefix(prefix string) ValidationErrors {
 
 // WithLazyPrefix prefixes all errors path with the given pathelement.
 // This is useful when unwinding the stack on errors. Prefix is
-// computed lazily only if there is an error.
+// computed lazily only if there is an error. As with WithPrefix,
+// FieldPath is only prefixed if the prefix can be parsed as a path.
+//
+// Deprecated: Use WithPathElementPrefix, which doesn't need to parse the
+// prefix to keep FieldPath up to date.
 func (errs ValidationErrors) WithLazyPrefix(fn func() string) ValidationErrors {
 	if len(errs) == 0 {
 		return errs
@@ -84,18 +165,116 @@ func (errs ValidationErrors) WithLazyPrefix(fn func() string) ValidationErrors {
 	if fn != nil {
 		prefix = fn()
 	}
+	return errs.WithPrefix(prefix)
+}
+
+// WithPathElementPrefix prefixes the path of all errors with the given
+// path element, both in FieldPath and in Path. This is useful when
+// unwinding the stack on errors.
+func (errs ValidationErrors) WithPathElementPrefix(pe fieldpath.PathElement) ValidationErrors {
+	if len(errs) == 0 {
+		return errs
+	}
+	// The path element may point to values that are reused once the
+	// walkers return, so keep a copy.
+	prefix := copyPathElement(pe)
+	return errs.withPrefixElement(prefix)
+}
+
+// withFieldNamePrefix is WithPathElementPrefix for a field name.
+func (errs ValidationErrors) withFieldNamePrefix(name string) ValidationErrors {
+	if len(errs) == 0 {
+		return errs
+	}
+	// Only allocate the name when there are errors.
+	fieldName := new(string)
+	*fieldName = name
+	return errs.withPrefixElement(fieldpath.PathElement{FieldName: fieldName})
+}
+
+func (errs ValidationErrors) withPrefixElement(pe fieldpath.PathElement) ValidationErrors {
+	prefix := pe.String()
 	for i := range errs {
 		errs[i].Path = prefix + errs[i].Path
+		errs[i].FieldPath = append(fieldpath.Path{pe}, errs[i].FieldPath...)
 	}
 	return errs
 }
 
+// withLazyPathElementPrefix is WithPathElementPrefix for a path element
+// that may be nil.
+func (errs ValidationErrors) withLazyPathElementPrefix(pe *fieldpath.PathElement) ValidationErrors {
+	if len(errs) == 0 || pe == nil {
+		return errs
+	}
+	return errs.WithPathElementPrefix(*pe)
+}
+
 func errorf(format string, args ...interface{}) ValidationErrors {
+	return typedErrorf(ErrorTypeInvalid, format, args...)
+}
+
+func typedErrorf(errType ValidationErrorType, format string, args ...interface{}) ValidationErrors {
 	return ValidationErrors{{
 		ErrorMessage: fmt.Sprintf(format, args...),
+		Type:         errType,
 	}}
 }
 
+// typeMismatchf returns an error of type ErrorTypeTypeMismatch for the
+// value v, which should have been of type tr.
+func typeMismatchf(v value.Value, tr schema.TypeRef, format string, args ...interface{}) ValidationErrors {
+	errs := typedErrorf(ErrorTypeTypeMismatch, format, args...)
+	errs[0].Value = copyValue(v)
+	errs[0].Expected = tr
+	return errs
+}
+
+// listItemErrorf returns the error of listItemToPathElement for the
+// item i of a list.
+func listItemErrorf(i int, item value.Value, err error) ValidationErrors {
+	errType := ErrorTypeInvalid
+	if _, ok := err.(missingKeyFieldError); ok {
+		errType = ErrorTypeMissingKeyField
+	}
+	errs := typedErrorf(errType, "element %v: %v", i, err.Error())
+	errs[0].Value = copyValue(item)
+	return errs
+}
+
+// copyValue returns a copy of v which is not affected by the allocators
+// reusing v.
+func copyValue(v value.Value) value.Value {
+	if v == nil {
+		return nil
+	}
+	return value.NewValueInterface(v.Unstructured())
+}
+
+func copyPathElement(pe fieldpath.PathElement) fieldpath.PathElement {
+	out := fieldpath.PathElement{}
+	if pe.FieldName != nil {
+		name := *pe.FieldName
+		out.FieldName = &name
+	}
+	if pe.Key != nil {
+		key := make(value.FieldList, len(*pe.Key))
+		for i, f := range *pe.Key {
+			key[i] = value.Field{Name: f.Name, Value: copyValue(f.Value)}
+		}
+		out.Key = &key
+	}
+	if pe.Value != nil {
+		v := copyValue(*pe.Value)
+		out.Value = &v
+	}
+	if pe.Index != nil {
+		i := *pe.Index
+		out.Index = &i
+	}
+	return out
+}
+
 type atomHandler interface {
 	doScalar(*schema.Scalar) ValidationErrors
 	doList(*schema.List) ValidationErrors
@@ -103,17 +282,24 @@ type atomHandler interface {
 }
 
 func resolveSchema(s *schema.Schema, tr schema.TypeRef, v value.Value, ah atomHandler) ValidationErrors {
+	a, errs := resolveAtom(s, tr, v)
+	if errs != nil {
+		return errs
+	}
+	return handleAtom(a, tr, ah)
+}
+
+// resolveAtom returns the atom of tr that applies to v.
+func resolveAtom(s *schema.Schema, tr schema.TypeRef, v value.Value) (schema.Atom, ValidationErrors) {
 	a, ok := s.Resolve(tr)
 	if !ok {
 		typeName := "inlined type"
 		if tr.NamedType != nil {
 			typeName = *tr.NamedType
 		}
-		return errorf("schema error: no type found matching: %v", typeName)
+		return schema.Atom{}, typedErrorf(ErrorTypeSchema, "schema error: no type found matching: %v", typeName)
 	}
-
-	a = deduceAtom(a, v)
-	return handleAtom(a, tr, ah)
+	return deduceAtom(a, v), nil
 }
 
 // deduceAtom determines which of the possible types in atom 'atom' applies to value 'val'.
@@ -125,15 +311,15 @@ func deduceAtom(atom schema.Atom, val value.Value) schema.Atom {
 	case val == nil:
 	case val.IsFloat(), val.IsInt(), val.IsString(), val.IsBool():
 		if atom.Scalar != nil {
-			return schema.Atom{Scalar: atom.Scalar}
+			return schema.Atom{Scalar: atom.Scalar, Format: atom.Format, Constraints: atom.Constraints}
 		}
 	case val.IsList():
 		if atom.List != nil {
-			return schema.Atom{List: atom.List}
+			return schema.Atom{List: atom.List, Constraints: atom.Constraints}
 		}
 	case val.IsMap():
 		if atom.Map != nil {
-			return schema.Atom{Map: atom.Map}
+			return schema.Atom{Map: atom.Map, Constraints: atom.Constraints}
 		}
 	}
 	return atom
@@ -154,7 +340,7 @@ func handleAtom(a schema.Atom, tr schema.TypeRef, ah atomHandler) ValidationErro
 		name = "named type: " + *tr.NamedType
 	}
 
-	return errorf("schema error: invalid atom: %v", name)
+	return typedErrorf(ErrorTypeSchema, "schema error: invalid atom: %v", name)
 }
 
 // Returns the list, or an error. Reminder: nil is a valid list and might be returned.
@@ -197,6 +383,16 @@ func getAssociativeKeyDefault(s *schema.Schema, list *schema.List, fieldName str
 	return field.Default, nil
 }
 
+// missingKeyFieldError is returned for items of an associative list that
+// omit one of the keys of the list.
+type missingKeyFieldError struct {
+	field string
+}
+
+func (e missingKeyFieldError) Error() string {
+	return fmt.Sprintf("associative list with keys has an element that omits key field %q (and doesn't have default value)", e.field)
+}
+
 func keyedAssociativeListItemToPathElement(a value.Allocator, s *schema.Schema, list *schema.List, child value.Value) (fieldpath.PathElement, error) {
 	pe := fieldpath.PathElement{}
 	if child.IsNull() {
@@ -217,7 +413,7 @@ func keyedAssociativeListItemToPathElement(a value.Allocator, s *schema.Schema,
 		} else if def != nil {
 			keyMap = append(keyMap, value.Field{Name: fieldName, Value: value.NewValueInterface(def)})
 		} else {
-			return pe, fmt.Errorf("associative list with keys has an element that omits key field %q (and doesn't have default value)", fieldName)
+			return pe, missingKeyFieldError{fieldName}
 		}
 	}
 	keyMap.Sort()
@@ -257,3 +453,29 @@ func listItemToPathElement(a value.Allocator, s *schema.Schema, list *schema.Lis
 	// If there's no keys, then we must be a set of primitives.
 	return setItemToPathElement(child)
 }
+
+// indexAssociativeListItems returns the path element of each item of the
+// associative list, or nil if some items have the same path element.
+func indexAssociativeListItems(s *schema.Schema, t *schema.List, list value.List) ([]fieldpath.PathElement, ValidationErrors) {
+	var errs ValidationErrors
+	pes := make([]fieldpath.PathElement, list.Length())
+	observed := fieldpath.MakePathElementSet(list.Length())
+	duplicates := false
+	for i := range pes {
+		item := list.At(i)
+		pe, err := listItemToPathElement(value.HeapAllocator, s, t, item)
+		if err != nil {
+			errs = append(errs, listItemErrorf(i, item, err)...)
+			continue
+		}
+		if observed.Has(pe) {
+			duplicates = true
+		}
+		observed.Insert(pe)
+		pes[i] = pe
+	}
+	if duplicates {
+		return nil, errs
+	}
+	return pes, errs
+}
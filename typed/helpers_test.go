@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
 	"sigs.k8s.io/structured-merge-diff/v6/internal/fixture"
 	"sigs.k8s.io/structured-merge-diff/v6/typed"
 )
@@ -45,3 +46,29 @@ func TestInvalidOverride(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestValidationErrorsPrefixes(t *testing.T) {
+	name := "name"
+	errs := typed.ValidationErrors{{ErrorMessage: "invalid"}}
+	errs = errs.WithPathElementPrefix(fieldpath.PathElement{FieldName: &name})
+	errs = errs.WithPrefix(`.items[key="a"]`)
+	errs = errs.WithLazyPrefix(func() string { return ".spec" })
+	expected := fieldpath.MakePathOrDie("spec", "items", fieldpath.KeyByFields("key", "a"), "name")
+	if errs[0].Path != expected.String() || !errs[0].FieldPath.Equals(expected) {
+		t.Errorf("expected path %v, got %v and %v", expected, errs[0].Path, errs[0].FieldPath)
+	}
+
+	errs = errs.WithPath("[0].spec")
+	expected = fieldpath.MakePathOrDie(0, "spec")
+	if errs[0].Path != expected.String() || !errs[0].FieldPath.Equals(expected) {
+		t.Errorf("expected path %v, got %v and %v", expected, errs[0].Path, errs[0].FieldPath)
+	}
+
+	// Prefixes that aren't paths are only added to Path, so FieldPath is
+	// partial.
+	errs = errs.WithPrefix("root: ").WithPrefix(".spec")
+	expected = fieldpath.MakePathOrDie("spec", 0, "spec")
+	if errs[0].Path != ".specroot: [0].spec" || !errs[0].FieldPath.Equals(expected) {
+		t.Errorf("expected path .specroot: [0].spec and %v, got %v and %v", expected, errs[0].Path, errs[0].FieldPath)
+	}
+}
@@ -0,0 +1,765 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// JSONPatchOp is the name of a JSON Patch operation.
+type JSONPatchOp string
+
+const (
+	JSONPatchAdd     JSONPatchOp = "add"
+	JSONPatchRemove  JSONPatchOp = "remove"
+	JSONPatchReplace JSONPatchOp = "replace"
+	JSONPatchMove    JSONPatchOp = "move"
+	JSONPatchCopy    JSONPatchOp = "copy"
+	JSONPatchTest    JSONPatchOp = "test"
+)
+
+// JSONPatchOperation is an operation of an RFC 6902 JSON Patch. Path and
+// From are JSON Pointers (RFC 6901).
+type JSONPatchOperation struct {
+	Op   JSONPatchOp
+	Path string
+	// From is only used by the move and copy operations.
+	From string
+	// Value is only used by the add, replace and test operations.
+	Value value.Value
+}
+
+// JSONPatch is an RFC 6902 JSON Patch, a list of operations applied in
+// order.
+type JSONPatch []JSONPatchOperation
+
+type jsonPatchOperation struct {
+	Op    JSONPatchOp      `json:"op"`
+	From  string           `json:"from,omitempty"`
+	Path  string           `json:"path"`
+	Value *json.RawMessage `json:"value,omitempty"`
+}
+
+// MarshalJSON serializes the operation, with its value only if the
+// operation uses one.
+func (o JSONPatchOperation) MarshalJSON() ([]byte, error) {
+	out := jsonPatchOperation{Op: o.Op, Path: o.Path}
+	switch o.Op {
+	case JSONPatchMove, JSONPatchCopy:
+		out.From = o.From
+	case JSONPatchAdd, JSONPatchReplace, JSONPatchTest:
+		v := o.Value
+		if v == nil {
+			v = value.NewValueInterface(nil)
+		}
+		raw, err := value.ToJSON(v)
+		if err != nil {
+			return nil, err
+		}
+		r := json.RawMessage(raw)
+		out.Value = &r
+	}
+	return json.Marshal(&out)
+}
+
+// UnmarshalJSON parses an operation. The value of the operations that
+// use one must be set, even if only to null.
+func (o *JSONPatchOperation) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Op    JSONPatchOp     `json:"op"`
+		From  *string         `json:"from"`
+		Path  *string         `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if in.Path == nil {
+		return fmt.Errorf("json patch %q operation is missing a path", in.Op)
+	}
+	out := JSONPatchOperation{Op: in.Op, Path: *in.Path}
+	switch in.Op {
+	case JSONPatchRemove:
+	case JSONPatchMove, JSONPatchCopy:
+		if in.From == nil {
+			return fmt.Errorf("json patch %q operation is missing a from path", in.Op)
+		}
+		out.From = *in.From
+	case JSONPatchAdd, JSONPatchReplace, JSONPatchTest:
+		if len(in.Value) == 0 {
+			return fmt.Errorf("json patch %q operation is missing a value", in.Op)
+		}
+		v, err := value.FromJSON(in.Value)
+		if err != nil {
+			return err
+		}
+		out.Value = v
+	default:
+		return fmt.Errorf("unknown json patch operation %q", in.Op)
+	}
+	*o = out
+	return nil
+}
+
+// String returns the JSON serialization of the patch.
+func (p JSONPatch) String() string {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+// MarshalJSON serializes the patch, so that a nil patch is an empty list.
+func (p JSONPatch) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, o := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b, err := o.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// JSONPatch returns an RFC 6902 JSON Patch that turns tv into rhs. The
+// patch is computed with the schema: items of associative lists are
+// matched by key (or by value for sets) and addressed by their index at
+// the time each operation is applied, while atomic maps and lists, and
+// other lists, are replaced as a whole when they differ.
+//
+// tv and rhs must both be of the same type (their Schema and TypeRef must
+// match), or an error will be returned. The objects aren't validated as a
+// whole, but validation errors will be returned for the parts the patch
+// is computed from, if their types can't be resolved or if the items of
+// their associative lists can't be keyed, e.g. for missing or duplicate
+// keys.
+func (tv TypedValue) JSONPatch(rhs *TypedValue) (JSONPatch, error) {
+	if err := checkSameType(&tv, rhs); err != nil {
+		return nil, err
+	}
+	w := jsonPatchWalker{schema: tv.schema, patch: JSONPatch{}}
+	if errs := w.diff("", tv.value, rhs.value, tv.typeRef); len(errs) > 0 {
+		return nil, errs
+	}
+	return w.patch, nil
+}
+
+type jsonPatchWalker struct {
+	schema *schema.Schema
+	patch  JSONPatch
+}
+
+// diff appends the operations that turn lhs into rhs, at the JSON
+// Pointer ptr, to the patch.
+func (w *jsonPatchWalker) diff(ptr string, lhs, rhs value.Value, tr schema.TypeRef) ValidationErrors {
+	if value.Equals(lhs, rhs) {
+		return nil
+	}
+	a, errs := resolveTypeRef(w.schema, tr)
+	if len(errs) > 0 {
+		return errs
+	}
+	switch {
+	case lhs.IsMap() && rhs.IsMap() && a.Map != nil && a.Map.ElementRelationship != schema.Atomic:
+		return w.diffMaps(ptr, a.Map, lhs.AsMap(), rhs.AsMap())
+	case lhs.IsList() && rhs.IsList() && a.List != nil && a.List.ElementRelationship == schema.Associative:
+		return w.diffAssociativeLists(ptr, a.List, lhs, rhs)
+	}
+	w.patch = append(w.patch, JSONPatchOperation{Op: JSONPatchReplace, Path: ptr, Value: rhs})
+	return nil
+}
+
+func (w *jsonPatchWalker) diffMaps(ptr string, t *schema.Map, lhs, rhs value.Map) (errs ValidationErrors) {
+	keys := map[string]struct{}{}
+	collect := func(key string, _ value.Value) bool {
+		keys[key] = struct{}{}
+		return true
+	}
+	lhs.Iterate(collect)
+	rhs.Iterate(collect)
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		childPtr := ptr + "/" + escapeJSONPointer(key)
+		lChild, lok := lhs.Get(key)
+		rChild, rok := rhs.Get(key)
+		switch {
+		case !rok:
+			w.patch = append(w.patch, JSONPatchOperation{Op: JSONPatchRemove, Path: childPtr})
+		case !lok:
+			w.patch = append(w.patch, JSONPatchOperation{Op: JSONPatchAdd, Path: childPtr, Value: rChild})
+		default:
+			tr := t.ElementType
+			if sf, ok := t.FindField(key); ok {
+				tr = sf.Type
+			}
+			errs = append(errs, w.diff(childPtr, lChild, rChild, tr).withFieldNamePrefix(key)...)
+		}
+	}
+	return errs
+}
+
+// diffAssociativeLists turns lhs into rhs by removing the items that are
+// not in rhs, starting from the end, and then by moving or adding each
+// item of rhs to its position, in order, and patching it.
+func (w *jsonPatchWalker) diffAssociativeLists(ptr string, t *schema.List, lhsValue, rhsValue value.Value) (errs ValidationErrors) {
+	lhs, rhs := lhsValue.AsList(), rhsValue.AsList()
+	lPEs, lerrs := indexAssociativeListItems(w.schema, t, lhs)
+	rPEs, rerrs := indexAssociativeListItems(w.schema, t, rhs)
+	if errs = append(lerrs, rerrs...); len(errs) > 0 {
+		return errs
+	}
+	if lPEs == nil || rPEs == nil {
+		// Items with duplicate keys can't be told apart.
+		w.patch = append(w.patch, JSONPatchOperation{Op: JSONPatchReplace, Path: ptr, Value: rhsValue})
+		return nil
+	}
+
+	rKeys := fieldpath.MakePathElementSet(len(rPEs))
+	for _, pe := range rPEs {
+		rKeys.Insert(pe)
+	}
+	// current holds the indices in lhs of the items left in the list,
+	// or -1 for added items.
+	current := make([]int, 0, lhs.Length())
+	for i := lhs.Length() - 1; i >= 0; i-- {
+		if !rKeys.Has(lPEs[i]) {
+			w.patch = append(w.patch, JSONPatchOperation{Op: JSONPatchRemove, Path: ptr + "/" + strconv.Itoa(i)})
+		}
+	}
+	for i := 0; i < lhs.Length(); i++ {
+		if rKeys.Has(lPEs[i]) {
+			current = append(current, i)
+		}
+	}
+
+	for j, pe := range rPEs {
+		itemPtr := ptr + "/" + strconv.Itoa(j)
+		k := j
+		for ; k < len(current); k++ {
+			if current[k] >= 0 && lPEs[current[k]].Equals(pe) {
+				break
+			}
+		}
+		if k == len(current) {
+			w.patch = append(w.patch, JSONPatchOperation{Op: JSONPatchAdd, Path: itemPtr, Value: rhs.At(j)})
+			current = append(current[:j], append([]int{-1}, current[j:]...)...)
+			continue
+		}
+		if k != j {
+			w.patch = append(w.patch, JSONPatchOperation{Op: JSONPatchMove, From: ptr + "/" + strconv.Itoa(k), Path: itemPtr})
+			moved := current[k]
+			copy(current[j+1:k+1], current[j:k])
+			current[j] = moved
+		}
+		errs = append(errs, w.diff(itemPtr, lhs.At(current[j]), rhs.At(j), t.ElementType).WithPathElementPrefix(pe)...)
+	}
+	return errs
+}
+
+// escapeJSONPointer escapes a reference token of a JSON Pointer.
+func escapeJSONPointer(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// ApplyJSONPatch applies the RFC 6902 JSON Patch to tv and returns the
+// result, validated with opts, along with the set of fields that the patch
+// touched. tv is left unchanged.
+//
+// The touched set has the fields of the result that were written by an
+// add, replace, move or copy operation, even if their value didn't
+// change, and the fields that were removed. Changes within an atomic map
+// or list touch the whole map or list. Test operations touch nothing.
+//
+// Errors are reported as ValidationErrors, with the path of the field
+// where the operation failed.
+func (tv TypedValue) ApplyJSONPatch(patch JSONPatch, opts ...ValidationOptions) (*TypedValue, *fieldpath.Set, error) {
+	a := jsonPatchApplier{
+		schema:  tv.schema,
+		doc:     deepCopyValue(tv.value),
+		written: fieldpath.NewSet(),
+		removed: fieldpath.NewSet(),
+	}
+	root := jsonPatchLocation{typeRef: tv.typeRef}
+	for i, op := range patch {
+		if errs := a.apply(root, op); len(errs) > 0 {
+			for j := range errs {
+				errs[j].ErrorMessage = fmt.Sprintf("json patch operation %d (%v %v): %v", i, op.Op, op.Path, errs[j].ErrorMessage)
+			}
+			return nil, nil, errs
+		}
+	}
+
+	out, err := AsTyped(value.NewValueInterface(a.doc), tv.schema, tv.typeRef, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	fields, err := out.ToFieldSet()
+	if err != nil {
+		return nil, nil, err
+	}
+	touched := fieldpath.NewSet()
+	fields.Iterate(func(p fieldpath.Path) {
+		for i := 1; i <= len(p); i++ {
+			if a.writtenRoot || a.written.Has(p[:i]) {
+				touched.Insert(p)
+				return
+			}
+		}
+	})
+	return out, touched.Union(a.removed), nil
+}
+
+// jsonPatchApplier applies JSON Patch operations to doc, an unstructured
+// value that it owns.
+type jsonPatchApplier struct {
+	schema *schema.Schema
+	doc    interface{}
+
+	// written has the paths written by the operations, whose fields in
+	// the result were touched, and removed the fields that were removed.
+	written     *fieldpath.Set
+	writtenRoot bool
+	removed     *fieldpath.Set
+}
+
+// jsonPatchLocation is the type and field path of a value of the document.
+type jsonPatchLocation struct {
+	typeRef schema.TypeRef
+	path    fieldpath.Path
+	// atomic is true within atomic maps and lists, where the path stops
+	// at the atomic value.
+	atomic bool
+}
+
+func (loc jsonPatchLocation) errorf(format string, args ...interface{}) ValidationErrors {
+	errs := errorf(format, args...)
+	errs[0].Path = loc.path.String()
+	errs[0].FieldPath = loc.path.Copy()
+	return errs
+}
+
+func (a *jsonPatchApplier) apply(root jsonPatchLocation, op JSONPatchOperation) ValidationErrors {
+	tokens, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return errorf("%v", err)
+	}
+	switch op.Op {
+	case JSONPatchAdd, JSONPatchReplace:
+		v := op.Value
+		if v == nil {
+			v = value.NewValueInterface(nil)
+		}
+		return a.add(root, tokens, deepCopyValue(v), op.Op == JSONPatchReplace)
+	case JSONPatchRemove:
+		_, errs := a.remove(root, tokens)
+		return errs
+	case JSONPatchMove, JSONPatchCopy:
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return errorf("%v", err)
+		}
+		var v interface{}
+		var errs ValidationErrors
+		if op.Op == JSONPatchCopy {
+			v, _, errs = a.get(root, from)
+			v = deepCopyValue(value.NewValueInterface(v))
+		} else {
+			if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return errorf("can't move %v into itself", op.From)
+			}
+			v, errs = a.remove(root, from)
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+		return a.add(root, tokens, v, false)
+	case JSONPatchTest:
+		v, loc, errs := a.get(root, tokens)
+		if len(errs) > 0 {
+			return errs
+		}
+		expected := op.Value
+		if expected == nil {
+			expected = value.NewValueInterface(nil)
+		}
+		if !value.Equals(value.NewValueInterface(v), expected) {
+			errs := loc.errorf("test failed, expected %v, got %v", value.ToString(expected), value.ToString(value.NewValueInterface(v)))
+			errs[0].Value = value.NewValueInterface(v)
+			return errs
+		}
+		return nil
+	}
+	return errorf("unknown json patch operation %q", op.Op)
+}
+
+// add adds or replaces the value at tokens with v.
+func (a *jsonPatchApplier) add(root jsonPatchLocation, tokens []string, v interface{}, replace bool) ValidationErrors {
+	if len(tokens) == 0 {
+		a.doc = v
+		a.writtenRoot = true
+		return nil
+	}
+	var written jsonPatchLocation
+	doc, errs := a.update(a.doc, root, tokens, func(parent interface{}, loc jsonPatchLocation, token string) (interface{}, ValidationErrors) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := p[token]; replace && !ok {
+				return nil, loc.errorf("field %q doesn't exist", token)
+			}
+			p[token] = v
+		case []interface{}:
+			i, errs := listIndex(loc, p, token, !replace)
+			if len(errs) > 0 {
+				return nil, errs
+			}
+			if !replace {
+				p = append(p, nil)
+				copy(p[i+1:], p[i:])
+			}
+			p[i] = v
+			parent, token = p, strconv.Itoa(i)
+		}
+		var errs ValidationErrors
+		written, errs = a.child(loc, parent, token)
+		return parent, errs
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	a.doc = doc
+	a.write(written)
+	return nil
+}
+
+// remove removes the value at tokens and returns it.
+func (a *jsonPatchApplier) remove(root jsonPatchLocation, tokens []string) (interface{}, ValidationErrors) {
+	if len(tokens) == 0 {
+		return nil, errorf("can't remove the whole document")
+	}
+	var removed interface{}
+	doc, errs := a.update(a.doc, root, tokens, func(parent interface{}, loc jsonPatchLocation, token string) (interface{}, ValidationErrors) {
+		var out interface{}
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			v, ok := p[token]
+			if !ok {
+				return nil, loc.errorf("field %q doesn't exist", token)
+			}
+			removed = v
+			out = p
+		case []interface{}:
+			i, errs := listIndex(loc, p, token, false)
+			if len(errs) > 0 {
+				return nil, errs
+			}
+			removed = p[i]
+			token = strconv.Itoa(i)
+			out = append(p[:i:i], p[i+1:]...)
+		}
+		child, errs := a.child(loc, parent, token)
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		if m, ok := out.(map[string]interface{}); ok {
+			delete(m, token)
+		}
+		if child.atomic {
+			// The atomic value that contains the field was changed.
+			a.write(child)
+			return out, nil
+		}
+		a.removed.Insert(child.path)
+		fields, err := AsTypedUnvalidated(value.NewValueInterface(removed), a.schema, child.typeRef).ToFieldSet()
+		if err == nil {
+			fields.Iterate(func(p fieldpath.Path) {
+				a.removed.Insert(append(child.path.Copy(), p...))
+			})
+		}
+		return out, nil
+	})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	a.doc = doc
+	return removed, nil
+}
+
+// get returns the value at tokens and its location.
+func (a *jsonPatchApplier) get(root jsonPatchLocation, tokens []string) (interface{}, jsonPatchLocation, ValidationErrors) {
+	v, loc := a.doc, root
+	for _, token := range tokens {
+		var child interface{}
+		switch parent := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if child, ok = parent[token]; !ok {
+				return nil, loc, loc.errorf("field %q doesn't exist", token)
+			}
+		case []interface{}:
+			i, errs := listIndex(loc, parent, token, false)
+			if len(errs) > 0 {
+				return nil, loc, errs
+			}
+			child = parent[i]
+			token = strconv.Itoa(i)
+		default:
+			return nil, loc, loc.errorf("can't get %q of a scalar", token)
+		}
+		next, errs := a.child(loc, v, token)
+		if len(errs) > 0 {
+			return nil, loc, errs
+		}
+		v, loc = child, next
+	}
+	return v, loc, nil
+}
+
+// update calls fn with the parent of the value at tokens, its location and
+// the last token, and returns v with the parent replaced by the result of
+// fn.
+func (a *jsonPatchApplier) update(v interface{}, loc jsonPatchLocation, tokens []string, fn func(parent interface{}, loc jsonPatchLocation, token string) (interface{}, ValidationErrors)) (interface{}, ValidationErrors) {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return nil, loc.errorf("can't get %q of a scalar", tokens[0])
+	}
+	if len(tokens) == 1 {
+		return fn(v, loc, tokens[0])
+	}
+	var child interface{}
+	switch parent := v.(type) {
+	case map[string]interface{}:
+		var ok bool
+		if child, ok = parent[tokens[0]]; !ok {
+			return nil, loc.errorf("field %q doesn't exist", tokens[0])
+		}
+		childLoc, errs := a.child(loc, v, tokens[0])
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		if parent[tokens[0]], errs = a.update(child, childLoc, tokens[1:], fn); len(errs) > 0 {
+			return nil, errs
+		}
+	case []interface{}:
+		i, errs := listIndex(loc, parent, tokens[0], false)
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		childLoc, errs := a.child(loc, v, strconv.Itoa(i))
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		if parent[i], errs = a.update(parent[i], childLoc, tokens[1:], fn); len(errs) > 0 {
+			return nil, errs
+		}
+	}
+	return v, nil
+}
+
+// child returns the location of the child of parent, a map or a list at
+// loc, for the token, which must be a field name or a valid index.
+func (a *jsonPatchApplier) child(loc jsonPatchLocation, parent interface{}, token string) (jsonPatchLocation, ValidationErrors) {
+	out := jsonPatchLocation{path: loc.path, atomic: loc.atomic}
+	atom, errs := resolveTypeRef(a.schema, loc.typeRef)
+	if len(errs) > 0 {
+		return out, errs
+	}
+	switch parent := parent.(type) {
+	case map[string]interface{}:
+		if atom.Map == nil {
+			return out, typeMismatchf(value.NewValueInterface(parent), loc.typeRef, "expected %v, got a map", atomKind(atom)).withFieldPath(loc.path)
+		}
+		out.typeRef = atom.Map.ElementType
+		if sf, ok := atom.Map.FindField(token); ok {
+			out.typeRef = sf.Type
+		}
+		out.atomic = out.atomic || atom.Map.ElementRelationship == schema.Atomic
+		if !out.atomic {
+			name := token
+			out.path = append(loc.path.Copy(), fieldpath.PathElement{FieldName: &name})
+		}
+	case []interface{}:
+		if atom.List == nil {
+			return out, typeMismatchf(value.NewValueInterface(parent), loc.typeRef, "expected %v, got a list", atomKind(atom)).withFieldPath(loc.path)
+		}
+		out.typeRef = atom.List.ElementType
+		out.atomic = out.atomic || atom.List.ElementRelationship != schema.Associative
+		if !out.atomic {
+			i, _ := strconv.Atoi(token)
+			item := value.NewValueInterface(parent[i])
+			pe, err := listItemToPathElement(value.HeapAllocator, a.schema, atom.List, item)
+			if err != nil {
+				errs := listItemErrorf(i, item, err)
+				errs[0].Path = loc.path.String()
+				errs[0].FieldPath = loc.path.Copy()
+				return out, errs
+			}
+			out.path = append(loc.path.Copy(), pe)
+		}
+	}
+	return out, nil
+}
+
+// write records that the value at loc was written.
+func (a *jsonPatchApplier) write(loc jsonPatchLocation) {
+	if len(loc.path) == 0 {
+		a.writtenRoot = true
+		return
+	}
+	a.written.Insert(loc.path)
+}
+
+// listIndex parses the token as an index of list. "-", the index past the
+// end of the list, is only allowed if end is true.
+func listIndex(loc jsonPatchLocation, list []interface{}, token string, end bool) (int, ValidationErrors) {
+	max := len(list) - 1
+	if end {
+		max = len(list)
+		if token == "-" {
+			return max, nil
+		}
+	}
+	// RFC 6901 only allows "0" or digits without a leading zero.
+	if !isListIndex(token) {
+		return 0, loc.errorf("invalid list index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, loc.errorf("invalid list index %q", token)
+	}
+	if i > max {
+		return 0, loc.errorf("list index %v is out of range", i)
+	}
+	return i, nil
+}
+
+func isListIndex(token string) bool {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return false
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseJSONPointer returns the unescaped reference tokens of the JSON
+// Pointer.
+func parseJSONPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("invalid json pointer %q, it must start with /", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+		var b strings.Builder
+		for j := 0; j < len(token); j++ {
+			if token[j] != '~' {
+				b.WriteByte(token[j])
+				continue
+			}
+			if j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1') {
+				return nil, fmt.Errorf("invalid json pointer %q, ~ must be followed by 0 or 1", ptr)
+			}
+			if token[j+1] == '0' {
+				b.WriteByte('~')
+			} else {
+				b.WriteByte('/')
+			}
+			j++
+		}
+		tokens[i] = b.String()
+	}
+	return tokens, nil
+}
+
+// deepCopyValue returns an unstructured copy of v that can be changed.
+func deepCopyValue(v value.Value) interface{} {
+	switch {
+	case v.IsMap():
+		m := v.AsMap()
+		out := make(map[string]interface{}, m.Length())
+		m.Iterate(func(key string, child value.Value) bool {
+			out[key] = deepCopyValue(child)
+			return true
+		})
+		return out
+	case v.IsList():
+		l := v.AsList()
+		out := make([]interface{}, l.Length())
+		for i := range out {
+			out[i] = deepCopyValue(l.At(i))
+		}
+		return out
+	}
+	return v.Unstructured()
+}
+
+// atomKind returns the kind of values described by the atom.
+func atomKind(a schema.Atom) string {
+	switch {
+	case a.Map != nil:
+		return "map"
+	case a.List != nil:
+		return "list"
+	}
+	return "scalar"
+}
+
+// resolveTypeRef resolves tr in s, or returns a schema error.
+func resolveTypeRef(s *schema.Schema, tr schema.TypeRef) (schema.Atom, ValidationErrors) {
+	a, ok := s.Resolve(tr)
+	if !ok {
+		typeName := "inlined type"
+		if tr.NamedType != nil {
+			typeName = *tr.NamedType
+		}
+		return a, typedErrorf(ErrorTypeSchema, "schema error: no type found matching: %v", typeName)
+	}
+	return a, nil
+}
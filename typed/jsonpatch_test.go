@@ -0,0 +1,304 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+var patchParser = func() typed.ParseableType {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+    - name: labels
+      type:
+        map:
+          elementType:
+            scalar: string
+    - name: selector
+      type:
+        map:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: args
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: finalizers
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: associative
+    - name: items
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["key"]
+- name: item
+  map:
+    fields:
+    - name: key
+      type:
+        scalar: string
+    - name: value
+      type:
+        scalar: numeric
+`)
+	if err != nil {
+		panic(err)
+	}
+	return parser.Type("root")
+}()
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		lhs, rhs typed.YAMLObject
+		expected string
+	}{{
+		name:     "same",
+		lhs:      `{"name": "a", "items": [{"key": "a"}]}`,
+		rhs:      `{"name": "a", "items": [{"key": "a"}]}`,
+		expected: `[]`,
+	}, {
+		name:     "fields",
+		lhs:      `{"name": "a", "labels": {"a": "1", "b": "2", "c/d": "3"}}`,
+		rhs:      `{"labels": {"a": "1", "b": "3", "c~d": "4"}}`,
+		expected: `[{"op":"replace","path":"/labels/b","value":"3"},{"op":"remove","path":"/labels/c~1d"},{"op":"add","path":"/labels/c~0d","value":"4"},{"op":"remove","path":"/name"}]`,
+	}, {
+		name:     "atomic",
+		lhs:      `{"selector": {"a": "1", "b": "2"}, "args": ["a", "b"]}`,
+		rhs:      `{"selector": {"a": "1"}, "args": ["a", "c"]}`,
+		expected: `[{"op":"replace","path":"/args","value":["a","c"]},{"op":"replace","path":"/selector","value":{"a":"1"}}]`,
+	}, {
+		name:     "null",
+		lhs:      `{"labels": null, "items": [{"key": "a"}]}`,
+		rhs:      `{"labels": {"a": "1"}, "items": null}`,
+		expected: `[{"op":"replace","path":"/items","value":null},{"op":"replace","path":"/labels","value":{"a":"1"}}]`,
+	}, {
+		name:     "keyed items",
+		lhs:      `{"items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}, {"key": "c", "value": 3}, {"key": "d", "value": 4}]}`,
+		rhs:      `{"items": [{"key": "d", "value": 4}, {"key": "e", "value": 5}, {"key": "b", "value": 20}]}`,
+		expected: `[{"op":"remove","path":"/items/2"},{"op":"remove","path":"/items/0"},{"op":"move","from":"/items/1","path":"/items/0"},{"op":"add","path":"/items/1","value":{"key":"e","value":5}},{"op":"replace","path":"/items/2/value","value":20}]`,
+	}, {
+		name:     "set items",
+		lhs:      `{"finalizers": ["a", "b", "c"]}`,
+		rhs:      `{"finalizers": ["c", "a", "d"]}`,
+		expected: `[{"op":"remove","path":"/finalizers/1"},{"op":"move","from":"/finalizers/1","path":"/finalizers/0"},{"op":"add","path":"/finalizers/2","value":"d"}]`,
+	}, {
+		name:     "duplicate items",
+		lhs:      `{"finalizers": ["a", "a"]}`,
+		rhs:      `{"finalizers": ["a"]}`,
+		expected: `[{"op":"replace","path":"/finalizers","value":["a"]}]`,
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			lhs, err := patchParser.FromYAML(tt.lhs, typed.AllowDuplicates)
+			if err != nil {
+				t.Fatalf("failed to parse lhs: %v", err)
+			}
+			rhs, err := patchParser.FromYAML(tt.rhs, typed.AllowDuplicates)
+			if err != nil {
+				t.Fatalf("failed to parse rhs: %v", err)
+			}
+			patch, err := lhs.JSONPatch(rhs)
+			if err != nil {
+				t.Fatalf("failed to create patch: %v", err)
+			}
+			if got := patch.String(); got != tt.expected {
+				t.Errorf("expected:\n%v\ngot:\n%v", tt.expected, got)
+			}
+			got, _, err := lhs.ApplyJSONPatch(patch, typed.AllowDuplicates)
+			if err != nil {
+				t.Fatalf("failed to apply patch: %v", err)
+			}
+			if !value.Equals(got.AsValue(), rhs.AsValue()) {
+				t.Errorf("expected patch to give:\n%v\ngot:\n%v", value.ToString(rhs.AsValue()), value.ToString(got.AsValue()))
+			}
+		})
+	}
+}
+
+func TestJSONPatchErrors(t *testing.T) {
+	lhs, err := patchParser.FromYAML(`{"items": [{"key": "a"}]}`)
+	if err != nil {
+		t.Fatalf("failed to parse lhs: %v", err)
+	}
+	v, err := value.FromJSON([]byte(`{"items": [{"value": 1}]}`))
+	if err != nil {
+		t.Fatalf("failed to parse rhs: %v", err)
+	}
+	rhs := typed.AsTypedUnvalidated(v, lhs.Schema(), lhs.TypeRef())
+	if _, err := lhs.JSONPatch(rhs); err == nil {
+		t.Error("expected an error for an item without key")
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	object := typed.YAMLObject(`{"name": "a", "labels": {"a": "1"}, "args": ["a"], "finalizers": ["a", "b"], "items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}]}`)
+	tests := []struct {
+		name     string
+		patch    string
+		expected string
+		touched  *fieldpath.Set
+	}{{
+		name:     "add",
+		patch:    `[{"op": "add", "path": "/labels/b", "value": "2"}, {"op": "add", "path": "/items/1", "value": {"key": "c"}}, {"op": "add", "path": "/finalizers/-", "value": "c"}]`,
+		expected: `{"name": "a", "labels": {"a": "1", "b": "2"}, "args": ["a"], "finalizers": ["a", "b", "c"], "items": [{"key": "a", "value": 1}, {"key": "c"}, {"key": "b", "value": 2}]}`,
+		touched: _NS(
+			_P("labels", "b"),
+			_P("items", _KBF("key", "c")),
+			_P("items", _KBF("key", "c"), "key"),
+			_P("finalizers", _V("c")),
+		),
+	}, {
+		name:     "replace with same value",
+		patch:    `[{"op": "replace", "path": "/name", "value": "a"}, {"op": "replace", "path": "/items/0", "value": {"key": "a", "value": 1}}]`,
+		expected: string(object),
+		touched: _NS(
+			_P("name"),
+			_P("items", _KBF("key", "a")),
+			_P("items", _KBF("key", "a"), "key"),
+			_P("items", _KBF("key", "a"), "value"),
+		),
+	}, {
+		name:     "remove",
+		patch:    `[{"op": "remove", "path": "/items/1"}, {"op": "remove", "path": "/labels"}, {"op": "remove", "path": "/args/0"}]`,
+		expected: `{"name": "a", "args": [], "finalizers": ["a", "b"], "items": [{"key": "a", "value": 1}]}`,
+		touched: _NS(
+			_P("items", _KBF("key", "b")),
+			_P("items", _KBF("key", "b"), "key"),
+			_P("items", _KBF("key", "b"), "value"),
+			_P("labels"),
+			_P("labels", "a"),
+			_P("args"),
+		),
+	}, {
+		name:     "move and copy",
+		patch:    `[{"op": "move", "from": "/finalizers/1", "path": "/finalizers/0"}, {"op": "copy", "from": "/labels/a", "path": "/labels/a~1b"}, {"op": "test", "path": "/labels/a~1b", "value": "1"}]`,
+		expected: `{"name": "a", "labels": {"a": "1", "a/b": "1"}, "args": ["a"], "finalizers": ["b", "a"], "items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}]}`,
+		touched: _NS(
+			_P("finalizers", _V("b")),
+			_P("labels", "a/b"),
+		),
+	}, {
+		name:     "root",
+		patch:    `[{"op": "replace", "path": "", "value": {"name": "b"}}]`,
+		expected: `{"name": "b"}`,
+		touched:  _NS(_P("name")),
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tv, err := patchParser.FromYAML(object)
+			if err != nil {
+				t.Fatalf("failed to parse object: %v", err)
+			}
+			var patch typed.JSONPatch
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("failed to parse patch: %v", err)
+			}
+			got, touched, err := tv.ApplyJSONPatch(patch)
+			if err != nil {
+				t.Fatalf("failed to apply patch: %v", err)
+			}
+			expected, err := value.FromJSON([]byte(tt.expected))
+			if err != nil {
+				t.Fatalf("failed to parse expected object: %v", err)
+			}
+			if !value.Equals(got.AsValue(), expected) {
+				t.Errorf("expected:\n%v\ngot:\n%v", value.ToString(expected), value.ToString(got.AsValue()))
+			}
+			if !touched.Equals(tt.touched) {
+				t.Errorf("expected touched fields:\n%v\ngot:\n%v", tt.touched, touched)
+			}
+			if orig, _ := patchParser.FromYAML(object); !value.Equals(tv.AsValue(), orig.AsValue()) {
+				t.Errorf("the object was changed by the patch: %v", value.ToString(tv.AsValue()))
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	tv, err := patchParser.FromYAML(`{"name": "a", "labels": {"a": "1"}, "items": [{"key": "a", "value": 1}]}`)
+	if err != nil {
+		t.Fatalf("failed to parse object: %v", err)
+	}
+	tests := []struct {
+		patch string
+		path  string
+	}{
+		{`[{"op": "remove", "path": "/labels/b"}]`, ".labels"},
+		{`[{"op": "replace", "path": "/items/1", "value": {"key": "b"}}]`, ".items"},
+		{`[{"op": "add", "path": "/items/01", "value": {"key": "b"}}]`, ".items"},
+		{`[{"op": "add", "path": "/items/+0", "value": {"key": "b"}}]`, ".items"},
+		{`[{"op": "add", "path": "/labels/a~2b", "value": "b"}]`, ""},
+		{`[{"op": "add", "path": "/labels/a~", "value": "b"}]`, ""},
+		{`[{"op": "add", "path": "/name/a", "value": "b"}]`, ".name"},
+		{`[{"op": "test", "path": "/items/0/value", "value": 2}]`, ".items[key=\"a\"].value"},
+		{`[{"op": "move", "from": "/labels", "path": "/labels/b"}]`, ""},
+		{`[{"op": "add", "path": "/items/-", "value": {"value": 2}}]`, ".items"},
+		{`[{"op": "add", "path": "/items/0/value", "value": "b"}]`, ".items[key=\"a\"].value"},
+		{`[{"op": "add", "path": "/unknown", "value": "b"}]`, ".unknown"},
+	}
+	for _, tt := range tests {
+		var patch typed.JSONPatch
+		if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+			t.Fatalf("failed to parse patch: %v", err)
+		}
+		_, _, err := tv.ApplyJSONPatch(patch)
+		if err == nil {
+			t.Errorf("expected an error applying %v", tt.patch)
+			continue
+		}
+		errs, ok := err.(typed.ValidationErrors)
+		if !ok {
+			t.Errorf("expected ValidationErrors applying %v, got %T: %v", tt.patch, err, err)
+			continue
+		}
+		if errs[0].Path != tt.path {
+			t.Errorf("expected an error at %q applying %v, got: %v", tt.path, tt.patch, err)
+		}
+	}
+	for _, patch := range []string{
+		`[{"op": "add", "path": "/name"}]`,
+		`[{"op": "move", "path": "/name"}]`,
+		`[{"op": "remove"}]`,
+		`[{"op": "unknown", "path": "/name"}]`,
+	} {
+		var p typed.JSONPatch
+		if err := json.Unmarshal([]byte(patch), &p); err == nil {
+			t.Errorf("expected an error parsing %v", patch)
+		}
+	}
+}
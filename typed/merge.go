@@ -38,6 +38,10 @@ type mergingWalker struct {
 	// probably already set.)
 	postItemHook mergeRule
 
+	// If set, rhs is a merge patch rather than an object, see
+	// mergePatchKind.
+	patch mergePatchKind
+
 	// output of the merge operation (nil if none)
 	out *interface{}
 
@@ -68,14 +72,18 @@ var (
 )
 
 // merge sets w.out.
-func (w *mergingWalker) merge(prefixFn func() string) (errs ValidationErrors) {
+func (w *mergingWalker) merge(pe *fieldpath.PathElement) (errs ValidationErrors) {
 	if w.lhs == nil && w.rhs == nil {
 		// check this condidition here instead of everywhere below.
 		return errorf("at least one of lhs and rhs must be provided")
 	}
+	if w.patch != notAPatch && w.rhs != nil && w.rhs.IsNull() {
+		// null values of merge patches remove the field.
+		return nil
+	}
 	a, ok := w.schema.Resolve(w.typeRef)
 	if !ok {
-		return errorf("schema error: no type found matching: %v", *w.typeRef.NamedType)
+		return typedErrorf(ErrorTypeSchema, "schema error: no type found matching: %v", *w.typeRef.NamedType)
 	}
 
 	alhs := deduceAtom(a, w.lhs)
@@ -96,7 +104,7 @@ func (w *mergingWalker) merge(prefixFn func() string) (errs ValidationErrors) {
 	if !w.inLeaf && w.postItemHook != nil {
 		w.postItemHook(w)
 	}
-	return errs.WithLazyPrefix(prefixFn)
+	return errs.withLazyPathElementPrefix(pe)
 }
 
 // doLeaf should be called on leaves before descending into children, if there
@@ -115,8 +123,8 @@ func (w *mergingWalker) doLeaf() {
 
 func (w *mergingWalker) doScalar(t *schema.Scalar) ValidationErrors {
 	// Make sure at least one side is a valid scalar.
-	lerrs := validateScalar(t, w.lhs, "lhs: ")
-	rerrs := validateScalar(t, w.rhs, "rhs: ")
+	lerrs := validateScalar(t, w.typeRef, w.lhs, "lhs: ")
+	rerrs := validateScalar(t, w.typeRef, w.rhs, "rhs: ")
 	if len(lerrs) > 0 && len(rerrs) > 0 {
 		return append(lerrs, rerrs...)
 	}
@@ -160,7 +168,7 @@ func (w *mergingWalker) derefMap(prefix string, v value.Value) (value.Map, Valid
 	}
 	m, err := mapValue(w.allocator, v)
 	if err != nil {
-		return nil, errorf("%v: %v", prefix, err)
+		return nil, typeMismatchf(v, w.typeRef, "%v: %v", prefix, err)
 	}
 	return m, nil
 }
@@ -274,7 +282,8 @@ func (w *mergingWalker) visitListItems(t *schema.List, lhs, rhs value.List) (err
 		}
 	}
 
-	if len(out) > 0 {
+	// Merge patches keep the lists that they empty.
+	if len(out) > 0 || (w.patch != notAPatch && rhs != nil) {
 		i := interface{}(out)
 		w.out = &i
 	}
@@ -294,14 +303,16 @@ func (w *mergingWalker) indexListPathElements(t *schema.List, list value.List, a
 		child := list.At(i)
 		pe, err := listItemToPathElement(w.allocator, w.schema, t, child)
 		if err != nil {
-			errs = append(errs, errorf("element %v: %v", i, err.Error())...)
+			errs = append(errs, listItemErrorf(i, child, err)...)
 			// If we can't construct the path element, we can't
 			// even report errors deeper in the schema, so bail on
 			// this element.
 			continue
 		}
 		if _, found := observed.Get(pe); found && !allowDuplicates {
-			errs = append(errs, errorf("duplicate entries for key %v", pe.String())...)
+			dup := typedErrorf(ErrorTypeDuplicateKey, "duplicate entries for key %v", pe.String())
+			dup[0].Value = copyValue(child)
+			errs = append(errs, dup...)
 			continue
 		} else if !found {
 			observed.Insert(pe, child)
@@ -315,10 +326,13 @@ func (w *mergingWalker) indexListPathElements(t *schema.List, list value.List, a
 }
 
 func (w *mergingWalker) mergeListItem(t *schema.List, pe fieldpath.PathElement, lChild, rChild value.Value) (out *interface{}, errs ValidationErrors) {
+	if w.patch == strategicMergePatch && isDeleteDirective(rChild) {
+		return nil, nil
+	}
 	w2 := w.prepareDescent(pe, t.ElementType)
 	w2.lhs = lChild
 	w2.rhs = rChild
-	errs = append(errs, w2.merge(pe.String)...)
+	errs = append(errs, w2.merge(&pe)...)
 	if w2.out != nil {
 		out = w2.out
 	}
@@ -332,7 +346,7 @@ func (w *mergingWalker) derefList(prefix string, v value.Value) (value.List, Val
 	}
 	l, err := listValue(w.allocator, v)
 	if err != nil {
-		return nil, errorf("%v: %v", prefix, err)
+		return nil, typeMismatchf(v, w.typeRef, "%v: %v", prefix, err)
 	}
 	return l, nil
 }
@@ -352,7 +366,8 @@ func (w *mergingWalker) doList(t *schema.List) (errs ValidationErrors) {
 	// distinction.
 	emptyPromoteToLeaf := (lhs == nil || lhs.Length() == 0) && (rhs == nil || rhs.Length() == 0)
 
-	if t.ElementRelationship == schema.Atomic || emptyPromoteToLeaf {
+	// JSON merge patches replace lists.
+	if t.ElementRelationship == schema.Atomic || emptyPromoteToLeaf || w.patch == jsonMergePatch {
 		w.doLeaf()
 		return nil
 	}
@@ -361,12 +376,28 @@ func (w *mergingWalker) doList(t *schema.List) (errs ValidationErrors) {
 		return nil
 	}
 
+	if w.patch == strategicMergePatch && rhs != nil {
+		for i := 0; i < rhs.Length(); i++ {
+			if item := rhs.At(i); item.IsMap() {
+				if err := checkDirectives(item.AsMap(), true); err != nil {
+					errs = append(errs, listItemErrorf(i, item, err)...)
+				}
+			}
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+
 	errs = w.visitListItems(t, lhs, rhs)
 
 	return errs
 }
 
 func (w *mergingWalker) visitMapItem(t *schema.Map, out map[string]interface{}, key string, lhs, rhs value.Value) (errs ValidationErrors) {
+	if w.patch == strategicMergePatch && isDeleteDirective(rhs) {
+		return nil
+	}
 	fieldType := t.ElementType
 	if sf, ok := t.FindField(key); ok {
 		fieldType = sf.Type
@@ -375,7 +406,7 @@ func (w *mergingWalker) visitMapItem(t *schema.Map, out map[string]interface{},
 	w2 := w.prepareDescent(pe, fieldType)
 	w2.lhs = lhs
 	w2.rhs = rhs
-	errs = append(errs, w2.merge(pe.String)...)
+	errs = append(errs, w2.merge(&pe)...)
 	if w2.out != nil {
 		out[key] = *w2.out
 	}
@@ -386,11 +417,23 @@ func (w *mergingWalker) visitMapItem(t *schema.Map, out map[string]interface{},
 func (w *mergingWalker) visitMapItems(t *schema.Map, lhs, rhs value.Map) (errs ValidationErrors) {
 	out := map[string]interface{}{}
 
+	if w.patch == strategicMergePatch && rhs != nil {
+		if err := checkDirectives(rhs, false); err != nil {
+			return errorf("%v", err)
+		}
+	}
 	value.MapZipUsing(w.allocator, lhs, rhs, value.Unordered, func(key string, lhsValue, rhsValue value.Value) bool {
+		if w.patch == strategicMergePatch && lhsValue == nil && isDirective(key) {
+			return true
+		}
 		errs = append(errs, w.visitMapItem(t, out, key, lhsValue, rhsValue)...)
 		return true
 	})
-	if len(out) > 0 {
+	if w.patch == strategicMergePatch && rhs != nil {
+		errs = append(errs, w.applyListDirectives(t, rhs, out)...)
+	}
+	// Merge patches keep the maps that they empty.
+	if len(out) > 0 || (w.patch != notAPatch && rhs != nil) {
 		i := interface{}(out)
 		w.out = &i
 	}
@@ -412,7 +455,8 @@ func (w *mergingWalker) doMap(t *schema.Map) (errs ValidationErrors) {
 	// distinction.
 	emptyPromoteToLeaf := (lhs == nil || lhs.Empty()) && (rhs == nil || rhs.Empty())
 
-	if t.ElementRelationship == schema.Atomic || emptyPromoteToLeaf {
+	// Merge patches merge all maps, so that they can remove fields.
+	if (t.ElementRelationship == schema.Atomic && w.patch == notAPatch) || emptyPromoteToLeaf {
 		w.doLeaf()
 		return nil
 	}
@@ -0,0 +1,404 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// mergePatchKind is the kind of patch that the rhs of a mergingWalker
+// is, if any.
+type mergePatchKind int
+
+const (
+	// notAPatch merges two objects.
+	notAPatch mergePatchKind = iota
+	// jsonMergePatch applies an RFC 7386 JSON Merge Patch: null values
+	// remove fields, maps are always merged and lists are replaced.
+	jsonMergePatch
+	// strategicMergePatch applies a strategic merge patch: like a JSON
+	// Merge Patch, but associative lists are merged by key and the
+	// directives below are honored.
+	strategicMergePatch
+)
+
+const (
+	// patchDirective is the field of the items of an associative list
+	// which, set to patchDirectiveDelete, removes the item with the same
+	// key. Set in the value of a map field, it removes the field, and set
+	// to patchDirectiveMerge, the default, it merges the map.
+	patchDirective       = "$patch"
+	patchDirectiveDelete = "delete"
+	patchDirectiveMerge  = "merge"
+	// retainKeysDirective lists the fields of a map to keep. It isn't
+	// supported.
+	retainKeysDirective = "$retainKeys"
+	// setElementOrderPrefix prefixes the name of an associative list in
+	// the fields holding the order of the items of the list: their key
+	// fields for lists with keys, or their value for sets.
+	setElementOrderPrefix = "$setElementOrder/"
+	// deleteFromPrimitiveListPrefix prefixes the name of a set in the
+	// fields holding the values to remove from the set.
+	deleteFromPrimitiveListPrefix = "$deleteFromPrimitiveList/"
+)
+
+// MergePatch returns an RFC 7386 JSON Merge Patch that turns tv into rhs.
+// Maps are patched field by field, removed fields being set to null,
+// while lists are replaced as a whole. As in any merge patch, null values
+// can't be set, so they are removed.
+//
+// tv and rhs must both be of the same type (their Schema and TypeRef must
+// match), or an error will be returned. Validation errors will be returned
+// if the objects don't conform to the schema.
+func (tv TypedValue) MergePatch(rhs *TypedValue) (value.Value, error) {
+	return mergePatch(&tv, rhs, jsonMergePatch)
+}
+
+// StrategicMergePatch returns a strategic merge patch that turns tv into
+// rhs. It is like the patch of MergePatch, but it only includes the
+// added and changed items of associative lists, with their keys. Removed
+// items are listed with a "$patch: delete" field if the list has keys,
+// or in a "$deleteFromPrimitiveList/<field>" field of the parent map for
+// sets. The order of the items is listed in a "$setElementOrder/<field>"
+// field of the parent map. Associative lists that are not the field of a
+// map are replaced as a whole.
+//
+// tv and rhs must both be of the same type (their Schema and TypeRef must
+// match), or an error will be returned. Validation errors will be returned
+// if the objects don't conform to the schema.
+func (tv TypedValue) StrategicMergePatch(rhs *TypedValue) (value.Value, error) {
+	return mergePatch(&tv, rhs, strategicMergePatch)
+}
+
+// ApplyMergePatch applies an RFC 7386 JSON Merge Patch to tv, and
+// returns the validated result.
+func (tv TypedValue) ApplyMergePatch(patch value.Value, opts ...ValidationOptions) (*TypedValue, error) {
+	return applyMergePatch(&tv, patch, jsonMergePatch, opts)
+}
+
+// ApplyStrategicMergePatch applies a strategic merge patch, as returned
+// by StrategicMergePatch, to tv, and returns the validated result. Items
+// of associative lists are merged with the items with the same key, and
+// new items are added after the existing ones unless the patch sets the
+// order of the list.
+//
+// A "$patch: delete" directive removes the list item or the map field it
+// is in, and "$patch: merge" merges it as usual. The other directives,
+// such as "$patch: replace" or "$retainKeys", aren't supported, and an
+// error naming them is returned.
+func (tv TypedValue) ApplyStrategicMergePatch(patch value.Value, opts ...ValidationOptions) (*TypedValue, error) {
+	return applyMergePatch(&tv, patch, strategicMergePatch, opts)
+}
+
+func applyMergePatch(tv *TypedValue, patch value.Value, kind mergePatchKind, opts []ValidationOptions) (*TypedValue, error) {
+	out, err := merge(tv, &TypedValue{value: patch, schema: tv.schema, typeRef: tv.typeRef}, ruleKeepRHS, nil, kind)
+	if err != nil {
+		return nil, err
+	}
+	if out.value == nil {
+		out.value = value.NewValueInterface(nil)
+	}
+	return AsTyped(out.value, out.schema, out.typeRef, opts...)
+}
+
+func mergePatch(lhs, rhs *TypedValue, kind mergePatchKind) (value.Value, error) {
+	// Compare validates both objects and tells whether there is
+	// anything to patch.
+	c, err := lhs.Compare(rhs)
+	if err != nil {
+		return nil, err
+	}
+	if c.IsSame() && value.Equals(lhs.value, rhs.value) {
+		return value.NewValueInterface(map[string]interface{}{}), nil
+	}
+	w := mergePatchWalker{schema: lhs.schema, kind: kind}
+	patch, errs := w.diff(lhs.value, rhs.value, lhs.typeRef)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return value.NewValueInterface(patch), nil
+}
+
+type mergePatchWalker struct {
+	schema *schema.Schema
+	kind   mergePatchKind
+}
+
+// diff returns the patch that turns lhs into rhs, which must be
+// different.
+func (w *mergePatchWalker) diff(lhs, rhs value.Value, tr schema.TypeRef) (interface{}, ValidationErrors) {
+	a, _ := w.schema.Resolve(tr)
+	if a.Map == nil || !lhs.IsMap() || !rhs.IsMap() {
+		return rhs.Unstructured(), nil
+	}
+	return w.diffMaps(a.Map, lhs.AsMap(), rhs.AsMap())
+}
+
+func (w *mergePatchWalker) diffMaps(t *schema.Map, lhs, rhs value.Map) (map[string]interface{}, ValidationErrors) {
+	var errs ValidationErrors
+	patch := map[string]interface{}{}
+	for _, key := range sortedKeys(lhs, rhs) {
+		lChild, lok := lhs.Get(key)
+		rChild, rok := rhs.Get(key)
+		switch {
+		case !rok:
+			patch[key] = nil
+			continue
+		case !lok:
+			patch[key] = rChild.Unstructured()
+			continue
+		case value.Equals(lChild, rChild):
+			continue
+		}
+		tr := t.ElementType
+		if sf, ok := t.FindField(key); ok {
+			tr = sf.Type
+		}
+		if a, _ := w.schema.Resolve(tr); w.kind == strategicMergePatch && a.List != nil &&
+			a.List.ElementRelationship == schema.Associative && lChild.IsList() && rChild.IsList() {
+			errs = append(errs, w.diffAssociativeLists(a.List, key, lChild, rChild, patch).withFieldNamePrefix(key)...)
+			continue
+		}
+		childPatch, childErrs := w.diff(lChild, rChild, tr)
+		errs = append(errs, childErrs.withFieldNamePrefix(key)...)
+		patch[key] = childPatch
+	}
+	return patch, errs
+}
+
+// diffAssociativeLists adds the strategic patch of the field of patch
+// named key, which turns the list lhs into rhs, to patch.
+func (w *mergePatchWalker) diffAssociativeLists(t *schema.List, key string, lhsValue, rhsValue value.Value, patch map[string]interface{}) ValidationErrors {
+	lhs, rhs := lhsValue.AsList(), rhsValue.AsList()
+	lPEs, lerrs := indexAssociativeListItems(w.schema, t, lhs)
+	rPEs, rerrs := indexAssociativeListItems(w.schema, t, rhs)
+	if errs := append(lerrs, rerrs...); len(errs) > 0 {
+		return errs
+	}
+	if lPEs == nil || rPEs == nil {
+		return errorf("items with duplicate keys can't be patched")
+	}
+	lItems := fieldpath.MakePathElementMap(len(lPEs))
+	for i, pe := range lPEs {
+		lItems.Insert(pe, lhs.At(i))
+	}
+	rKeys := fieldpath.MakePathElementSet(len(rPEs))
+	items := []interface{}{}
+	order := make([]interface{}, 0, len(rPEs))
+	var errs ValidationErrors
+	for i, pe := range rPEs {
+		rKeys.Insert(pe)
+		rItem := rhs.At(i)
+		order = append(order, pathElementPatchValue(pe))
+		l, ok := lItems.Get(pe)
+		switch {
+		case !ok:
+			items = append(items, rItem.Unstructured())
+		case len(t.Keys) > 0 && !value.Equals(l.(value.Value), rItem):
+			a, _ := w.schema.Resolve(t.ElementType)
+			if a.Map == nil || !rItem.IsMap() || !l.(value.Value).IsMap() {
+				items = append(items, rItem.Unstructured())
+				continue
+			}
+			item, itemErrs := w.diffMaps(a.Map, l.(value.Value).AsMap(), rItem.AsMap())
+			errs = append(errs, itemErrs.WithPathElementPrefix(pe)...)
+			// Items are found by their keys.
+			rMap := rItem.AsMap()
+			for _, k := range t.Keys {
+				if v, ok := rMap.Get(k); ok {
+					item[k] = v.Unstructured()
+				}
+			}
+			items = append(items, item)
+		}
+	}
+	var deleted []interface{}
+	for _, pe := range lPEs {
+		if rKeys.Has(pe) {
+			continue
+		}
+		if len(t.Keys) == 0 {
+			deleted = append(deleted, pathElementPatchValue(pe))
+			continue
+		}
+		item := pathElementPatchValue(pe).(map[string]interface{})
+		item[patchDirective] = patchDirectiveDelete
+		items = append(items, item)
+	}
+	if len(items) > 0 {
+		patch[key] = items
+	}
+	if len(deleted) > 0 {
+		patch[deleteFromPrimitiveListPrefix+key] = deleted
+	}
+	patch[setElementOrderPrefix+key] = order
+	return errs
+}
+
+// pathElementPatchValue returns the value that identifies an item of an
+// associative list in a strategic patch: a map of its keys, or its value
+// for sets.
+func pathElementPatchValue(pe fieldpath.PathElement) interface{} {
+	if pe.Value != nil {
+		return (*pe.Value).Unstructured()
+	}
+	keys := make(map[string]interface{}, len(*pe.Key))
+	for _, f := range *pe.Key {
+		keys[f.Name] = f.Value.Unstructured()
+	}
+	return keys
+}
+
+func sortedKeys(maps ...value.Map) []string {
+	keys := map[string]struct{}{}
+	for _, m := range maps {
+		m.Iterate(func(key string, _ value.Value) bool {
+			keys[key] = struct{}{}
+			return true
+		})
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func isDirective(key string) bool {
+	return key == patchDirective || key == retainKeysDirective ||
+		strings.HasPrefix(key, setElementOrderPrefix) || strings.HasPrefix(key, deleteFromPrimitiveListPrefix)
+}
+
+// checkDirectives returns an error naming the first directive of the map
+// m of a strategic patch that isn't supported, if any. The
+// "$patch: delete" directive is only supported if allowDelete is true.
+func checkDirectives(m value.Map, allowDelete bool) error {
+	if d, ok := m.Get(patchDirective); ok {
+		if !d.IsString() || (d.AsString() != patchDirectiveMerge && (!allowDelete || d.AsString() != patchDirectiveDelete)) {
+			return fmt.Errorf("unsupported strategic merge patch directive %v: %v", patchDirective, value.ToString(d))
+		}
+	}
+	if _, ok := m.Get(retainKeysDirective); ok {
+		return fmt.Errorf("unsupported strategic merge patch directive %v", retainKeysDirective)
+	}
+	return nil
+}
+
+func isDeleteDirective(v value.Value) bool {
+	if v == nil || !v.IsMap() {
+		return false
+	}
+	d, ok := v.AsMap().Get(patchDirective)
+	return ok && d.IsString() && d.AsString() == patchDirectiveDelete
+}
+
+// applyListDirectives applies the "$deleteFromPrimitiveList/<field>" and
+// "$setElementOrder/<field>" directives of the strategic patch rhs to the
+// lists of the merged map out.
+func (w *mergingWalker) applyListDirectives(t *schema.Map, rhs value.Map, out map[string]interface{}) (errs ValidationErrors) {
+	rhs.Iterate(func(key string, directive value.Value) bool {
+		var field string
+		var deleteItems bool
+		switch {
+		case strings.HasPrefix(key, deleteFromPrimitiveListPrefix):
+			field, deleteItems = strings.TrimPrefix(key, deleteFromPrimitiveListPrefix), true
+		case strings.HasPrefix(key, setElementOrderPrefix):
+			field = strings.TrimPrefix(key, setElementOrderPrefix)
+		default:
+			return true
+		}
+		list, ok := out[field].([]interface{})
+		if !ok || !directive.IsList() {
+			return true
+		}
+		tr := t.ElementType
+		if sf, ok := t.FindField(field); ok {
+			tr = sf.Type
+		}
+		a, _ := w.schema.Resolve(tr)
+		if a.List == nil || a.List.ElementRelationship != schema.Associative {
+			errs = append(errs, errorf("%v is only supported for associative lists", key)...)
+			return true
+		}
+		var err ValidationErrors
+		if deleteItems {
+			out[field], err = w.deleteFromList(a.List, list, directive.AsList())
+		} else {
+			out[field], err = w.orderList(a.List, list, directive.AsList())
+		}
+		errs = append(errs, err.withFieldNamePrefix(field)...)
+		return true
+	})
+	return errs
+}
+
+// deleteFromList removes the items of the set list that are in values.
+func (w *mergingWalker) deleteFromList(t *schema.List, list []interface{}, values value.List) ([]interface{}, ValidationErrors) {
+	deleted := fieldpath.MakePathElementSet(values.Length())
+	for i := 0; i < values.Length(); i++ {
+		v := values.At(i)
+		pe, err := setItemToPathElement(v)
+		if err != nil {
+			return list, listItemErrorf(i, v, err)
+		}
+		deleted.Insert(pe)
+	}
+	out := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		v := value.NewValueInterface(item)
+		if pe, err := setItemToPathElement(v); err == nil && deleted.Has(pe) {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// orderList sorts the items of list like the items identified by order
+// (see pathElementPatchValue). Items that are not in order are kept
+// after the others.
+func (w *mergingWalker) orderList(t *schema.List, list []interface{}, order value.List) ([]interface{}, ValidationErrors) {
+	positions := fieldpath.MakePathElementMap(order.Length())
+	for i := 0; i < order.Length(); i++ {
+		v := order.At(i)
+		pe, err := listItemToPathElement(value.HeapAllocator, w.schema, t, v)
+		if err != nil {
+			return list, listItemErrorf(i, v, err)
+		}
+		positions.Insert(pe, i)
+	}
+	position := func(item interface{}) int {
+		pe, err := listItemToPathElement(value.HeapAllocator, w.schema, t, value.NewValueInterface(item))
+		if err != nil {
+			return order.Length()
+		}
+		if p, ok := positions.Get(pe); ok {
+			return p.(int)
+		}
+		return order.Length()
+	}
+	out := append([]interface{}{}, list...)
+	sort.SliceStable(out, func(i, j int) bool { return position(out[i]) < position(out[j]) })
+	return out, nil
+}
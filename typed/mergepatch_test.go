@@ -0,0 +1,285 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+type mergePatchTestCase struct {
+	name     string
+	lhs, rhs typed.YAMLObject
+	// mergePatch and strategicPatch are the expected JSON patches.
+	mergePatch     string
+	strategicPatch string
+}
+
+var mergePatchCases = []mergePatchTestCase{{
+	name:           "same",
+	lhs:            `{"name": "a", "items": [{"key": "a"}]}`,
+	rhs:            `{"name": "a", "items": [{"key": "a"}]}`,
+	mergePatch:     `{}`,
+	strategicPatch: `{}`,
+}, {
+	name:           "fields",
+	lhs:            `{"name": "a", "labels": {"a": "1", "b": "2"}}`,
+	rhs:            `{"labels": {"a": "1", "b": "3", "c": "4"}}`,
+	mergePatch:     `{"name": null, "labels": {"b": "3", "c": "4"}}`,
+	strategicPatch: `{"name": null, "labels": {"b": "3", "c": "4"}}`,
+}, {
+	name:           "atomic",
+	lhs:            `{"selector": {"a": "1", "b": "2"}, "args": ["a", "b"]}`,
+	rhs:            `{"selector": {"a": "1"}, "args": ["a", "c"]}`,
+	mergePatch:     `{"selector": {"b": null}, "args": ["a", "c"]}`,
+	strategicPatch: `{"selector": {"b": null}, "args": ["a", "c"]}`,
+}, {
+	name:       "keyed items",
+	lhs:        `{"items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}, {"key": "c", "value": 3}]}`,
+	rhs:        `{"items": [{"key": "c", "value": 3}, {"key": "d", "value": 4}, {"key": "b"}]}`,
+	mergePatch: `{"items": [{"key": "c", "value": 3}, {"key": "d", "value": 4}, {"key": "b"}]}`,
+	strategicPatch: `{
+		"$setElementOrder/items": [{"key": "c"}, {"key": "d"}, {"key": "b"}],
+		"items": [{"key": "d", "value": 4}, {"key": "b", "value": null}, {"key": "a", "$patch": "delete"}]
+	}`,
+}, {
+	name:       "set items",
+	lhs:        `{"finalizers": ["a", "b", "c"]}`,
+	rhs:        `{"finalizers": ["c", "a", "d"]}`,
+	mergePatch: `{"finalizers": ["c", "a", "d"]}`,
+	strategicPatch: `{
+		"$setElementOrder/finalizers": ["c", "a", "d"],
+		"$deleteFromPrimitiveList/finalizers": ["b"],
+		"finalizers": ["d"]
+	}`,
+}, {
+	name:           "reordered items",
+	lhs:            `{"finalizers": ["a", "b"]}`,
+	rhs:            `{"finalizers": ["b", "a"]}`,
+	mergePatch:     `{"finalizers": ["b", "a"]}`,
+	strategicPatch: `{"$setElementOrder/finalizers": ["b", "a"]}`,
+}, {
+	name:           "removed items",
+	lhs:            `{"items": [{"key": "a"}], "finalizers": ["a"]}`,
+	rhs:            `{"items": [], "finalizers": []}`,
+	mergePatch:     `{"items": [], "finalizers": []}`,
+	strategicPatch: `{"$setElementOrder/items": [], "items": [{"key": "a", "$patch": "delete"}], "$setElementOrder/finalizers": [], "$deleteFromPrimitiveList/finalizers": ["a"]}`,
+}}
+
+func TestMergePatch(t *testing.T) {
+	for _, tt := range mergePatchCases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			lhs, err := patchParser.FromYAML(tt.lhs)
+			if err != nil {
+				t.Fatalf("failed to parse lhs: %v", err)
+			}
+			rhs, err := patchParser.FromYAML(tt.rhs)
+			if err != nil {
+				t.Fatalf("failed to parse rhs: %v", err)
+			}
+			for _, kind := range []struct {
+				name     string
+				create   func(*typed.TypedValue) (value.Value, error)
+				apply    func(value.Value, ...typed.ValidationOptions) (*typed.TypedValue, error)
+				expected string
+			}{
+				{"merge", lhs.MergePatch, lhs.ApplyMergePatch, tt.mergePatch},
+				{"strategic", lhs.StrategicMergePatch, lhs.ApplyStrategicMergePatch, tt.strategicPatch},
+			} {
+				patch, err := kind.create(rhs)
+				if err != nil {
+					t.Fatalf("failed to create %v patch: %v", kind.name, err)
+				}
+				expected, err := value.FromJSON([]byte(kind.expected))
+				if err != nil {
+					t.Fatalf("failed to parse expected %v patch: %v", kind.name, err)
+				}
+				if !value.Equals(patch, expected) {
+					t.Errorf("expected %v patch:\n%v\ngot:\n%v", kind.name, value.ToString(expected), value.ToString(patch))
+				}
+				got, err := kind.apply(patch)
+				if err != nil {
+					t.Fatalf("failed to apply %v patch: %v", kind.name, err)
+				}
+				if !value.Equals(got.AsValue(), rhs.AsValue()) {
+					t.Errorf("expected %v patch to give:\n%v\ngot:\n%v", kind.name, value.ToString(rhs.AsValue()), value.ToString(got.AsValue()))
+				}
+			}
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name            string
+		object          typed.YAMLObject
+		patch           string
+		expected        string
+		expectStrategic string
+	}{{
+		name:     "new map",
+		object:   `{}`,
+		patch:    `{"labels": {"a": null, "b": "1"}}`,
+		expected: `{"labels": {"b": "1"}}`,
+	}, {
+		name:     "emptied map",
+		object:   `{"labels": {"a": "1"}}`,
+		patch:    `{"labels": {"a": null}}`,
+		expected: `{"labels": {}}`,
+	}, {
+		name:            "merged items",
+		object:          `{"items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}], "finalizers": ["a"]}`,
+		patch:           `{"items": [{"key": "c", "value": 3}, {"key": "a", "value": null}], "finalizers": ["b"]}`,
+		expected:        `{"items": [{"key": "c", "value": 3}, {"key": "a", "value": null}], "finalizers": ["b"]}`,
+		expectStrategic: `{"items": [{"key": "c", "value": 3}, {"key": "a"}, {"key": "b", "value": 2}], "finalizers": ["a", "b"]}`,
+	}, {
+		name:     "null",
+		object:   `{"name": "a"}`,
+		patch:    `null`,
+		expected: `null`,
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			object, err := patchParser.FromYAML(tt.object)
+			if err != nil {
+				t.Fatalf("failed to parse object: %v", err)
+			}
+			patch, err := value.FromJSON([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("failed to parse patch: %v", err)
+			}
+			if tt.expectStrategic == "" {
+				tt.expectStrategic = tt.expected
+			}
+			for _, kind := range []struct {
+				name     string
+				apply    func(value.Value, ...typed.ValidationOptions) (*typed.TypedValue, error)
+				expected string
+			}{
+				{"merge", object.ApplyMergePatch, tt.expected},
+				{"strategic", object.ApplyStrategicMergePatch, tt.expectStrategic},
+			} {
+				got, err := kind.apply(patch)
+				if err != nil {
+					t.Fatalf("failed to apply %v patch: %v", kind.name, err)
+				}
+				expected, err := value.FromJSON([]byte(kind.expected))
+				if err != nil {
+					t.Fatalf("failed to parse expected object: %v", err)
+				}
+				if !value.Equals(got.AsValue(), expected) {
+					t.Errorf("expected %v patch to give:\n%v\ngot:\n%v", kind.name, value.ToString(expected), value.ToString(got.AsValue()))
+				}
+			}
+		})
+	}
+}
+
+func TestApplyMergePatchErrors(t *testing.T) {
+	object, err := patchParser.FromYAML(`{"name": "a"}`)
+	if err != nil {
+		t.Fatalf("failed to parse object: %v", err)
+	}
+	for _, patch := range []string{
+		`{"name": 1}`,
+		`{"unknown": "a"}`,
+		`{"items": [{"value": 1}]}`,
+	} {
+		p, err := value.FromJSON([]byte(patch))
+		if err != nil {
+			t.Fatalf("failed to parse patch: %v", err)
+		}
+		if _, err := object.ApplyMergePatch(p); err == nil {
+			t.Errorf("expected an error applying %v", patch)
+		}
+		if _, err := object.ApplyStrategicMergePatch(p); err == nil {
+			t.Errorf("expected an error applying strategic %v", patch)
+		}
+	}
+}
+
+func TestApplyStrategicMergePatchDirectives(t *testing.T) {
+	object, err := patchParser.FromYAML(`{"name": "a", "labels": {"a": "1"}, "items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}]}`)
+	if err != nil {
+		t.Fatalf("failed to parse object: %v", err)
+	}
+	tests := []struct {
+		name     string
+		patch    string
+		expected string
+		err      string
+	}{{
+		name:     "delete item",
+		patch:    `{"items": [{"key": "a", "$patch": "delete"}]}`,
+		expected: `{"name": "a", "labels": {"a": "1"}, "items": [{"key": "b", "value": 2}]}`,
+	}, {
+		name:     "delete field",
+		patch:    `{"labels": {"$patch": "delete"}}`,
+		expected: `{"name": "a", "items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}]}`,
+	}, {
+		name:     "merge",
+		patch:    `{"labels": {"$patch": "merge", "b": "2"}, "items": [{"key": "b", "value": 3, "$patch": "merge"}]}`,
+		expected: `{"name": "a", "labels": {"a": "1", "b": "2"}, "items": [{"key": "a", "value": 1}, {"key": "b", "value": 3}]}`,
+	}, {
+		name:  "replace field",
+		patch: `{"labels": {"$patch": "replace", "b": "2"}}`,
+		err:   "unsupported strategic merge patch directive $patch: \"replace\"",
+	}, {
+		name:  "replace list",
+		patch: `{"items": [{"$patch": "replace"}, {"key": "c"}]}`,
+		err:   "unsupported strategic merge patch directive $patch: \"replace\"",
+	}, {
+		name:  "delete root",
+		patch: `{"$patch": "delete"}`,
+		err:   "unsupported strategic merge patch directive $patch: \"delete\"",
+	}, {
+		name:  "retain keys",
+		patch: `{"labels": {"$retainKeys": ["a"], "b": "2"}}`,
+		err:   "unsupported strategic merge patch directive $retainKeys",
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := value.FromJSON([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("failed to parse patch: %v", err)
+			}
+			got, err := object.ApplyStrategicMergePatch(patch)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to apply patch: %v", err)
+			}
+			expected, err := value.FromJSON([]byte(tt.expected))
+			if err != nil {
+				t.Fatalf("failed to parse expected object: %v", err)
+			}
+			if !value.Equals(got.AsValue(), expected) {
+				t.Errorf("expected:\n%v\ngot:\n%v", value.ToString(expected), value.ToString(got.AsValue()))
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// ThreeWayConflict is a field that was changed differently by both sides
+// of a three-way merge.
+type ThreeWayConflict struct {
+	Path fieldpath.Path
+	// Base, Ours and Theirs are the values of the field in each object,
+	// or nil if the field is absent from the object.
+	Base, Ours, Theirs value.Value
+}
+
+// ThreeWayConflicts is the list of conflicts of a three-way merge.
+type ThreeWayConflicts []ThreeWayConflict
+
+// Set returns the set of the paths of the conflicts.
+func (c ThreeWayConflicts) Set() *fieldpath.Set {
+	set := fieldpath.NewSet()
+	for _, conflict := range c {
+		set.Insert(conflict.Path)
+	}
+	return set
+}
+
+// Error returns a human readable description of the conflicts.
+func (c ThreeWayConflicts) Error() string {
+	messages := []string{fmt.Sprintf("%d conflict(s):", len(c))}
+	for _, conflict := range c {
+		messages = append(messages, fmt.Sprintf("  %v: ours: %v, theirs: %v", conflict.Path, threeWayValueString(conflict.Ours), threeWayValueString(conflict.Theirs)))
+	}
+	return strings.Join(messages, "\n")
+}
+
+func threeWayValueString(v value.Value) string {
+	if v == nil {
+		return "<absent>"
+	}
+	return value.ToString(v)
+}
+
+// ThreeWayMerge merges the changes made to base by ours and by theirs.
+// Fields that only one side changed take the value of that side. The
+// schema is used to merge the fields of maps, and the items of
+// associative lists by key (or by value for sets), one by one, while
+// atomic maps and lists, other lists and scalars are merged as a whole.
+//
+// Fields that both sides changed, to different values, are conflicts.
+// They keep the value of ours in the result and are returned with both
+// values. The items of merged lists are ordered like ours, followed by the
+// items only added by theirs, in their order.
+//
+// base, ours and theirs must all be of the same type (their Schema and
+// TypeRef must match), or an error will be returned. The objects aren't
+// validated as a whole, but validation errors will be returned for the
+// parts they are merged from, if their types can't be resolved or if the
+// items of their associative lists can't be keyed, e.g. for missing or
+// duplicate keys. The result is validated with opts, and validation
+// errors are returned if it doesn't conform to the schema.
+func ThreeWayMerge(base, ours, theirs *TypedValue, opts ...ValidationOptions) (*TypedValue, ThreeWayConflicts, error) {
+	if err := checkSameType(base, ours); err != nil {
+		return nil, nil, err
+	}
+	if err := checkSameType(base, theirs); err != nil {
+		return nil, nil, err
+	}
+	w := threeWayMerger{schema: base.schema}
+	out, errs := w.merge(base.value, ours.value, theirs.value, base.typeRef)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	if out == nil {
+		out = value.NewValueInterface(nil)
+	}
+	result, err := AsTyped(out, base.schema, base.typeRef, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, w.conflicts, nil
+}
+
+type threeWayMerger struct {
+	schema    *schema.Schema
+	path      fieldpath.Path
+	conflicts ThreeWayConflicts
+}
+
+// merge returns the merge of the three values, which are nil when
+// absent, or nil if the merged field is absent.
+func (w *threeWayMerger) merge(base, ours, theirs value.Value, tr schema.TypeRef) (value.Value, ValidationErrors) {
+	switch {
+	case threeWayEquals(ours, theirs), threeWayEquals(base, theirs):
+		return ours, nil
+	case threeWayEquals(base, ours):
+		return theirs, nil
+	}
+	a, errs := resolveTypeRef(w.schema, tr)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	baseIsNil := base == nil || base.IsNull()
+	switch {
+	case ours == nil || theirs == nil:
+		// One side removed the field, and the other changed it.
+	case a.Map != nil && a.Map.ElementRelationship != schema.Atomic &&
+		ours.IsMap() && theirs.IsMap() && (baseIsNil || base.IsMap()):
+		return w.mergeMaps(a.Map, base, ours, theirs)
+	case a.List != nil && a.List.ElementRelationship == schema.Associative &&
+		ours.IsList() && theirs.IsList() && (baseIsNil || base.IsList()):
+		return w.mergeAssociativeLists(a.List, base, ours, theirs)
+	}
+	w.conflict(base, ours, theirs)
+	return ours, nil
+}
+
+func (w *threeWayMerger) mergeMaps(t *schema.Map, base, ours, theirs value.Value) (value.Value, ValidationErrors) {
+	var baseMap value.Map
+	if base != nil && base.IsMap() {
+		baseMap = base.AsMap()
+	}
+	oursMap, theirsMap := ours.AsMap(), theirs.AsMap()
+	maps := []value.Map{oursMap, theirsMap}
+	if baseMap != nil {
+		maps = append(maps, baseMap)
+	}
+
+	out := map[string]interface{}{}
+	var errs ValidationErrors
+	for _, key := range sortedKeys(maps...) {
+		tr := t.ElementType
+		if sf, ok := t.FindField(key); ok {
+			tr = sf.Type
+		}
+		name := key
+		w.path = append(w.path, fieldpath.PathElement{FieldName: &name})
+		child, childErrs := w.merge(mapChild(baseMap, key), mapChild(oursMap, key), mapChild(theirsMap, key), tr)
+		w.path = w.path[:len(w.path)-1]
+		errs = append(errs, childErrs.withFieldNamePrefix(key)...)
+		if child != nil {
+			out[key] = child.Unstructured()
+		}
+	}
+	return value.NewValueInterface(out), errs
+}
+
+func (w *threeWayMerger) mergeAssociativeLists(t *schema.List, base, ours, theirs value.Value) (value.Value, ValidationErrors) {
+	var baseList value.List
+	if base != nil && base.IsList() {
+		baseList = base.AsList()
+	}
+	oursList, theirsList := ours.AsList(), theirs.AsList()
+	var basePEs []fieldpath.PathElement
+	var errs ValidationErrors
+	if baseList != nil {
+		basePEs, errs = indexAssociativeListItems(w.schema, t, baseList)
+	}
+	oursPEs, oursErrs := indexAssociativeListItems(w.schema, t, oursList)
+	theirsPEs, theirsErrs := indexAssociativeListItems(w.schema, t, theirsList)
+	if errs = append(append(errs, oursErrs...), theirsErrs...); len(errs) > 0 {
+		return nil, errs
+	}
+	if (baseList != nil && basePEs == nil) || oursPEs == nil || theirsPEs == nil {
+		// Items with duplicate keys can't be told apart.
+		w.conflict(base, ours, theirs)
+		return ours, nil
+	}
+
+	baseItems := fieldpath.MakePathElementValueMap(len(basePEs))
+	for i, pe := range basePEs {
+		baseItems.Insert(pe, baseList.At(i))
+	}
+	oursItems := fieldpath.MakePathElementValueMap(len(oursPEs))
+	for i, pe := range oursPEs {
+		oursItems.Insert(pe, oursList.At(i))
+	}
+	theirsItems := fieldpath.MakePathElementValueMap(len(theirsPEs))
+	for i, pe := range theirsPEs {
+		theirsItems.Insert(pe, theirsList.At(i))
+	}
+
+	// Items are ordered like ours, followed by the items of theirs that
+	// aren't in ours. Items that ours removed and theirs changed are
+	// conflicts, which keep ours, so they are dropped.
+	order := append([]fieldpath.PathElement{}, oursPEs...)
+	for _, pe := range theirsPEs {
+		if _, ok := oursItems.Get(pe); !ok {
+			order = append(order, pe)
+		}
+	}
+	out := []interface{}{}
+	for _, pe := range order {
+		w.path = append(w.path, pe)
+		child, childErrs := w.merge(pathElementChild(baseItems, pe), pathElementChild(oursItems, pe), pathElementChild(theirsItems, pe), t.ElementType)
+		w.path = w.path[:len(w.path)-1]
+		errs = append(errs, childErrs.WithPathElementPrefix(pe)...)
+		if child != nil {
+			out = append(out, child.Unstructured())
+		}
+	}
+	return value.NewValueInterface(out), errs
+}
+
+// conflict records a conflict at the current path.
+func (w *threeWayMerger) conflict(base, ours, theirs value.Value) {
+	w.conflicts = append(w.conflicts, ThreeWayConflict{
+		Path:   w.path.Copy(),
+		Base:   base,
+		Ours:   ours,
+		Theirs: theirs,
+	})
+}
+
+// threeWayEquals returns true if lhs and rhs are equal, or both absent.
+func threeWayEquals(lhs, rhs value.Value) bool {
+	if lhs == nil || rhs == nil {
+		return lhs == nil && rhs == nil
+	}
+	return value.Equals(lhs, rhs)
+}
+
+// mapChild returns the value of the key in m, or nil if m is nil or
+// doesn't have the key.
+func mapChild(m value.Map, key string) value.Value {
+	if m == nil {
+		return nil
+	}
+	v, ok := m.Get(key)
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+// pathElementChild returns the item of the path element in m, or nil if
+// m doesn't have it.
+func pathElementChild(m fieldpath.PathElementValueMap, pe fieldpath.PathElement) value.Value {
+	v, ok := m.Get(pe)
+	if !ok {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+func TestThreeWayMerge(t *testing.T) {
+	tests := []struct {
+		name                   string
+		base, ours, theirs     typed.YAMLObject
+		expected               string
+		conflicts              *fieldpath.Set
+		oursValue, theirsValue string
+	}{{
+		name:      "no changes",
+		base:      `{"name": "a", "labels": {"a": "1"}}`,
+		ours:      `{"name": "a", "labels": {"a": "1"}}`,
+		theirs:    `{"name": "a", "labels": {"a": "1"}}`,
+		expected:  `{"name": "a", "labels": {"a": "1"}}`,
+		conflicts: _NS(),
+	}, {
+		name:      "separate fields",
+		base:      `{"name": "a", "labels": {"a": "1", "b": "2", "c": "3"}}`,
+		ours:      `{"name": "b", "labels": {"a": "1", "c": "3", "d": "4"}}`,
+		theirs:    `{"name": "a", "labels": {"a": "10", "b": "2"}}`,
+		expected:  `{"name": "b", "labels": {"a": "10", "d": "4"}}`,
+		conflicts: _NS(),
+	}, {
+		name:      "same changes",
+		base:      `{"name": "a"}`,
+		ours:      `{"name": "b", "labels": {"a": "1"}}`,
+		theirs:    `{"name": "b", "labels": {"a": "1"}}`,
+		expected:  `{"name": "b", "labels": {"a": "1"}}`,
+		conflicts: _NS(),
+	}, {
+		name:        "conflicting leaf",
+		base:        `{"name": "a", "labels": {"a": "1"}}`,
+		ours:        `{"name": "b", "labels": {"a": "1"}}`,
+		theirs:      `{"name": "c", "labels": {"a": "2"}}`,
+		expected:    `{"name": "b", "labels": {"a": "2"}}`,
+		conflicts:   _NS(_P("name")),
+		oursValue:   `"b"`,
+		theirsValue: `"c"`,
+	}, {
+		name:        "atomic",
+		base:        `{"selector": {"a": "1"}, "args": ["a"]}`,
+		ours:        `{"selector": {"a": "1", "b": "2"}, "args": ["a"]}`,
+		theirs:      `{"selector": {"a": "1", "c": "3"}, "args": ["b"]}`,
+		expected:    `{"selector": {"a": "1", "b": "2"}, "args": ["b"]}`,
+		conflicts:   _NS(_P("selector")),
+		oursValue:   `{"a": "1", "b": "2"}`,
+		theirsValue: `{"a": "1", "c": "3"}`,
+	}, {
+		name:      "keyed items",
+		base:      `{"items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}, {"key": "c", "value": 3}]}`,
+		ours:      `{"items": [{"key": "c", "value": 3}, {"key": "a", "value": 10}, {"key": "d", "value": 4}]}`,
+		theirs:    `{"items": [{"key": "a", "value": 1}, {"key": "e", "value": 5}, {"key": "b", "value": 2}, {"key": "c", "value": 30}]}`,
+		expected:  `{"items": [{"key": "c", "value": 30}, {"key": "a", "value": 10}, {"key": "d", "value": 4}, {"key": "e", "value": 5}]}`,
+		conflicts: _NS(),
+	}, {
+		name:        "removed and changed item",
+		base:        `{"items": [{"key": "a", "value": 1}, {"key": "b", "value": 2}]}`,
+		ours:        `{"items": [{"key": "b", "value": 2}]}`,
+		theirs:      `{"items": [{"key": "a", "value": 10}, {"key": "b", "value": 2}]}`,
+		expected:    `{"items": [{"key": "b", "value": 2}]}`,
+		conflicts:   _NS(_P("items", _KBF("key", "a"))),
+		theirsValue: `{"key": "a", "value": 10}`,
+	}, {
+		name:      "set items",
+		base:      `{"finalizers": ["a", "b"]}`,
+		ours:      `{"finalizers": ["a", "c"]}`,
+		theirs:    `{"finalizers": ["d", "b", "a"]}`,
+		expected:  `{"finalizers": ["a", "c", "d"]}`,
+		conflicts: _NS(),
+	}, {
+		name:        "duplicates",
+		base:        `{"finalizers": ["a"]}`,
+		ours:        `{"finalizers": ["a", "a"]}`,
+		theirs:      `{"finalizers": ["b"]}`,
+		expected:    `{"finalizers": ["a", "a"]}`,
+		conflicts:   _NS(_P("finalizers")),
+		oursValue:   `["a", "a"]`,
+		theirsValue: `["b"]`,
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var objects []*typed.TypedValue
+			for _, obj := range []typed.YAMLObject{tt.base, tt.ours, tt.theirs} {
+				tv, err := patchParser.FromYAML(obj, typed.AllowDuplicates)
+				if err != nil {
+					t.Fatalf("failed to parse object: %v", err)
+				}
+				objects = append(objects, tv)
+			}
+			got, conflicts, err := typed.ThreeWayMerge(objects[0], objects[1], objects[2], typed.AllowDuplicates)
+			if err != nil {
+				t.Fatalf("failed to merge: %v", err)
+			}
+			expected, err := value.FromJSON([]byte(tt.expected))
+			if err != nil {
+				t.Fatalf("failed to parse expected object: %v", err)
+			}
+			if !value.Equals(got.AsValue(), expected) {
+				t.Errorf("expected:\n%v\ngot:\n%v", value.ToString(expected), value.ToString(got.AsValue()))
+			}
+			if !conflicts.Set().Equals(tt.conflicts) {
+				t.Errorf("expected conflicts:\n%v\ngot:\n%v", tt.conflicts, conflicts.Set())
+			}
+			if len(conflicts) != 1 {
+				return
+			}
+			for _, side := range []struct {
+				got      value.Value
+				expected string
+			}{{conflicts[0].Ours, tt.oursValue}, {conflicts[0].Theirs, tt.theirsValue}} {
+				if side.expected == "" {
+					if side.got != nil {
+						t.Errorf("expected an absent value, got %v", value.ToString(side.got))
+					}
+					continue
+				}
+				expected, err := value.FromJSON([]byte(side.expected))
+				if err != nil {
+					t.Fatalf("failed to parse expected value: %v", err)
+				}
+				if side.got == nil || !value.Equals(side.got, expected) {
+					t.Errorf("expected conflicting value %v, got %v", side.expected, side.got)
+				}
+			}
+		})
+	}
+}
@@ -30,6 +30,12 @@ type ValidationOptions int
 const (
 	// AllowDuplicates means that sets and associative lists can have duplicate similar items.
 	AllowDuplicates ValidationOptions = iota
+	// RequireComplete means that the value is a complete object, which
+	// must have the required fields of its maps, and the minimum number
+	// of items of its lists. These constraints aren't checked otherwise,
+	// since partial objects, like apply configurations, don't satisfy
+	// them.
+	RequireComplete
 )
 
 // extractItemsOptions is the options available when extracting items.
@@ -108,10 +114,12 @@ func (tv TypedValue) Validate(opts ...ValidationOptions) error {
 		switch opt {
 		case AllowDuplicates:
 			w.allowDuplicates = true
+		case RequireComplete:
+			w.requireComplete = true
 		}
 	}
 	defer w.finished()
-	if errs := w.validate(nil); len(errs) != 0 {
+	if errs := w.validate(); len(errs) != 0 {
 		return errs
 	}
 	return nil
@@ -141,7 +149,58 @@ func (tv TypedValue) ToFieldSet() (*fieldpath.Set, error) {
 // match), or an error will be returned. Validation errors will be returned if
 // the objects don't conform to the schema.
 func (tv TypedValue) Merge(pso *TypedValue) (*TypedValue, error) {
-	return merge(&tv, pso, ruleKeepRHS, nil)
+	return merge(&tv, pso, ruleKeepRHS, nil, notAPatch)
+}
+
+// NormalizeUnions takes the new object and normalizes the unions in it
+// against tv, the previous version of the object:
+//   - If the discriminator changed to non-nil, and a new field has been
+//     added that doesn't match, an error is returned,
+//   - If the discriminator hasn't changed and two fields or more are set,
+//     an error is returned,
+//   - If the discriminator changed to non-nil, all other fields but the
+//     discriminated one will be cleared,
+//   - Otherwise, if only one field is left, the discriminator is set to
+//     that field if it was missing, or if it was invalid and the union
+//     allows deducing it.
+//
+// tv and new must both be of the same type (their Schema and TypeRef must
+// match), or an error will be returned.
+func (tv TypedValue) NormalizeUnions(new *TypedValue) (*TypedValue, error) {
+	if err := checkSameType(&tv, new); err != nil {
+		return nil, err
+	}
+	out, changed, errs := normalizeUnionsWithSchema(new.value, tv.value, new.schema, new.typeRef, false)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if !changed {
+		return new, nil
+	}
+	return &TypedValue{value: out, schema: new.schema, typeRef: new.typeRef}, nil
+}
+
+// NormalizeUnionsApply normalizes the unions of merged, the result of
+// merging tv, an applied configuration, into the live object. It checks
+// that at most one field of each union was applied, and that it matches
+// the applied discriminator, if any. All the other fields of the union
+// are cleared from merged and the discriminator is set to match the
+// applied field.
+//
+// tv and merged must both be of the same type (their Schema and TypeRef
+// must match), or an error will be returned.
+func (tv TypedValue) NormalizeUnionsApply(merged *TypedValue) (*TypedValue, error) {
+	if err := checkSameType(&tv, merged); err != nil {
+		return nil, err
+	}
+	out, changed, errs := normalizeUnionsWithSchema(merged.value, tv.value, merged.schema, merged.typeRef, true)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if !changed {
+		return merged, nil
+	}
+	return &TypedValue{value: out, schema: merged.schema, typeRef: merged.typeRef}, nil
 }
 
 var cmpwPool = sync.Pool{
@@ -149,18 +208,16 @@ var cmpwPool = sync.Pool{
 }
 
 // Compare compares the two objects. See the comments on the `Comparison`
-// struct for details on the return value.
+// struct for details on the return value. opts can configure how scalar
+// fields are compared.
 //
 // tv and rhs must both be of the same type (their Schema and TypeRef must
 // match), or an error will be returned. Validation errors will be returned if
 // the objects don't conform to the schema.
-func (tv TypedValue) Compare(rhs *TypedValue) (c *Comparison, err error) {
+func (tv TypedValue) Compare(rhs *TypedValue, opts ...CompareOption) (c *Comparison, err error) {
 	lhs := tv
-	if lhs.schema != rhs.schema {
-		return nil, errorf("expected objects with types from the same schema")
-	}
-	if !lhs.typeRef.Equals(&rhs.typeRef) {
-		return nil, errorf("expected objects of the same type, but got %v and %v", lhs.typeRef, rhs.typeRef)
+	if err := checkSameType(&lhs, rhs); err != nil {
+		return nil, err
 	}
 
 	cmpw := cmpwPool.Get().(*compareWalker)
@@ -170,6 +227,7 @@ func (tv TypedValue) Compare(rhs *TypedValue) (c *Comparison, err error) {
 		cmpw.schema = nil
 		cmpw.typeRef = schema.TypeRef{}
 		cmpw.comparison = nil
+		cmpw.options = nil
 		cmpw.inLeaf = false
 
 		cmpwPool.Put(cmpw)
@@ -179,6 +237,12 @@ func (tv TypedValue) Compare(rhs *TypedValue) (c *Comparison, err error) {
 	cmpw.rhs = rhs.value
 	cmpw.schema = lhs.schema
 	cmpw.typeRef = lhs.typeRef
+	if len(opts) > 0 {
+		cmpw.options = &compareOptions{}
+		for _, opt := range opts {
+			opt(cmpw.options)
+		}
+	}
 	cmpw.comparison = &Comparison{
 		Removed:  fieldpath.NewSet(),
 		Modified: fieldpath.NewSet(),
@@ -246,12 +310,21 @@ var mwPool = sync.Pool{
 	New: func() interface{} { return &mergingWalker{} },
 }
 
-func merge(lhs, rhs *TypedValue, rule, postRule mergeRule) (*TypedValue, error) {
+// checkSameType returns an error if lhs and rhs don't have the same
+// Schema and TypeRef.
+func checkSameType(lhs, rhs *TypedValue) error {
 	if lhs.schema != rhs.schema {
-		return nil, errorf("expected objects with types from the same schema")
+		return errorf("expected objects with types from the same schema")
 	}
 	if !lhs.typeRef.Equals(&rhs.typeRef) {
-		return nil, errorf("expected objects of the same type, but got %v and %v", lhs.typeRef, rhs.typeRef)
+		return errorf("expected objects of the same type, but got %v and %v", lhs.typeRef, rhs.typeRef)
+	}
+	return nil
+}
+
+func merge(lhs, rhs *TypedValue, rule, postRule mergeRule, patch mergePatchKind) (*TypedValue, error) {
+	if err := checkSameType(lhs, rhs); err != nil {
+		return nil, err
 	}
 
 	mw := mwPool.Get().(*mergingWalker)
@@ -262,6 +335,7 @@ func merge(lhs, rhs *TypedValue, rule, postRule mergeRule) (*TypedValue, error)
 		mw.typeRef = schema.TypeRef{}
 		mw.rule = nil
 		mw.postItemHook = nil
+		mw.patch = notAPatch
 		mw.out = nil
 		mw.inLeaf = false
 
@@ -274,6 +348,7 @@ func merge(lhs, rhs *TypedValue, rule, postRule mergeRule) (*TypedValue, error)
 	mw.typeRef = lhs.typeRef
 	mw.rule = rule
 	mw.postItemHook = postRule
+	mw.patch = patch
 	if mw.allocator == nil {
 		mw.allocator = value.NewFreelistAllocator()
 	}
@@ -0,0 +1,404 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
+)
+
+// validateUnions returns an error for each union of t that has more than
+// one of its fields set in m.
+func validateUnions(t *schema.Map, m value.Map) (errs ValidationErrors) {
+	for i := range t.Unions {
+		u := newUnion(&t.Unions[i])
+		if set := newFieldsSet(m, u.f); len(set) > 1 {
+			errs = append(errs, typedErrorf(ErrorTypeUnion, "more than one field of union set: %v", set)...)
+		}
+	}
+	return errs
+}
+
+type unionWalker struct {
+	// value is the object being normalized.
+	value value.Value
+	// other is the corresponding part of the reference object: the
+	// old object for an update, or the applied configuration for an
+	// apply. It may be nil.
+	other value.Value
+
+	// out is only set if changed is true.
+	out     interface{}
+	changed bool
+
+	schema    *schema.Schema
+	apply     bool
+	allocator value.Allocator
+}
+
+// normalizeUnionsWithSchema walks val and normalizes all the unions of the
+// schema against other. See the comments on NormalizeUnions and
+// NormalizeUnionsApply. val is returned as is, and changed is false, if no
+// union were found.
+func normalizeUnionsWithSchema(val, other value.Value, s *schema.Schema, typeRef schema.TypeRef, apply bool) (out value.Value, changed bool, errs ValidationErrors) {
+	w := &unionWalker{
+		value:     val,
+		other:     other,
+		schema:    s,
+		apply:     apply,
+		allocator: value.NewFreelistAllocator(),
+	}
+	errs = resolveSchema(s, typeRef, val, w)
+	if !w.changed {
+		return val, false, errs
+	}
+	return value.NewValueInterface(w.out), true, errs
+}
+
+func (w *unionWalker) doScalar(t *schema.Scalar) ValidationErrors {
+	return nil
+}
+
+func (w *unionWalker) doList(t *schema.List) (errs ValidationErrors) {
+	// Unions inside of atomic lists are not normalized, just like
+	// they are not merged.
+	if !w.value.IsList() || t.ElementRelationship != schema.Associative {
+		return nil
+	}
+	l := w.value.AsListUsing(w.allocator)
+	defer w.allocator.Free(l)
+
+	others := fieldpath.MakePathElementValueMap(0)
+	if w.other != nil && w.other.IsList() {
+		ol := w.other.AsListUsing(w.allocator)
+		defer w.allocator.Free(ol)
+		others = fieldpath.MakePathElementValueMap(ol.Length())
+		for i := 0; i < ol.Length(); i++ {
+			item := ol.At(i)
+			pe, err := listItemToPathElement(w.allocator, w.schema, t, item)
+			if err != nil {
+				continue
+			}
+			if _, found := others.Get(pe); found {
+				// Duplicated items can't be matched, make them null.
+				others.Insert(pe, value.NewValueInterface(nil))
+			} else {
+				others.Insert(pe, item)
+			}
+		}
+	}
+
+	changedItems := map[int]interface{}{}
+	for i := 0; i < l.Length(); i++ {
+		item := l.At(i)
+		pe, err := listItemToPathElement(w.allocator, w.schema, t, item)
+		if err != nil {
+			continue
+		}
+		other, _ := others.Get(pe)
+		out, changed, itemErrs := normalizeUnionsWithSchema(item, other, w.schema, t.ElementType, w.apply)
+		errs = append(errs, itemErrs.WithPathElementPrefix(pe)...)
+		if changed {
+			changedItems[i] = out.Unstructured()
+		}
+	}
+	if len(changedItems) == 0 {
+		return errs
+	}
+	newItems := make([]interface{}, l.Length())
+	for i := range newItems {
+		if item, ok := changedItems[i]; ok {
+			newItems[i] = item
+		} else {
+			newItems[i] = l.At(i).Unstructured()
+		}
+	}
+	w.out = newItems
+	w.changed = true
+	return errs
+}
+
+func (w *unionWalker) doMap(t *schema.Map) (errs ValidationErrors) {
+	if !w.value.IsMap() || t.ElementRelationship == schema.Atomic {
+		return nil
+	}
+	m := w.value.AsMapUsing(w.allocator)
+	defer w.allocator.Free(m)
+	var other value.Map
+	if w.other != nil && w.other.IsMap() {
+		other = w.other.AsMapUsing(w.allocator)
+		defer w.allocator.Free(other)
+	}
+
+	changedFields := map[string]interface{}{}
+	m.Iterate(func(k string, val value.Value) bool {
+		fieldType := t.ElementType
+		if sf, ok := t.FindField(k); ok {
+			fieldType = sf.Type
+		}
+		var otherVal value.Value
+		if other != nil {
+			otherVal, _ = other.Get(k)
+		}
+		out, changed, fieldErrs := normalizeUnionsWithSchema(val, otherVal, w.schema, fieldType, w.apply)
+		errs = append(errs, fieldErrs.WithPathElementPrefix(fieldpath.PathElement{FieldName: &k})...)
+		if changed {
+			changedFields[k] = out.Unstructured()
+		}
+		return true
+	})
+	// Maps with unions are always copied since they may be modified.
+	if len(changedFields) == 0 && len(t.Unions) == 0 {
+		return errs
+	}
+	newMap := make(map[string]interface{}, m.Length())
+	m.Iterate(func(k string, val value.Value) bool {
+		if v, ok := changedFields[k]; ok {
+			newMap[k] = v
+		} else {
+			newMap[k] = val.Unstructured()
+		}
+		return true
+	})
+	w.out = newMap
+	w.changed = true
+
+	out := value.NewValueInterface(newMap).AsMap()
+	for i := range t.Unions {
+		u := newUnion(&t.Unions[i])
+		var err error
+		if w.apply {
+			err = u.NormalizeApply(other, out)
+		} else {
+			err = u.Normalize(other, m, out)
+		}
+		if err != nil {
+			errs = append(errs, errorf("%v", err)...)
+		}
+	}
+	return errs
+}
+
+type discriminated string
+type field string
+
+type discriminatedNames struct {
+	f2d map[field]discriminated
+	d2f map[discriminated]field
+}
+
+func newDiscriminatedName(f2d map[field]discriminated) discriminatedNames {
+	d2f := map[discriminated]field{}
+	for key, value := range f2d {
+		d2f[value] = key
+	}
+	return discriminatedNames{
+		f2d: f2d,
+		d2f: d2f,
+	}
+}
+
+func (dn discriminatedNames) toField(d discriminated) field {
+	if f, ok := dn.d2f[d]; ok {
+		return f
+	}
+	return field(d)
+}
+
+func (dn discriminatedNames) toDiscriminated(f field) discriminated {
+	if d, ok := dn.f2d[f]; ok {
+		return d
+	}
+	return discriminated(f)
+}
+
+type discriminator struct {
+	name string
+}
+
+func (d *discriminator) Set(m value.Map, v discriminated) {
+	if d == nil {
+		return
+	}
+	m.Set(d.name, value.NewValueInterface(string(v)))
+}
+
+func (d *discriminator) Get(m value.Map) discriminated {
+	if d == nil || m == nil {
+		return ""
+	}
+	val, ok := m.Get(d.name)
+	if !ok {
+		return ""
+	}
+	if !val.IsString() {
+		return ""
+	}
+	return discriminated(val.AsString())
+}
+
+type fieldsSet map[field]struct{}
+
+// newFieldsSet returns a map of the fields that are part of the union and are set
+// in the given map.
+func newFieldsSet(m value.Map, fields []field) fieldsSet {
+	if m == nil {
+		return nil
+	}
+	set := fieldsSet{}
+	for _, f := range fields {
+		if subField, ok := m.Get(string(f)); ok && !subField.IsNull() {
+			set.Add(f)
+		}
+	}
+	return set
+}
+
+func (fs fieldsSet) Add(f field) {
+	fs[f] = struct{}{}
+}
+
+func (fs fieldsSet) One() *field {
+	for f := range fs {
+		return &f
+	}
+	return nil
+}
+
+func (fs fieldsSet) Has(f field) bool {
+	_, ok := fs[f]
+	return ok
+}
+
+func (fs fieldsSet) Difference(o fieldsSet) fieldsSet {
+	n := fieldsSet{}
+	for f := range fs {
+		if !o.Has(f) {
+			n.Add(f)
+		}
+	}
+	return n
+}
+
+func (fs fieldsSet) String() string {
+	s := []string{}
+	for k := range fs {
+		s = append(s, string(k))
+	}
+	sort.Strings(s)
+	return strings.Join(s, ", ")
+}
+
+type union struct {
+	deduceInvalidDiscriminator bool
+	d                          *discriminator
+	dn                         discriminatedNames
+	f                          []field
+}
+
+func newUnion(su *schema.Union) *union {
+	u := &union{}
+	if su.Discriminator != nil {
+		u.d = &discriminator{name: *su.Discriminator}
+	}
+	f2d := map[field]discriminated{}
+	for _, f := range su.Fields {
+		u.f = append(u.f, field(f.FieldName))
+		f2d[field(f.FieldName)] = discriminated(f.DiscriminatorValue)
+	}
+	u.dn = newDiscriminatedName(f2d)
+	u.deduceInvalidDiscriminator = su.DeduceInvalidDiscriminator
+	return u
+}
+
+// clear removes all the fields in map that are part of the union, but
+// the one we decided to keep.
+func (u *union) clear(m value.Map, f field) {
+	for _, fieldName := range u.f {
+		if field(fieldName) != f {
+			m.Delete(string(fieldName))
+		}
+	}
+}
+
+// Normalize normalizes the union after an update, where old is the
+// previous version of the object, new the updated version and out the
+// map to be modified.
+func (u *union) Normalize(old, new, out value.Map) error {
+	os := newFieldsSet(old, u.f)
+	ns := newFieldsSet(new, u.f)
+	diff := ns.Difference(os)
+
+	if u.d.Get(old) != u.d.Get(new) && u.d.Get(new) != "" {
+		if len(diff) == 1 && u.d.Get(new) != u.dn.toDiscriminated(*diff.One()) {
+			return fmt.Errorf("discriminator (%v) and field changed (%v) don't match", u.d.Get(new), *diff.One())
+		}
+		if len(diff) > 1 {
+			return fmt.Errorf("multiple new fields added: %v", diff)
+		}
+		u.clear(out, u.dn.toField(u.d.Get(new)))
+		return nil
+	}
+
+	if len(ns) > 1 {
+		return fmt.Errorf("multiple fields set without discriminator change: %v", ns)
+	}
+
+	// Set the discriminator if it's missing, or if it's invalid and
+	// needs to be deduced.
+	if len(ns) == 1 {
+		want := u.dn.toDiscriminated(*ns.One())
+		if got := u.d.Get(new); got == "" || (got != want && u.deduceInvalidDiscriminator) {
+			u.d.Set(out, want)
+		}
+	}
+
+	return nil
+}
+
+// NormalizeApply normalizes the union after an apply, where applied is
+// the applied configuration and out the merged object to be modified.
+func (u *union) NormalizeApply(applied, out value.Map) error {
+	as := newFieldsSet(applied, u.f)
+	if len(as) > 1 {
+		return fmt.Errorf("more than one field of union applied: %v", as)
+	}
+	if len(as) == 0 {
+		// If only the discriminator was applied, clear the fields
+		// that it doesn't select.
+		if d := u.d.Get(applied); d != "" {
+			u.clear(out, u.dn.toField(d))
+		}
+		return nil
+	}
+	// We have exactly one, discriminator must match if set, unless
+	// we're allowed to deduce it.
+	want := u.dn.toDiscriminated(*as.One())
+	if got := u.d.Get(applied); got != "" && got != want && !u.deduceInvalidDiscriminator {
+		return fmt.Errorf("applied discriminator (%v) doesn't match applied field (%v)", got, *as.One())
+	}
+
+	u.d.Set(out, want)
+	u.clear(out, *as.One())
+
+	return nil
+}
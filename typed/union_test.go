@@ -0,0 +1,331 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typed_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+)
+
+var unionParser = func() typed.ParseableType {
+	parser, err := typed.NewParser(`types:
+- name: union
+  map:
+    fields:
+    - name: discriminator
+      type:
+        scalar: string
+    - name: one
+      type:
+        scalar: numeric
+    - name: two
+      type:
+        scalar: numeric
+    - name: three
+      type:
+        scalar: numeric
+    - name: letter
+      type:
+        scalar: string
+    - name: a
+      type:
+        scalar: numeric
+    - name: b
+      type:
+        scalar: numeric
+    - name: nested
+      type:
+        list:
+          elementType:
+            namedType: union
+          elementRelationship: associative
+          keys:
+          - letter
+    unions:
+    - discriminator: discriminator
+      deduceInvalidDiscriminator: true
+      fields:
+      - fieldName: one
+        discriminatorValue: One
+      - fieldName: two
+        discriminatorValue: TWO
+      - fieldName: three
+        discriminatorValue: three
+    - discriminator: letter
+      fields:
+      - fieldName: a
+        discriminatorValue: A
+      - fieldName: b
+        discriminatorValue: b
+`)
+	if err != nil {
+		panic(err)
+	}
+	return parser.Type("union")
+}()
+
+func TestUnionValidation(t *testing.T) {
+	valid := []typed.YAMLObject{
+		`{}`,
+		`{"one": 1}`,
+		`{"one": 1, "two": null}`,
+		`{"discriminator": "TWO", "two": 2, "a": 1}`,
+		`{"nested": [{"letter": "A", "a": 1}, {"letter": "b", "b": 1}]}`,
+	}
+	invalid := []typed.YAMLObject{
+		`{"one": 1, "two": 2}`,
+		`{"discriminator": "One", "one": 1, "three": 3}`,
+		`{"a": 1, "b": 2}`,
+		`{"nested": [{"letter": "A", "a": 1, "b": 1}]}`,
+	}
+	for _, obj := range valid {
+		if _, err := unionParser.FromYAML(obj); err != nil {
+			t.Errorf("expected %v to be valid, got: %v", obj, err)
+		}
+	}
+	for _, obj := range invalid {
+		if _, err := unionParser.FromYAML(obj); err == nil {
+			t.Errorf("expected %v to be invalid", obj)
+		}
+	}
+}
+
+func TestNormalizeUnions(t *testing.T) {
+	tests := []struct {
+		name string
+		old  typed.YAMLObject
+		new  typed.YAMLObject
+		out  typed.YAMLObject
+	}{
+		{
+			name: "nothing changed, add discriminator",
+			new:  `{"one": 1}`,
+			out:  `{"one": 1, "discriminator": "One"}`,
+		},
+		{
+			name: "nothing changed, non-deduced",
+			new:  `{"a": 1}`,
+			out:  `{"a": 1, "letter": "A"}`,
+		},
+		{
+			name: "proper union update, setting discriminator",
+			old:  `{"one": 1}`,
+			new:  `{"two": 1}`,
+			out:  `{"two": 1, "discriminator": "TWO"}`,
+		},
+		{
+			name: "proper union update, invalid discriminator is deduced",
+			old:  `{"one": 1, "discriminator": "One"}`,
+			new:  `{"two": 1, "discriminator": "One"}`,
+			out:  `{"two": 1, "discriminator": "TWO"}`,
+		},
+		{
+			name: "proper union update from not-set, setting discriminator",
+			old:  `{}`,
+			new:  `{"two": 1}`,
+			out:  `{"two": 1, "discriminator": "TWO"}`,
+		},
+		{
+			name: "remove union, with discriminator",
+			old:  `{"one": 1}`,
+			new:  `{}`,
+			out:  `{}`,
+		},
+		{
+			name: "change discriminator, nothing else",
+			old:  `{"discriminator": "One"}`,
+			new:  `{"discriminator": "random"}`,
+			out:  `{"discriminator": "random"}`,
+		},
+		{
+			name: "change discriminator, nothing else, it drops other field",
+			old:  `{"discriminator": "One", "one": 1}`,
+			new:  `{"discriminator": "random", "one": 1}`,
+			out:  `{"discriminator": "random"}`,
+		},
+		{
+			name: "remove discriminator, nothing else",
+			old:  `{"discriminator": "One", "one": 1}`,
+			new:  `{"one": 1}`,
+			out:  `{"one": 1, "discriminator": "One"}`,
+		},
+		{
+			name: "nested unions are normalized",
+			old:  `{"nested": [{"letter": "A", "a": 1}]}`,
+			new:  `{"nested": [{"letter": "A", "a": 1}, {"letter": "b", "b": 1}], "three": 3}`,
+			out:  `{"nested": [{"letter": "A", "a": 1}, {"letter": "b", "b": 1}], "three": 3, "discriminator": "three"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			old, err := unionParser.FromYAML(test.old)
+			if err != nil {
+				t.Fatalf("Failed to parse old object: %v", err)
+			}
+			new, err := unionParser.FromYAML(test.new)
+			if err != nil {
+				t.Fatalf("failed to parse new object: %v", err)
+			}
+			out, err := unionParser.FromYAML(test.out)
+			if err != nil {
+				t.Fatalf("failed to parse out object: %v", err)
+			}
+			got, err := old.NormalizeUnions(new)
+			if err != nil {
+				t.Fatalf("failed to normalize unions: %v", err)
+			}
+			comparison, err := out.Compare(got)
+			if err != nil {
+				t.Fatalf("failed to compare result and expected: %v", err)
+			}
+			if !comparison.IsSame() {
+				t.Errorf("Result is different from expected:\n%v", comparison)
+			}
+		})
+	}
+}
+
+func TestNormalizeUnionError(t *testing.T) {
+	tests := []struct {
+		name string
+		old  typed.YAMLObject
+		new  typed.YAMLObject
+	}{
+		{
+			name: "dumb client update, no discriminator",
+			old:  `{"one": 1}`,
+			new:  `{"one": 2, "two": 1}`,
+		},
+		{
+			name: "new object has three of same union set",
+			old:  `{"one": 1}`,
+			new:  `{"one": 2, "two": 1, "three": 3}`,
+		},
+		{
+			name: "dumb client doesn't clear discriminator",
+			old:  `{"one": 1, "discriminator": "One"}`,
+			new:  `{"one": 2, "two": 1, "discriminator": "One"}`,
+		},
+		{
+			name: "discriminator and field changed don't match",
+			old:  `{"one": 1, "discriminator": "One"}`,
+			new:  `{"one": 1, "two": 1, "discriminator": "three"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			old, err := unionParser.FromYAML(test.old)
+			if err != nil {
+				t.Fatalf("Failed to parse old object: %v", err)
+			}
+			// The new objects are invalid on purpose, they are
+			// built the way a client could send them.
+			new, err := unionParser.FromUnstructured(nil)
+			if err != nil {
+				t.Fatalf("failed to create empty object: %v", err)
+			}
+			newValue, err := typed.DeducedParseableType.FromYAML(test.new)
+			if err != nil {
+				t.Fatalf("failed to parse new object: %v", err)
+			}
+			new = typed.AsTypedUnvalidated(newValue.AsValue(), new.Schema(), new.TypeRef())
+			_, err = old.NormalizeUnions(new)
+			if err == nil {
+				t.Fatal("Normalization should have failed, but hasn't.")
+			}
+		})
+	}
+}
+
+func TestNormalizeUnionsApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied typed.YAMLObject
+		merged  typed.YAMLObject
+		out     typed.YAMLObject
+		err     bool
+	}{
+		{
+			name:    "applied field clears the other fields",
+			applied: `{"two": 2}`,
+			merged:  `{"one": 1, "two": 2, "discriminator": "One"}`,
+			out:     `{"two": 2, "discriminator": "TWO"}`,
+		},
+		{
+			name:    "applied discriminator clears the other fields",
+			applied: `{"letter": "b"}`,
+			merged:  `{"a": 1, "letter": "b"}`,
+			out:     `{"letter": "b"}`,
+		},
+		{
+			name:    "applied invalid discriminator is deduced",
+			applied: `{"discriminator": "One", "three": 3}`,
+			merged:  `{"one": 1, "three": 3, "discriminator": "One"}`,
+			out:     `{"three": 3, "discriminator": "three"}`,
+		},
+		{
+			name:    "applied invalid discriminator is not deduced",
+			applied: `{"letter": "A", "b": 3}`,
+			merged:  `{"a": 1, "b": 3, "letter": "A"}`,
+			err:     true,
+		},
+		{
+			name:    "nothing applied",
+			applied: `{}`,
+			merged:  `{"a": 1, "letter": "A", "one": 1}`,
+			out:     `{"a": 1, "letter": "A", "one": 1}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			applied, err := unionParser.FromYAML(test.applied)
+			if err != nil {
+				t.Fatalf("failed to parse applied object: %v", err)
+			}
+			mergedValue, err := typed.DeducedParseableType.FromYAML(test.merged)
+			if err != nil {
+				t.Fatalf("failed to parse merged object: %v", err)
+			}
+			merged := typed.AsTypedUnvalidated(mergedValue.AsValue(), applied.Schema(), applied.TypeRef())
+			got, err := applied.NormalizeUnionsApply(merged)
+			if test.err {
+				if err == nil {
+					t.Fatal("Normalization should have failed, but hasn't.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to normalize unions: %v", err)
+			}
+			out, err := unionParser.FromYAML(test.out)
+			if err != nil {
+				t.Fatalf("failed to parse out object: %v", err)
+			}
+			comparison, err := out.Compare(got)
+			if err != nil {
+				t.Fatalf("failed to compare result and expected: %v", err)
+			}
+			if !comparison.IsSame() {
+				t.Errorf("Result is different from expected:\n%v", comparison)
+			}
+		})
+	}
+}
@@ -34,6 +34,7 @@ func (tv TypedValue) walker() *validatingObjectWalker {
 	v.schema = tv.schema
 	v.typeRef = tv.typeRef
 	v.allowDuplicates = false
+	v.requireComplete = false
 	if v.allocator == nil {
 		v.allocator = value.NewFreelistAllocator()
 	}
@@ -43,6 +44,7 @@ func (tv TypedValue) walker() *validatingObjectWalker {
 func (v *validatingObjectWalker) finished() {
 	v.schema = nil
 	v.typeRef = schema.TypeRef{}
+	v.format, v.constraints = "", nil
 	vPool.Put(v)
 }
 
@@ -53,6 +55,14 @@ type validatingObjectWalker struct {
 	// If set to true, duplicates will be allowed in
 	// associativeLists/sets.
 	allowDuplicates bool
+	// If set to true, the required fields and the minimum numbers of
+	// items are checked.
+	requireComplete bool
+
+	// format and constraints are those of the atom of typeRef, once
+	// resolved.
+	format      schema.Format
+	constraints *schema.Constraints
 
 	// Allocate only as many walkers as needed for the depth by storing them here.
 	spareWalkers *[]*validatingObjectWalker
@@ -81,11 +91,16 @@ func (v *validatingObjectWalker) finishDescent(v2 *validatingObjectWalker) {
 	*v.spareWalkers = append(*v.spareWalkers, v2)
 }
 
-func (v *validatingObjectWalker) validate(prefixFn func() string) ValidationErrors {
-	return resolveSchema(v.schema, v.typeRef, v.value, v).WithLazyPrefix(prefixFn)
+func (v *validatingObjectWalker) validate() ValidationErrors {
+	a, errs := resolveAtom(v.schema, v.typeRef, v.value)
+	if errs != nil {
+		return errs
+	}
+	v.format, v.constraints = a.Format, a.Constraints
+	return handleAtom(a, v.typeRef, v)
 }
 
-func validateScalar(t *schema.Scalar, v value.Value, prefix string) (errs ValidationErrors) {
+func validateScalar(t *schema.Scalar, tr schema.TypeRef, v value.Value, prefix string) (errs ValidationErrors) {
 	if v == nil {
 		return nil
 	}
@@ -95,32 +110,43 @@ func validateScalar(t *schema.Scalar, v value.Value, prefix string) (errs Valida
 	switch *t {
 	case schema.Numeric:
 		if !v.IsFloat() && !v.IsInt() {
-			// TODO: should the schema separate int and float?
-			return errorf("%vexpected numeric (int or float), got %T", prefix, v.Unstructured())
+			return typeMismatchf(v, tr, "%vexpected numeric (int or float), got %T", prefix, v.Unstructured())
+		}
+	case schema.Integer:
+		if !v.IsInt() && !(v.IsFloat() && isIntegral(v.AsFloat())) {
+			return typeMismatchf(v, tr, "%vexpected integer, got %v", prefix, value.ToString(v))
+		}
+	case schema.Float:
+		if !v.IsFloat() && !v.IsInt() {
+			return typeMismatchf(v, tr, "%vexpected float, got %T", prefix, v.Unstructured())
 		}
 	case schema.String:
 		if !v.IsString() {
-			return errorf("%vexpected string, got %#v", prefix, v)
+			return typeMismatchf(v, tr, "%vexpected string, got %#v", prefix, v)
 		}
 	case schema.Boolean:
 		if !v.IsBool() {
-			return errorf("%vexpected boolean, got %v", prefix, v)
+			return typeMismatchf(v, tr, "%vexpected boolean, got %v", prefix, v)
 		}
 	case schema.Untyped:
 		if !v.IsFloat() && !v.IsInt() && !v.IsString() && !v.IsBool() {
-			return errorf("%vexpected any scalar, got %v", prefix, v)
+			return typeMismatchf(v, tr, "%vexpected any scalar, got %v", prefix, v)
 		}
 	default:
-		return errorf("%vunexpected scalar type in schema: %v", prefix, *t)
+		return typedErrorf(ErrorTypeSchema, "%vunexpected scalar type in schema: %v", prefix, *t)
 	}
 	return nil
 }
 
 func (v *validatingObjectWalker) doScalar(t *schema.Scalar) ValidationErrors {
-	if errs := validateScalar(t, v.value, ""); len(errs) > 0 {
+	if errs := validateScalar(t, v.typeRef, v.value, ""); len(errs) > 0 {
 		return errs
 	}
-	return nil
+	var errs ValidationErrors
+	if v.format != "" {
+		errs = validateFormat(v.format, v.typeRef, v.value)
+	}
+	return append(errs, validateScalarConstraints(v.constraints, v.typeRef, v.value)...)
 }
 
 func (v *validatingObjectWalker) visitListItems(t *schema.List, list value.List) (errs ValidationErrors) {
@@ -135,20 +161,22 @@ func (v *validatingObjectWalker) visitListItems(t *schema.List, list value.List)
 			var err error
 			pe, err = listItemToPathElement(v.allocator, v.schema, t, child)
 			if err != nil {
-				errs = append(errs, errorf("element %v: %v", i, err.Error())...)
+				errs = append(errs, listItemErrorf(i, child, err)...)
 				// If we can't construct the path element, we can't
 				// even report errors deeper in the schema, so bail on
 				// this element.
 				return
 			}
 			if observedKeys.Has(pe) && !v.allowDuplicates {
-				errs = append(errs, errorf("duplicate entries for key %v", pe.String())...)
+				dup := typedErrorf(ErrorTypeDuplicateKey, "duplicate entries for key %v", pe.String())
+				dup[0].Value = copyValue(child)
+				errs = append(errs, dup...)
 			}
 			observedKeys.Insert(pe)
 		}
 		v2 := v.prepareDescent(t.ElementType)
 		v2.value = child
-		errs = append(errs, v2.validate(pe.String)...)
+		errs = append(errs, v2.validate().WithPathElementPrefix(pe)...)
 		v.finishDescent(v2)
 	}
 	return errs
@@ -157,7 +185,7 @@ func (v *validatingObjectWalker) visitListItems(t *schema.List, list value.List)
 func (v *validatingObjectWalker) doList(t *schema.List) (errs ValidationErrors) {
 	list, err := listValue(v.allocator, v.value)
 	if err != nil {
-		return errorf("%v", err)
+		return typeMismatchf(v.value, v.typeRef, "%v", err)
 	}
 
 	if list == nil {
@@ -165,25 +193,26 @@ func (v *validatingObjectWalker) doList(t *schema.List) (errs ValidationErrors)
 	}
 
 	defer v.allocator.Free(list)
-	errs = v.visitListItems(t, list)
+	errs = validateListConstraints(v.constraints, v.typeRef, v.value, list, v.requireComplete)
+	errs = append(errs, v.visitListItems(t, list)...)
 
 	return errs
 }
 
 func (v *validatingObjectWalker) visitMapItems(t *schema.Map, m value.Map) (errs ValidationErrors) {
 	m.IterateUsing(v.allocator, func(key string, val value.Value) bool {
-		pe := fieldpath.PathElement{FieldName: &key}
 		tr := t.ElementType
 		if sf, ok := t.FindField(key); ok {
 			tr = sf.Type
 		} else if (t.ElementType == schema.TypeRef{}) {
-			errs = append(errs, errorf("field not declared in schema").WithPrefix(pe.String())...)
+			unknown := typedErrorf(ErrorTypeUnknownField, "field not declared in schema")
+			unknown[0].Value = copyValue(val)
+			errs = append(errs, unknown.withFieldNamePrefix(key)...)
 			return false
 		}
 		v2 := v.prepareDescent(tr)
 		v2.value = val
-		// Giving pe.String as a parameter actually increases the allocations.
-		errs = append(errs, v2.validate(func() string { return pe.String() })...)
+		errs = append(errs, v2.validate().withFieldNamePrefix(key)...)
 		v.finishDescent(v2)
 		return true
 	})
@@ -193,13 +222,15 @@ func (v *validatingObjectWalker) visitMapItems(t *schema.Map, m value.Map) (errs
 func (v *validatingObjectWalker) doMap(t *schema.Map) (errs ValidationErrors) {
 	m, err := mapValue(v.allocator, v.value)
 	if err != nil {
-		return errorf("%v", err)
+		return typeMismatchf(v.value, v.typeRef, "%v", err)
 	}
 	if m == nil {
 		return nil
 	}
 	defer v.allocator.Free(m)
-	errs = v.visitMapItems(t, m)
+	errs = validateMapConstraints(v.constraints, v.typeRef, v.value, m, v.requireComplete)
+	errs = append(errs, v.visitMapItems(t, m)...)
+	errs = append(errs, validateUnions(t, m)...)
 
 	return errs
 }
@@ -21,8 +21,10 @@ import (
 	"strings"
 	"testing"
 
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
 	"sigs.k8s.io/structured-merge-diff/v6/schema"
 	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	"sigs.k8s.io/structured-merge-diff/v6/value"
 )
 
 type validationTestCase struct {
@@ -33,6 +35,9 @@ type validationTestCase struct {
 	invalidObjects []typed.YAMLObject
 	// duplicatesObjects are valid with AllowDuplicates validation, invalid otherwise.
 	duplicatesObjects []typed.YAMLObject
+	// incompleteObjects are valid, but invalid with RequireComplete
+	// validation.
+	incompleteObjects []typed.YAMLObject
 }
 
 var validationCases = []validationTestCase{{
@@ -237,6 +242,196 @@ var validationCases = []validationTestCase{{
 	}, duplicatesObjects: []typed.YAMLObject{
 		`{"list":[{"key":"a","id":1},{"key":"a","id":1}]}`,
 	},
+}, {
+	name:         "integer and float",
+	rootTypeName: "myStruct",
+	schema: `types:
+- name: myStruct
+  map:
+    fields:
+    - name: integer
+      type:
+        scalar: integer
+    - name: float
+      type:
+        scalar: float
+`,
+	validObjects: []typed.YAMLObject{
+		`{"integer":null}`,
+		`{"integer":1}`,
+		`{"integer":-1}`,
+		`{"integer":2.0}`,
+		`{"float":null}`,
+		`{"float":1}`,
+		`{"float":3.14159}`,
+	},
+	invalidObjects: []typed.YAMLObject{
+		`{"integer":3.14159}`,
+		`{"integer":"1"}`,
+		`{"integer":true}`,
+		`{"integer":[1]}`,
+		`{"float":"3.14159"}`,
+		`{"float":false}`,
+		`{"float":{"a":1}}`,
+	},
+}, {
+	name:         "formats",
+	rootTypeName: "myStruct",
+	schema: `types:
+- name: myStruct
+  map:
+    fields:
+    - name: dateTime
+      type:
+        scalar: string
+        format: date-time
+    - name: int32
+      type:
+        scalar: integer
+        format: int32
+    - name: int64
+      type:
+        scalar: integer
+        format: int64
+    - name: byte
+      type:
+        scalar: string
+        format: byte
+    - name: quantity
+      type:
+        namedType: quantity
+    - name: duration
+      type:
+        scalar: string
+        format: duration
+    - name: unknown
+      type:
+        scalar: string
+        format: email
+- name: quantity
+  scalar: untyped
+  format: quantity
+`,
+	validObjects: []typed.YAMLObject{
+		`{"dateTime":null}`,
+		`{"dateTime":"2006-01-02T15:04:05Z"}`,
+		`{"dateTime":"2006-01-02T15:04:05.999+07:00"}`,
+		`{"int32":2147483647}`,
+		`{"int32":-2147483648}`,
+		`{"int32":1.0}`,
+		`{"int64":9223372036854775807}`,
+		`{"byte":"aGVsbG8="}`,
+		`{"byte":""}`,
+		`{"quantity":"1Gi"}`,
+		`{"quantity":"100m"}`,
+		`{"quantity":"1.5"}`,
+		`{"quantity":"1e3"}`,
+		`{"quantity":"-.5k"}`,
+		`{"quantity":1}`,
+		`{"quantity":0.5}`,
+		`{"duration":"1h30m"}`,
+		`{"duration":"0"}`,
+		`{"unknown":"anything"}`,
+	},
+	invalidObjects: []typed.YAMLObject{
+		`{"dateTime":"2006-01-02"}`,
+		`{"dateTime":"yesterday"}`,
+		`{"int32":2147483648}`,
+		`{"int32":-2147483649}`,
+		`{"int64":9.3e18}`,
+		`{"byte":"hello!"}`,
+		`{"quantity":"1GB"}`,
+		`{"quantity":"one"}`,
+		`{"quantity":""}`,
+		`{"quantity":true}`,
+		`{"duration":"1 hour"}`,
+	},
+}, {
+	name:         "constraints",
+	rootTypeName: "myStruct",
+	schema: `types:
+- name: myStruct
+  map:
+    fields:
+    - name: color
+      type:
+        scalar: string
+        constraints:
+          enum: ["red", "green"]
+    - name: port
+      type:
+        scalar: integer
+        constraints:
+          minimum: 1
+          maximum: 65535
+    - name: name
+      type:
+        scalar: string
+        constraints:
+          pattern: "^[a-z]+$"
+          minLength: 2
+          maxLength: 4
+    - name: args
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+        constraints:
+          minItems: 1
+          maxItems: 2
+    - name: labels
+      type:
+        map:
+          elementType:
+            scalar: string
+        constraints:
+          maxProperties: 1
+    - name: spec
+      type:
+        namedType: spec
+- name: spec
+  map:
+    fields:
+    - name: image
+      type:
+        scalar: string
+    - name: command
+      type:
+        scalar: string
+  constraints:
+    required: ["image"]
+`,
+	validObjects: []typed.YAMLObject{
+		`{"color":"red"}`,
+		`{"color":null}`,
+		`{"port":1}`,
+		`{"port":65535}`,
+		`{"name":"ab"}`,
+		`{"name":"abcd"}`,
+		`{"args":["a"]}`,
+		`{"args":["a","b"]}`,
+		`{"args":null}`,
+		`{"labels":{}}`,
+		`{"labels":{"a":"b"}}`,
+		`{"spec":{"image":"nginx"}}`,
+		`{"spec":null}`,
+	},
+	invalidObjects: []typed.YAMLObject{
+		`{"color":"blue"}`,
+		`{"port":0}`,
+		`{"port":65536}`,
+		`{"name":"a"}`,
+		`{"name":"abcde"}`,
+		`{"name":"AB"}`,
+		`{"args":["a","b","c"]}`,
+		`{"labels":{"a":"b","c":"d"}}`,
+	},
+	incompleteObjects: []typed.YAMLObject{
+		`{"args":[]}`,
+		`{"spec":{}}`,
+		`{"spec":{"command":"run"}}`,
+	},
 }}
 
 func (tt validationTestCase) test(t *testing.T) {
@@ -287,6 +482,20 @@ func (tt validationTestCase) test(t *testing.T) {
 			}
 		})
 	}
+	for i, iv := range tt.incompleteObjects {
+		iv := iv
+		t.Run(fmt.Sprintf("%v-incomplete-%v", tt.name, i), func(t *testing.T) {
+			t.Parallel()
+			_, err := pt.FromYAML(iv)
+			if err != nil {
+				t.Errorf("failed to parse/validate yaml: %v\n%v", err, iv)
+			}
+			_, err = pt.FromYAML(iv, typed.RequireComplete)
+			if err == nil {
+				t.Fatalf("Object should fail:\n%v", iv)
+			}
+		})
+	}
 }
 
 func TestSchemaValidation(t *testing.T) {
@@ -307,6 +516,141 @@ func TestSchemaSchema(t *testing.T) {
 	}
 }
 
+func TestValidationErrorDetails(t *testing.T) {
+	parser, err := typed.NewParser(`types:
+- name: root
+  map:
+    fields:
+    - name: name
+      type:
+        scalar: string
+    - name: items
+      type:
+        list:
+          elementType:
+            namedType: item
+          elementRelationship: associative
+          keys: ["key"]
+    - name: spec
+      type:
+        map:
+          fields:
+          - name: image
+            type:
+              scalar: string
+          - name: replicas
+            type:
+              scalar: integer
+              constraints:
+                minimum: 0
+        constraints:
+          required: ["image"]
+- name: item
+  map:
+    fields:
+    - name: key
+      type:
+        scalar: string
+    - name: count
+      type:
+        scalar: numeric
+    - name: created
+      type:
+        scalar: string
+        format: date-time
+`)
+	if err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	numeric, str, integer := schema.Numeric, schema.String, schema.Integer
+	minimum := float64(0)
+	tests := []struct {
+		object    typed.YAMLObject
+		errType   typed.ValidationErrorType
+		path      fieldpath.Path
+		value     interface{}
+		expected  schema.TypeRef
+		errString string
+	}{{
+		object:    `{"items": [{"key": "a", "count": "one"}]}`,
+		errType:   typed.ErrorTypeTypeMismatch,
+		path:      fieldpath.MakePathOrDie("items", fieldpath.KeyByFields("key", "a"), "count"),
+		value:     "one",
+		expected:  schema.TypeRef{Inlined: schema.Atom{Scalar: &numeric}},
+		errString: `.items[key="a"].count: expected numeric (int or float), got string`,
+	}, {
+		object:    `{"items": [{"key": "a"}, {"key": "a"}]}`,
+		errType:   typed.ErrorTypeDuplicateKey,
+		path:      fieldpath.MakePathOrDie("items"),
+		value:     map[string]interface{}{"key": "a"},
+		errString: `.items: duplicate entries for key [key="a"]`,
+	}, {
+		object:    `{"items": [{"count": 1}]}`,
+		errType:   typed.ErrorTypeMissingKeyField,
+		path:      fieldpath.MakePathOrDie("items"),
+		value:     map[string]interface{}{"count": 1},
+		errString: `.items: element 0: associative list with keys has an element that omits key field "key" (and doesn't have default value)`,
+	}, {
+		object:    `{"items": [{"key": "a", "unknown": true}]}`,
+		errType:   typed.ErrorTypeUnknownField,
+		path:      fieldpath.MakePathOrDie("items", fieldpath.KeyByFields("key", "a"), "unknown"),
+		value:     true,
+		errString: `.items[key="a"].unknown: field not declared in schema`,
+	}, {
+		object:    `{"items": [{"key": "a", "created": "today"}]}`,
+		errType:   typed.ErrorTypeFormat,
+		path:      fieldpath.MakePathOrDie("items", fieldpath.KeyByFields("key", "a"), "created"),
+		value:     "today",
+		expected:  schema.TypeRef{Inlined: schema.Atom{Scalar: &str, Format: schema.FormatDateTime}},
+		errString: `.items[key="a"].created: expected format date-time, got "today"`,
+	}, {
+		object:    `{"spec": {"image": "nginx", "replicas": -1}}`,
+		errType:   typed.ErrorTypeConstraint,
+		path:      fieldpath.MakePathOrDie("spec", "replicas"),
+		value:     -1,
+		expected:  schema.TypeRef{Inlined: schema.Atom{Scalar: &integer, Constraints: &schema.Constraints{Minimum: &minimum}}},
+		errString: `.spec.replicas: value -1 is less than the minimum 0`,
+	}, {
+		object:    `{"spec": {"replicas": 1}}`,
+		errType:   typed.ErrorTypeRequired,
+		path:      fieldpath.MakePathOrDie("spec", "image"),
+		errString: `.spec.image: required field is missing`,
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.errType), func(t *testing.T) {
+			_, err := parser.Type("root").FromYAML(tt.object, typed.RequireComplete)
+			errs, ok := err.(typed.ValidationErrors)
+			if !ok || len(errs) != 1 {
+				t.Fatalf("expected a single validation error, got %v", err)
+			}
+			got := errs[0]
+			if got.Type != tt.errType {
+				t.Errorf("expected type %v, got %v", tt.errType, got.Type)
+			}
+			if !got.FieldPath.Equals(tt.path) {
+				t.Errorf("expected path %v, got %v", tt.path, got.FieldPath)
+			}
+			if got.Path != tt.path.String() {
+				t.Errorf("expected path string %v, got %v", tt.path, got.Path)
+			}
+			if tt.value == nil {
+				if got.Value != nil {
+					t.Errorf("expected no value, got %v", got.Value)
+				}
+			} else if got.Value == nil || !value.Equals(got.Value, value.NewValueInterface(tt.value)) {
+				t.Errorf("expected value %v, got %v", tt.value, got.Value)
+			}
+			if !got.Expected.Equals(&tt.expected) {
+				t.Errorf("expected type ref %v, got %v", tt.expected, got.Expected)
+			}
+			if got.Error() != tt.errString {
+				t.Errorf("expected error:\n%v\ngot:\n%v", tt.errString, got.Error())
+			}
+		})
+	}
+}
+
 func BenchmarkValidateStructured(b *testing.B) {
 	type Primitives struct {
 		s string